@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/valentino7504/jobtrack/internal/db"
+	"github.com/valentino7504/jobtrack/internal/jobPrinter"
+)
+
+var companyCmd = &cobra.Command{
+	Use:   "company",
+	Short: "Manage the companies you apply to and see every application made at one.",
+	Long: `Keep the companies you apply to, with details such as their website, industry and size.
+
+Every job belongs to a company. When a job is created its company is matched to an existing one
+by name or alias, ignoring case, so "acme" and "ACME" are the same company, and the job takes
+the company's spelling. A company is added the first time a job is created at it.
+
+Companies entered under two names, for example because of a typo, are combined with merge:
+the jobs of one move to the other, which keeps the merged name as an alias so later jobs
+entered with it match too. Renaming a company renames its jobs, and also keeps the old name
+as an alias.
+
+A company can be given by its ID, its name or one of its aliases.
+
+Examples:
+  jobtrack company list
+  jobtrack company show acme
+  jobtrack company update acme --website https://acme.example --industry Retail --size 1000+
+  jobtrack company update acme --alias "Acme Corp"
+  jobtrack company rename acme "Acme Inc."
+  jobtrack company merge "Acme Crop" "Acme Inc."
+`,
+}
+
+// applicationsStr counts applications, e.g. "1 application" or "3 applications".
+func applicationsStr(n int) string {
+	if n == 1 {
+		return "1 application"
+	}
+	return fmt.Sprintf("%d applications", n)
+}
+
+// companyFromArg finds the company given as an argument, printing why when there is none.
+func companyFromArg(ref string) *db.Company {
+	company, err := Store.FindCompany(ref)
+	if err != nil {
+		fmt.Println("Error getting company:", err)
+		return nil
+	}
+	if company == nil {
+		fmt.Println("No company found with ID or name:", ref)
+		return nil
+	}
+	return company
+}
+
+var companyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List companies with how many applications there are at each.",
+	Run: func(cmd *cobra.Command, args []string) {
+		companies, err := Store.GetCompanies()
+		if err != nil {
+			fmt.Println("Error getting companies:", err)
+			return
+		}
+		if len(companies) == 0 {
+			fmt.Println("No companies found")
+			return
+		}
+		jobPrinter.PrintCompaniesTable(companies)
+	},
+}
+
+var companyShowCmd = &cobra.Command{
+	Use:   "show COMPANY",
+	Short: "Show a company with every application made there and its outcome.",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Println("Specify the ID or name of the company to show")
+			return
+		}
+		company := companyFromArg(args[0])
+		if company == nil {
+			return
+		}
+		jobs, err := Store.QueryJobs(db.JobQuery{CompanyID: company.ID})
+		if err != nil {
+			fmt.Println("Error getting jobs of company:", err)
+			return
+		}
+		jobPrinter.PrintCompany(company, jobs)
+	},
+}
+
+var companyUpdateCmd = &cobra.Command{
+	Use:   "update COMPANY",
+	Short: "Update the details and aliases of a company.",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Println("Specify the ID or name of the company you want to update")
+			return
+		}
+		company := companyFromArg(args[0])
+		if company == nil {
+			return
+		}
+		website, _ := cmd.Flags().GetString("website")
+		industry, _ := cmd.Flags().GetString("industry")
+		size, _ := cmd.Flags().GetString("size")
+		notes, _ := cmd.Flags().GetString("notes")
+		addAliases, _ := cmd.Flags().GetStringArray("alias")
+		removeAliases, _ := cmd.Flags().GetStringArray("remove-alias")
+		updated, err := Store.UpdateCompany(company.ID, db.UpdatedCompanyParams{
+			Website:       processParam(website),
+			Industry:      processParam(industry),
+			Size:          processParam(size),
+			Notes:         processParam(notes),
+			AddAliases:    addAliases,
+			RemoveAliases: removeAliases,
+		})
+		if err != nil {
+			fmt.Println("Error updating company:", err)
+			exitCode = 1
+			return
+		}
+		if updated == nil {
+			fmt.Println("No company found with ID:", company.ID)
+			return
+		}
+		fmt.Printf("Company %s (ID: %d) has been updated\n", updated.Name, updated.ID)
+	},
+}
+
+var companyRenameCmd = &cobra.Command{
+	Use:   "rename COMPANY NAME",
+	Short: "Rename a company and the applications made there.",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			fmt.Println("Specify the company to rename and its new name")
+			return
+		}
+		company := companyFromArg(args[0])
+		if company == nil {
+			return
+		}
+		renamed, err := Store.RenameCompany(company.ID, args[1])
+		if err != nil {
+			fmt.Println("Error renaming company:", err)
+			exitCode = 1
+			return
+		}
+		if renamed == nil {
+			fmt.Println("No company found with ID:", company.ID)
+			return
+		}
+		fmt.Printf("Company %s renamed to %s", company.Name, renamed.Name)
+		if renamed.Jobs > 0 {
+			fmt.Printf(" along with its %s", applicationsStr(renamed.Jobs))
+		}
+		fmt.Println()
+	},
+}
+
+var companyMergeCmd = &cobra.Command{
+	Use:   "merge FROM INTO",
+	Short: "Move the applications of one company to another and delete it.",
+	Long: `Move the applications of one company to another and delete it, for example to combine a
+company entered under a misspelt name with the right one. The name and aliases of the merged
+company become aliases of the other, and details it has no value for are taken from the
+merged company.
+
+Examples:
+  jobtrack company merge "Gogle" Google
+  jobtrack company merge 7 3 --force`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			fmt.Println("Specify the company to merge and the company to merge it into")
+			return
+		}
+		from := companyFromArg(args[0])
+		if from == nil {
+			return
+		}
+		into := companyFromArg(args[1])
+		if into == nil {
+			return
+		}
+		if from.ID == into.ID {
+			fmt.Printf("%s and %s are the same company\n", args[0], args[1])
+			return
+		}
+		force, _ := cmd.Flags().GetBool("force")
+		question := fmt.Sprintf("Merge %s (%s) into %s?", from.Name, applicationsStr(from.Jobs), into.Name)
+		if !force && !confirm(question) {
+			return
+		}
+		merged, err := Store.MergeCompanies(from.ID, into.ID)
+		if err != nil {
+			fmt.Println("Error merging companies:", err)
+			exitCode = 1
+			return
+		}
+		if merged == nil {
+			fmt.Println("No company found with ID:", into.ID)
+			return
+		}
+		fmt.Printf("%s merged into %s (ID: %d), which now has %s\n", from.Name, merged.Name, merged.ID, applicationsStr(merged.Jobs))
+	},
+}
+
+// completeCompanies completes the names of companies.
+func completeCompanies(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if Store == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	companies, err := Store.GetCompanies()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var names []string
+	for _, company := range companies {
+		if strings.HasPrefix(strings.ToLower(company.Name), strings.ToLower(toComplete)) {
+			names = append(names, company.Name)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	rootCmd.AddCommand(companyCmd)
+	companyCmd.AddCommand(companyListCmd, companyShowCmd, companyUpdateCmd, companyRenameCmd, companyMergeCmd)
+	for _, cmd := range []*cobra.Command{companyShowCmd, companyUpdateCmd, companyRenameCmd, companyMergeCmd} {
+		cmd.ValidArgsFunction = completeCompanies
+	}
+
+	companyUpdateCmd.Flags().String("website", "", "The website of the company")
+	companyUpdateCmd.Flags().String("industry", "", "The industry the company is in")
+	companyUpdateCmd.Flags().String("size", "", "The size of the company, e.g. 50-200 employees")
+	companyUpdateCmd.Flags().String("notes", "", "Notes on the company")
+	companyUpdateCmd.Flags().StringArray("alias", nil, "Another name jobs at the company may be entered with (repeatable)")
+	companyUpdateCmd.Flags().StringArray("remove-alias", nil, "Remove an alias of the company (repeatable)")
+
+	companyMergeCmd.Flags().Bool("force", false, "Skip confirmation prompt")
+}
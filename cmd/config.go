@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/valentino7504/jobtrack/internal/config"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show and change jobtrack settings.",
+	Long: `Show and change the settings in the jobtrack configuration file.
+
+The file is ~/.config/jobtrack/config.toml unless another one is given with --config or the
+JOBTRACK_CONFIG environment variable. Every setting can also be overridden with an environment
+variable named after it, such as JOBTRACK_LIST_SORT for list.sort.
+
+The status pipeline and follow-up defaults are tables, edit them in the file directly.
+Changing a setting with jobtrack config set only changes its line, keeping the comments of the
+file.
+
+Examples:
+  jobtrack config list                              # Show every setting and where it comes from
+  jobtrack config get list.sort                     # Show a single setting
+  jobtrack config set list.sort applied:desc        # Newest applications first
+  jobtrack config set list.columns id,company,status,follow-up
+  jobtrack config set display.date_format DD/MM/YYYY
+  jobtrack config set display.color never
+  jobtrack config set defaults.status ""            # Back to the default
+  jobtrack config path                              # Show where the file is
+`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:               "get KEY",
+	Short:             "Show the value of a setting.",
+	ValidArgsFunction: completeSettings,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Println("Specify the setting to show, such as list.sort")
+			return
+		}
+		setting, err := config.LookupSetting(args[0])
+		if err != nil {
+			fmt.Println(err)
+			exitCode = 1
+			return
+		}
+		value, _ := Config.Value(setting)
+		fmt.Println(value)
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:               "set KEY VALUE",
+	Short:             "Change a setting in the configuration file.",
+	ValidArgsFunction: completeSettings,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			fmt.Println("Specify the setting and its new value, such as: jobtrack config set list.sort applied:desc")
+			return
+		}
+		setting, err := config.LookupSetting(args[0])
+		if err != nil {
+			fmt.Println(err)
+			exitCode = 1
+			return
+		}
+		value := strings.TrimSpace(args[1])
+		if err := config.SetValue(setting.Key, value); err != nil {
+			fmt.Println("Error changing setting:", err)
+			exitCode = 1
+			return
+		}
+		if value == "" {
+			fmt.Printf("Removed %s from %s\n", setting.Key, config.Path())
+		} else {
+			fmt.Printf("Set %s to %s in %s\n", setting.Key, value, config.Path())
+		}
+		if _, ok := os.LookupEnv(setting.Env()); ok {
+			fmt.Printf("Note that %s is set and takes precedence\n", setting.Env())
+		}
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show every setting with its value and where it comes from.",
+	Run: func(cmd *cobra.Command, args []string) {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.Debug|tabwriter.AlignRight)
+		fmt.Fprintf(w, "Key\tValue\tSource\tDescription\n")
+		for _, setting := range config.Settings {
+			value, source := Config.Value(setting)
+			if value == "" {
+				value = "N/A"
+			}
+			if source == "env" {
+				source = setting.Env()
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", setting.Key, value, source, setting.Description)
+		}
+		w.Flush()
+	},
+}
+
+var configPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Show where the configuration file is.",
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println(config.Path())
+	},
+}
+
+// completeSettings completes the key of a setting.
+func completeSettings(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	keys := make([]string, 0, len(config.Settings))
+	for _, setting := range config.Settings {
+		keys = append(keys, setting.Key+"\t"+setting.Description)
+	}
+	return keys, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configListCmd)
+	configCmd.AddCommand(configPathCmd)
+}
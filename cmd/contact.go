@@ -0,0 +1,376 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/valentino7504/jobtrack/internal/db"
+	"github.com/valentino7504/jobtrack/internal/jobPrinter"
+)
+
+var contactCmd = &cobra.Command{
+	Use:   "contact",
+	Short: "Manage recruiters, hiring managers and referrers linked to job applications.",
+	Long: `Keep track of the people involved in your job applications.
+
+A contact can be linked to any number of jobs. Linked contacts are shown when viewing a job,
+and jobs can be listed by contact with jobtrack list --contact.
+
+Examples:
+  jobtrack contact add --name "Jane Doe" --role Recruiter --company Google --job-id 3
+  jobtrack contact list --company google
+  jobtrack contact show --id 1
+  jobtrack contact update --id 1 --email jane@example.com
+  jobtrack contact link --id 1 --job-id 5
+  jobtrack contact unlink --id 1 --job-id 5
+  jobtrack contact delete --id 1
+  jobtrack contact export --output contacts.vcf
+`,
+}
+
+// addContactFlags adds the flags for every contact detail to cmd.
+func addContactFlags(cmd *cobra.Command) {
+	cmd.Flags().String("name", "", "The full name of the contact")
+	cmd.Flags().String("role", "", "The role of the contact, e.g. Recruiter or Hiring Manager")
+	cmd.Flags().String("email", "", "The email address of the contact")
+	cmd.Flags().String("phone", "", "The phone number of the contact")
+	cmd.Flags().String("linkedin", "", "The LinkedIn profile URL of the contact")
+	cmd.Flags().String("company", "", "The company the contact works at")
+}
+
+var contactAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add a new contact, optionally linking it to a job.",
+	Run: func(cmd *cobra.Command, args []string) {
+		name, _ := cmd.Flags().GetString("name")
+		role, _ := cmd.Flags().GetString("role")
+		email, _ := cmd.Flags().GetString("email")
+		phone, _ := cmd.Flags().GetString("phone")
+		linkedIn, _ := cmd.Flags().GetString("linkedin")
+		company, _ := cmd.Flags().GetString("company")
+		jobID, ok := jobIDFlag(cmd, "job-id")
+		if !ok {
+			return
+		}
+		if name == "" {
+			fmt.Println("Contact name not specified")
+			return
+		}
+		if jobID != -1 {
+			job, err := Store.GetJobByID(jobID)
+			if err != nil {
+				fmt.Println("Error accessing job with id", jobID)
+				return
+			}
+			if job == nil {
+				fmt.Println("No job found with ID:", jobID)
+				return
+			}
+		}
+		contact := db.Contact{
+			Name:        name,
+			Role:        optionalSQL(role),
+			Email:       optionalSQL(email),
+			Phone:       optionalSQL(phone),
+			LinkedInURL: optionalSQL(linkedIn),
+			Company:     optionalSQL(company),
+		}
+		if err := Store.AddContact(&contact); err != nil {
+			fmt.Println("Error adding contact:", err)
+			return
+		}
+		fmt.Printf("New contact %s (ID: %d) added\n", contact.Name, contact.ID)
+		if jobID != -1 {
+			if err := Store.LinkContact(jobID, contact.ID); err != nil {
+				fmt.Println("Error linking contact to job:", err)
+				return
+			}
+			fmt.Println("Contact linked to job", jobID)
+		}
+	},
+}
+
+var contactListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List contacts, optionally only those at a company or linked to a job.",
+	Run: func(cmd *cobra.Command, args []string) {
+		company, _ := cmd.Flags().GetString("company")
+		jobID, ok := jobIDFlag(cmd, "job-id")
+		if !ok {
+			return
+		}
+		var contacts db.Contacts
+		var err error
+		if jobID != -1 {
+			contacts, err = Store.GetJobContacts(jobID)
+		} else {
+			contacts, err = Store.GetContacts(company)
+		}
+		if err != nil {
+			fmt.Println("Error getting contacts:", err)
+			return
+		}
+		if len(contacts) == 0 {
+			fmt.Println("No contacts found")
+			return
+		}
+		jobPrinter.PrintContactsTable(contacts)
+	},
+}
+
+var contactShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show a contact and the jobs it is linked to.",
+	Run: func(cmd *cobra.Command, args []string) {
+		contact := contactFromFlag(cmd)
+		if contact == nil {
+			return
+		}
+		jobs, err := Store.GetContactJobs(contact.ID)
+		if err != nil {
+			fmt.Println("Error getting jobs of contact:", err)
+			return
+		}
+		jobPrinter.PrintContact(contact, jobs)
+	},
+}
+
+var contactUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Update the details of a contact.",
+	Run: func(cmd *cobra.Command, args []string) {
+		id, _ := cmd.Flags().GetInt("id")
+		if id == -1 {
+			fmt.Println("Specify the id of the contact you want to update")
+			return
+		}
+		name, _ := cmd.Flags().GetString("name")
+		role, _ := cmd.Flags().GetString("role")
+		email, _ := cmd.Flags().GetString("email")
+		phone, _ := cmd.Flags().GetString("phone")
+		linkedIn, _ := cmd.Flags().GetString("linkedin")
+		company, _ := cmd.Flags().GetString("company")
+		contact, err := Store.UpdateContact(id, db.UpdatedContactParams{
+			Name:        processParam(name),
+			Role:        processParam(role),
+			Email:       processParam(email),
+			Phone:       processParam(phone),
+			LinkedInURL: processParam(linkedIn),
+			Company:     processParam(company),
+		})
+		if err != nil {
+			fmt.Println("Error updating contact:", err)
+			return
+		}
+		if contact == nil {
+			fmt.Println("No contact found with ID:", id)
+			return
+		}
+		fmt.Println("Contact with id:", contact.ID, "has been updated")
+	},
+}
+
+var contactDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Delete a contact and its links to jobs.",
+	Run: func(cmd *cobra.Command, args []string) {
+		contact := contactFromFlag(cmd)
+		if contact == nil {
+			return
+		}
+		force, _ := cmd.Flags().GetBool("force")
+		if !force && !confirm(fmt.Sprintf("Delete contact %s (ID: %d)?", contact.Name, contact.ID)) {
+			return
+		}
+		if _, err := Store.DeleteContact(contact.ID); err != nil {
+			fmt.Println("Error deleting contact:", err)
+			return
+		}
+		fmt.Printf("Contact %s (ID: %d) deleted\n", contact.Name, contact.ID)
+	},
+}
+
+// contactJobLink reads the contact and job IDs of the link and unlink commands.
+func contactJobLink(cmd *cobra.Command) (contactID int, jobID int, ok bool) {
+	contactID, _ = cmd.Flags().GetInt("id")
+	if jobID, ok = jobIDFlag(cmd, "job-id"); !ok {
+		return 0, 0, false
+	}
+	if contactID == -1 || jobID == -1 {
+		fmt.Println("Specify both the contact id with --id and the job id with --job-id")
+		return 0, 0, false
+	}
+	return contactID, jobID, true
+}
+
+var contactLinkCmd = &cobra.Command{
+	Use:   "link",
+	Short: "Link a contact to a job.",
+	Run: func(cmd *cobra.Command, args []string) {
+		contactID, jobID, ok := contactJobLink(cmd)
+		if !ok {
+			return
+		}
+		job, err := Store.GetJobByID(jobID)
+		if err != nil {
+			fmt.Println("Error accessing job with id", jobID)
+			return
+		}
+		if job == nil {
+			fmt.Println("No job found with ID:", jobID)
+			return
+		}
+		contact := contactFromFlag(cmd)
+		if contact == nil {
+			return
+		}
+		if err := Store.LinkContact(jobID, contactID); err != nil {
+			fmt.Println("Error linking contact to job:", err)
+			return
+		}
+		fmt.Printf("Contact %s linked to %s at %s\n", contact.Name, job.Position, job.Company)
+	},
+}
+
+var contactUnlinkCmd = &cobra.Command{
+	Use:   "unlink",
+	Short: "Remove the link between a contact and a job.",
+	Run: func(cmd *cobra.Command, args []string) {
+		contactID, jobID, ok := contactJobLink(cmd)
+		if !ok {
+			return
+		}
+		unlinked, err := Store.UnlinkContact(jobID, contactID)
+		if err != nil {
+			fmt.Println("Error unlinking contact:", err)
+			return
+		}
+		if !unlinked {
+			fmt.Println("Contact", contactID, "is not linked to job", jobID)
+			return
+		}
+		fmt.Println("Contact", contactID, "unlinked from job", jobID)
+	},
+}
+
+var contactExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export contacts as vCards to a file or standard output.",
+	Long: `Export contacts as a vCard (.vcf) file that can be imported into any address book.
+
+By default every contact is exported. Use --id to export a single contact or --job-id to
+export the contacts linked to a job.
+
+Examples:
+  jobtrack contact export --output contacts.vcf
+  jobtrack contact export --job-id 3 --output google.vcf
+  jobtrack contact export --id 1`,
+	Run: func(cmd *cobra.Command, args []string) {
+		id, _ := cmd.Flags().GetInt("id")
+		jobID, ok := jobIDFlag(cmd, "job-id")
+		if !ok {
+			return
+		}
+		filename, _ := cmd.Flags().GetString("output")
+		var contacts db.Contacts
+		var err error
+		switch {
+		case id != -1:
+			contact := contactFromFlag(cmd)
+			if contact == nil {
+				return
+			}
+			contacts = db.Contacts{contact}
+		case jobID != -1:
+			contacts, err = Store.GetJobContacts(jobID)
+		default:
+			contacts, err = Store.GetContacts("")
+		}
+		if err != nil {
+			fmt.Println("Error getting contacts:", err)
+			return
+		}
+		if len(contacts) == 0 {
+			fmt.Println("No contacts available")
+			return
+		}
+		f := os.Stdout
+		if filename != "" {
+			f, err = os.Create(filename)
+			if err != nil {
+				fmt.Println("Error creating export file:", err)
+				return
+			}
+			defer f.Close()
+		}
+		if _, err := f.WriteString(contacts.ToVCard()); err != nil {
+			fmt.Println("Error writing vCard file:", err)
+			return
+		}
+		if filename != "" {
+			fmt.Println("Export successful:", filename)
+		}
+	},
+}
+
+// contactFromFlag returns the contact whose ID is given by the --id flag, printing an
+// error and returning nil if it cannot be found.
+func contactFromFlag(cmd *cobra.Command) *db.Contact {
+	id, _ := cmd.Flags().GetInt("id")
+	if id == -1 {
+		fmt.Println("Specify the id of the contact")
+		return nil
+	}
+	contact, err := Store.GetContactByID(id)
+	if err != nil {
+		fmt.Println("Error accessing contact with id", id)
+		return nil
+	}
+	if contact == nil {
+		fmt.Println("No contact found with ID:", id)
+		return nil
+	}
+	return contact
+}
+
+func init() {
+	rootCmd.AddCommand(contactCmd)
+	contactCmd.AddCommand(
+		contactAddCmd,
+		contactListCmd,
+		contactShowCmd,
+		contactUpdateCmd,
+		contactDeleteCmd,
+		contactLinkCmd,
+		contactUnlinkCmd,
+		contactExportCmd,
+	)
+	addContactFlags(contactAddCmd)
+	addJobIDFlag(contactAddCmd, "job-id", "Link the new contact to this job")
+
+	contactListCmd.Flags().String("company", "", "List contacts whose company contains this text")
+	addJobIDFlag(contactListCmd, "job-id", "List the contacts linked to this job")
+
+	contactShowCmd.Flags().Int("id", -1, "Specify the ID of the contact to show")
+
+	contactUpdateCmd.Flags().Int("id", -1, "Specify the ID of the contact to update")
+	addContactFlags(contactUpdateCmd)
+
+	contactDeleteCmd.Flags().Int("id", -1, "Specify the ID of the contact to delete")
+	contactDeleteCmd.Flags().Bool("force", false, "Skip confirmation prompt")
+
+	for _, c := range []*cobra.Command{contactLinkCmd, contactUnlinkCmd} {
+		c.Flags().Int("id", -1, "Specify the ID of the contact")
+		addJobIDFlag(c, "job-id", "Specify the job")
+	}
+
+	contactExportCmd.Flags().Int("id", -1, "Export only the contact with this ID")
+	addJobIDFlag(contactExportCmd, "job-id", "Export only the contacts linked to this job")
+	contactExportCmd.Flags().StringP(
+		"output",
+		"o",
+		"",
+		"Specify the output file (leave empty to print to stdout)",
+	)
+}
@@ -1,13 +1,12 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/valentino7504/jobtrack/internal/db"
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
 )
 
 func optionalSQL(param string) db.NullString {
@@ -19,48 +18,61 @@ func optionalSQL(param string) db.NullString {
 	return paramSQL
 }
 
+// printValidationError prints each problem found by db.ValidateJob or db.ValidateUpdate
+// on its own line.
+func printValidationError(err error) {
+	var validationErr db.ValidationError
+	if !errors.As(err, &validationErr) {
+		fmt.Println(err)
+		return
+	}
+	for _, fieldErr := range validationErr {
+		fmt.Println(fieldErr.Message)
+	}
+}
+
 func initializeJob(cmd *cobra.Command) *db.Job {
-	caser := cases.Title(language.English)
 	company, _ := cmd.Flags().GetString("company")
 	position, _ := cmd.Flags().GetString("position")
-	status, _ := cmd.Flags().GetString("status")
-	status = caser.String(status)
+	statusName, _ := cmd.Flags().GetString("status")
 	location, _ := cmd.Flags().GetString("location")
 	salaryRange, _ := cmd.Flags().GetString("salary-range")
 	jobPostingURL, _ := cmd.Flags().GetString("job-posting-url")
 	applied, _ := cmd.Flags().GetString("applied")
+	followUp, _ := cmd.Flags().GetString("follow-up")
+	tags, _ := cmd.Flags().GetStringSlice("tag")
+	fields, ok := fieldsFlag(cmd, "set")
+	if !ok {
+		return nil
+	}
 	appliedAt, err := db.ParseDateTime(applied, true)
 	if err != nil {
 		fmt.Println(err)
 		return nil
 	}
-	if appliedAt.After(time.Now()) {
-		fmt.Println("Applied date cannot be in the future")
-		return nil
-	}
-	if company == "" {
-		fmt.Println("Company not specified")
-		return nil
-	}
-	if position == "" {
-		fmt.Println("Position not specified")
-		return nil
-	}
-	if !db.IsValidStatus(db.JobStatus(status)) {
-		fmt.Println("Specified status is not valid")
-		fmt.Println(
-			"Valid statuses are: Applied, Interview, Offer, Accepted, \"Rejected Offer\" and Rejected",
-		)
-		return nil
+	var followUpOn *time.Time
+	if followUp != "" {
+		followUpOn, err = db.ParseRelativeDate(followUp, time.Now())
+		if err != nil {
+			fmt.Println("Invalid follow-up date:", err)
+			return nil
+		}
 	}
 	job := db.Job{
 		Company:       company,
 		Position:      position,
-		Status:        db.JobStatus(status),
+		Status:        db.JobStatus(statusName),
 		Location:      optionalSQL(location),
 		SalaryRange:   optionalSQL(salaryRange),
 		JobPostingURL: optionalSQL(jobPostingURL),
 		AppliedAt:     appliedAt,
+		FollowUpOn:    followUpOn,
+		Tags:          tags,
+		Fields:        fields,
+	}
+	if err := db.ValidateJob(&job); err != nil {
+		printValidationError(err)
+		return nil
 	}
 	return &job
 }
@@ -71,22 +83,32 @@ var createCmd = &cobra.Command{
 	Long: `Add a new job application to the database.
 
 You must provide the company name and position. Additional details such as status, location, salary range,
-job posting URL, and application date can also be included.
+job posting URL, application date, follow-up date, tags and custom fields can also be included.
+
+The salary range is kept as written and also parsed into amounts, a currency, a period and
+whether it is base pay or total compensation (TC), so jobs can be filtered and sorted by pay.
+Amounts can use k and m, and a currency without a symbol or code uses defaults.currency.
 
 Examples:
   jobtrack create --company "Google" --position "Backend Engineer"
   jobtrack create --company "Amazon" --position "SDE" --status "Applied"
   jobtrack create --company "Meta" --position "Data Scientist" --salary-range "$120,000 - $150,000"
+  jobtrack create --company "Revolut" --position "SRE" --salary-range "€70-85k"
+  jobtrack create --company "Acme" --position "Contractor" --salary-range "$85/hr"
+  jobtrack create --company "Stripe" --position "SRE" --follow-up +7d
+  jobtrack create --company "Acme" --position "Backend Engineer" --tag referral,backend
+  jobtrack create --company "Acme" --position "SRE" --set team=Platform --set referral_code=AC-42
 `,
 	Run: func(cmd *cobra.Command, args []string) {
 		job := initializeJob(cmd)
 		if job == nil {
 			return
 		}
-		err := db.AddJob(SqliteDB, job)
-		if err != nil {
+		if err := Store.AddJob(job); err != nil {
+			fmt.Println("Error adding job:", err)
 			return
 		}
+		fmt.Printf("New job application (ID: %d) added, good luck!\n", job.ID)
 	},
 }
 
@@ -104,9 +126,10 @@ func init() {
 	)
 	createCmd.Flags().String(
 		"status",
-		"applied",
-		"Specify the stage of the hiring process you are at",
+		"",
+		"Specify the stage of the hiring process you are at (defaults to the defaults.status setting)",
 	)
+	createCmd.RegisterFlagCompletionFunc("status", completeStatuses)
 	createCmd.Flags().String("location", "", "The location of the job")
 	createCmd.Flags().String("salary-range", "", "The salary range of the job")
 	createCmd.Flags().String("job-posting-url", "", "The URL of the job posting")
@@ -115,4 +138,13 @@ func init() {
 		time.Now().Format("2006-01-02"),
 		"The date of the application formatted YYYY-MM-DD",
 	)
+	createCmd.Flags().String(
+		"follow-up",
+		"",
+		"When to follow up, formatted YYYY-MM-DD or relative like +7d or +2w",
+	)
+	createCmd.Flags().StringSlice("tag", nil, "Tags to give the job (comma separated)")
+	createCmd.RegisterFlagCompletionFunc("tag", completeTags)
+	createCmd.Flags().StringArray("set", nil, "Set a custom field, formatted name=value (repeatable)")
+	createCmd.RegisterFlagCompletionFunc("set", completeFields)
 }
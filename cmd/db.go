@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/valentino7504/jobtrack/internal/db"
+)
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Manage the jobtrack database.",
+	Long: `Manage the database jobtrack stores job applications in.
+
+Examples:
+  jobtrack db migrate --status    # Show applied and pending schema migrations
+  jobtrack db migrate             # Apply every pending migration
+`,
+}
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Apply pending schema migrations or show their status.",
+	Long: `Apply pending schema migrations to the database.
+
+Migrations are normally applied automatically whenever jobtrack starts. This command
+lets you inspect which migrations have been applied, or migrate to a specific version.
+Migrating down is not supported.
+
+Examples:
+  jobtrack db migrate              # Migrate to the latest schema version
+  jobtrack db migrate --status     # List every migration and whether it has been applied
+  jobtrack db migrate --to 1       # Apply migrations up to and including version 1
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		showStatus, _ := cmd.Flags().GetBool("status")
+		target, _ := cmd.Flags().GetInt("to")
+		if showStatus {
+			statuses, err := Store.MigrationStatuses()
+			if err != nil {
+				fmt.Println("Error getting migration status:", err)
+				return
+			}
+			printMigrationStatuses(statuses)
+			return
+		}
+		if target == -1 {
+			target = db.LatestVersion()
+		}
+		before, err := Store.SchemaVersion()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if err := Store.Migrate(target); err != nil {
+			fmt.Println(err)
+			return
+		}
+		if before == target {
+			fmt.Println("Database is already at version", target)
+			return
+		}
+		fmt.Printf("Database migrated from version %d to %d\n", before, target)
+	},
+}
+
+func printMigrationStatuses(statuses []db.MigrationStatus) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.Debug|tabwriter.AlignRight)
+	fmt.Fprintf(w, "Version\tDescription\tApplied At\n")
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.AppliedAt != nil {
+			appliedAt = db.FormatDateTime(*status.AppliedAt, false)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Description, appliedAt)
+	}
+	w.Flush()
+}
+
+// ManagesMigrations reports whether the command being run applies schema migrations
+// itself, in which case they should not be applied automatically on startup.
+func ManagesMigrations() bool {
+	c, _, err := rootCmd.Find(os.Args[1:])
+	return err == nil && c == migrateCmd
+}
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+	dbCmd.AddCommand(migrateCmd)
+	migrateCmd.Flags().Bool("status", false, "Show applied and pending migrations without applying anything")
+	migrateCmd.Flags().Int("to", -1, "Migrate up to this schema version (defaults to the latest)")
+}
@@ -23,12 +23,15 @@ Examples:
   jobtrack delete --id 10    # Deletes the job with ID 10
 `,
 	Run: func(cmd *cobra.Command, args []string) {
-		id, _ := cmd.Flags().GetInt("id")
+		id, ok := jobIDFlag(cmd, "id")
+		if !ok {
+			return
+		}
 		if id == -1 {
 			fmt.Println("Specify the id of the job you want to delete")
 			return
 		}
-		job, err := db.GetJobByID(SqliteDB, id)
+		job, err := Store.GetJobByID(id)
 		if err != nil {
 			fmt.Println("Error accessing job with id", id)
 			return
@@ -38,25 +41,54 @@ Examples:
 			return
 		}
 		force, _ := cmd.Flags().GetBool("force")
-		if force {
-			db.DeleteJobByID(SqliteDB, id)
+		if !force && !confirmJobDeletion(job) {
+			return
+		}
+		deleted, err := Store.DeleteJobByID(id)
+		if err != nil {
+			fmt.Println("Error deleting job:", err)
 			return
 		}
-		fmt.Println("Job to be deleted:")
-		jobPrinter.PrintJob(job)
-		reader := bufio.NewReader(os.Stdin)
-		fmt.Printf("\nAre you sure? ([Y]/n): ")
-		response, _ := reader.ReadString('\n')
-		response = strings.TrimSpace(response)
-		if strings.ToLower(response) == "n" {
+		if !deleted {
+			fmt.Println("No job found with ID:", id)
 			return
 		}
-		db.DeleteJobByID(SqliteDB, id)
+		fmt.Printf("Application for %s at %s (ID: %d) deleted\n", job.Position, job.Company, id)
 	},
 }
 
+// confirmJobDeletion shows the job about to be deleted and asks the user to confirm.
+func confirmJobDeletion(job *db.Job) bool {
+	var err error
+	job.Notes, err = Store.GetNotes(job.ID)
+	if err != nil {
+		fmt.Println("Error getting notes:", err)
+		return false
+	}
+	job.Contacts, err = Store.GetJobContacts(job.ID)
+	if err != nil {
+		fmt.Println("Error getting contacts:", err)
+		return false
+	}
+	fmt.Println("Job to be deleted:")
+	jobPrinter.PrintJob(job)
+	return confirm("\nAre you sure?")
+}
+
+// stdin is shared by every prompt, so input buffered while answering one prompt is not
+// lost to the next.
+var stdin = bufio.NewReader(os.Stdin)
+
+// confirm asks the user a yes/no question on standard input, defaulting to yes.
+func confirm(question string) bool {
+	fmt.Printf("%s ([Y]/n): ", question)
+	response, _ := stdin.ReadString('\n')
+	response = strings.TrimSpace(response)
+	return strings.ToLower(response) != "n"
+}
+
 func init() {
 	rootCmd.AddCommand(deleteCmd)
 	deleteCmd.Flags().Bool("force", false, "Skip confirmation prompt")
-	deleteCmd.Flags().Int("id", -1, "Specify the ID of the job you want to delete")
+	addJobIDFlag(deleteCmd, "id", "Specify the job you want to delete")
 }
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/valentino7504/jobtrack/internal/db"
+	"github.com/valentino7504/jobtrack/internal/jobPrinter"
+)
+
+// dueErrorExitCode is the status due exits with when it cannot check the follow-ups, so
+// scripts can tell a failure from overdue follow-ups.
+const dueErrorExitCode = 2
+
+var dueCmd = &cobra.Command{
+	Use:   "due",
+	Short: "List overdue and upcoming follow-ups, most urgent first.",
+	Long: `List the job applications you should follow up on.
+
+A job is due for a follow-up on the date set with --follow-up on create or update. Jobs without
+one are due a number of days after entering their current status, counted from the applied date
+while still in the initial status, configured per status in the [follow_up] section of the
+config file (by default 10 days in Applied and 7 in Interview).
+Jobs in a final status are never due.
+
+The command exits with status 1 when any follow-up is overdue, so it can be used from cron
+or your shell startup file, and with status 2 when the follow-ups could not be checked.
+
+Examples:
+  jobtrack due                # Overdue follow-ups and those due in the next 7 days
+  jobtrack due --days 14      # Look two weeks ahead
+  jobtrack due --summary      # Print a one line summary, e.g. for your shell startup file
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		days, _ := cmd.Flags().GetInt("days")
+		summary, _ := cmd.Flags().GetBool("summary")
+		if days < 0 {
+			fmt.Println("The number of days must not be negative")
+			exitCode = dueErrorExitCode
+			return
+		}
+		defaults, err := Config.FollowUpDefaults(db.ActivePipeline())
+		if err != nil {
+			fmt.Println(err)
+			exitCode = dueErrorExitCode
+			return
+		}
+		now := time.Now()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		followUps, err := Store.GetFollowUps(defaults, today.AddDate(0, 0, days))
+		if err != nil {
+			fmt.Println("Error getting follow-ups:", err)
+			exitCode = dueErrorExitCode
+			return
+		}
+		overdue := 0
+		for _, followUp := range followUps {
+			if followUp.DueOn.Before(today) {
+				overdue++
+			}
+		}
+		switch {
+		case summary:
+			if len(followUps) > 0 {
+				fmt.Printf(
+					"jobtrack: %d follow-ups overdue, %d due in the next %d days\n",
+					overdue,
+					len(followUps)-overdue,
+					days,
+				)
+			}
+		case len(followUps) == 0:
+			fmt.Println("No follow-ups due in the next", days, "days")
+		default:
+			jobPrinter.PrintFollowUpsTable(followUps, today)
+		}
+		if overdue > 0 {
+			exitCode = 1
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dueCmd)
+	dueCmd.Flags().Int("days", 7, "Also list follow-ups due within this many days")
+	dueCmd.Flags().Bool("summary", false, "Print a one line summary instead of a table, nothing if no follow-ups are due")
+}
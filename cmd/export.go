@@ -17,17 +17,30 @@ var exportCmd = &cobra.Command{
 	Long: `Export job applications from the database to a file or standard output.
 
 You can choose between JSON (default) and CSV formats using the --format flag.
+JSON exports include the notes of each job, CSV exports do not. Both include the tags, which
+CSV exports keep comma separated in a Tags column, and the custom fields, which CSV exports
+add as a column each after the built-in ones.
 Use --output to specify a file instead of printing to stdout.
 
+With --in-currency, salaries are also exported converted to that currency using the exchange
+rates entered with jobtrack rates, under converted_salary in JSON and in ConvertedSalaryMin,
+ConvertedSalaryMax, ConvertedCurrency and StaleRate columns in CSV. Conversions using stale
+rates are flagged as such.
+
 Examples:
   jobtrack export --format json --output jobs.json   # Save jobs as JSON
   jobtrack export --format csv --output jobs.csv     # Save jobs as CSV
   jobtrack export                                    # Print JSON to stdout
-  jobtrack export --format csv                       # Print CSV to stdout`,
+  jobtrack export --format csv                       # Print CSV to stdout
+  jobtrack export --in-currency USD -o jobs.json     # Include salaries converted to USD`,
 	Run: func(cmd *cobra.Command, args []string) {
 		exportFormat, _ := cmd.Flags().GetString("format")
 		filename, _ := cmd.Flags().GetString("output")
-		jobs, err := db.GetAllJobs(SqliteDB, true)
+		target, conversions, ok := conversionFlag(cmd)
+		if !ok {
+			return
+		}
+		jobs, err := Store.GetAllJobs(true)
 		if err != nil {
 			fmt.Println("Error getting jobs:", err)
 			return
@@ -36,6 +49,10 @@ Examples:
 			fmt.Println("No job applications available")
 			return
 		}
+		var notes []string
+		if target != "" {
+			notes = convertSalaries(jobs, target, conversions)
+		}
 		var f *os.File
 
 		if filename == "" {
@@ -59,6 +76,10 @@ Examples:
 				return
 			}
 		case "json":
+			if err := Store.AttachNotes(jobs); err != nil {
+				fmt.Println("Error getting notes:", err)
+				return
+			}
 			b, err := json.Marshal(jobs)
 			if err != nil {
 				fmt.Println("Error marshalling to JSON:", err)
@@ -82,6 +103,9 @@ Examples:
 		}
 		if filename != "" {
 			fmt.Println("Export successful:", filename)
+			for _, note := range notes {
+				fmt.Println(note)
+			}
 		}
 	},
 }
@@ -94,6 +118,7 @@ func init() {
 		"json",
 		"Specify the format you want the export to be in - csv or json",
 	)
+	exportCmd.Flags().String("in-currency", "", "Also export salaries converted to this currency, see jobtrack rates")
 	exportCmd.Flags().StringP(
 		"output",
 		"o",
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/valentino7504/jobtrack/internal/db"
+)
+
+// fieldsFlag reads the name=value custom fields given to a repeatable flag, printing the
+// problem if one is invalid.
+func fieldsFlag(cmd *cobra.Command, name string) (map[string]string, bool) {
+	values, _ := cmd.Flags().GetStringArray(name)
+	if len(values) == 0 {
+		return nil, true
+	}
+	fields := make(map[string]string, len(values))
+	for _, value := range values {
+		field, fieldValue, err := db.ParseFieldAssignment(value)
+		if err != nil {
+			fmt.Printf("Invalid --%s: %s\n", name, err)
+			return nil, false
+		}
+		fields[field] = fieldValue
+	}
+	return fields, true
+}
+
+// completeFields completes a name=value flag with the names of the custom fields in use.
+func completeFields(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if Store == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	names, err := Store.GetFieldNames()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	completions := make([]string, 0, len(names))
+	for _, name := range names {
+		completions = append(completions, name+"=")
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
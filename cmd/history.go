@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/valentino7504/jobtrack/internal/jobPrinter"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show the status history of a job application.",
+	Long: `Show every status change of a job application, oldest first.
+
+Each row shows when the status changed, the previous and new status, how long the job
+stayed in that stage and any note recorded with the change.
+
+Examples:
+  jobtrack history --id 3    # Show the timeline of the job with ID 3
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		id, ok := jobIDFlag(cmd, "id")
+		if !ok {
+			return
+		}
+		if id == -1 {
+			fmt.Println("Specify the id of the job whose history you want to see")
+			return
+		}
+		job, err := Store.GetJobByID(id)
+		if err != nil {
+			fmt.Println("Error accessing job with id", id)
+			return
+		}
+		if job == nil {
+			fmt.Println("No job found with ID:", id)
+			return
+		}
+		events, err := Store.GetStatusHistory(id)
+		if err != nil {
+			fmt.Println("Error getting status history:", err)
+			return
+		}
+		if len(events) == 0 {
+			fmt.Println("No status history recorded for this job")
+			return
+		}
+		jobPrinter.PrintStatusHistory(job, events)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	addJobIDFlag(historyCmd, "id", "Specify the job whose history you want to see")
+}
@@ -1,15 +1,14 @@
 package cmd
 
 import (
-	"encoding/csv"
-	"encoding/json"
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/valentino7504/jobtrack/internal/db"
+	"github.com/valentino7504/jobtrack/internal/jobPrinter"
 )
 
 // importCmd represents the import command
@@ -19,12 +18,38 @@ var importCmd = &cobra.Command{
 	Long: `Import job applications into the database from a JSON or CSV file.
 
 The file format is automatically detected based on the extension (.json or .csv).
-The import process will assign new IDs, ensuring no duplicates based on ID.
-If a job already exists (matching company, position, and applied date), it will be skipped.
+Imported jobs get new IDs but keep the UUID they were exported with, so a job exported
+from one database and imported into another is still recognised as the same job.
+Notes included in a JSON file are imported along with their job.
+
+A job with the same UUID as an existing job is always a duplicate of it, so importing an
+export again with --on-conflict update brings the existing jobs up to date. Other jobs are
+duplicates when they match an existing job: by default when their company,
+position and applied date are equal, use --match to compare other fields: any of company,
+position, status, location, salary-range, url and applied, separated by commas. Text is
+compared ignoring case, and posting URLs ignoring differences such as tracking parameters.
+--on-conflict decides what happens to duplicates:
+  skip       keep the existing job and drop the imported one (default)
+  update     overwrite the existing job with the imported fields and add its new notes
+  duplicate  add the imported job anyway
+  ask        ask for every duplicate
+
+Every row is checked before anything is written: company and position are required, the
+status must be part of the pipeline and dates must be formatted YYYY-MM-DD. Rows that fail
+are reported with their line number. The import is all-or-nothing, a single failing row means
+no jobs are added, unless --continue-on-error is given. Use --dry-run to check a file without
+importing it.
+
+A CSV file may start with the header row written by jobtrack export, in which case its
+columns are matched by name. Without a header the columns must be in the order of the export.
 
 Examples:
-  jobtrack import jobs.json   # Import from a JSON file
-  jobtrack import jobs.csv    # Import from a CSV file
+  jobtrack import jobs.json                      # Import from a JSON file
+  jobtrack import jobs.csv                       # Import from a CSV file
+  jobtrack import jobs.csv --dry-run             # Only report what would be imported
+  jobtrack import jobs.csv --continue-on-error   # Import the valid rows, skip the others
+  jobtrack import jobs.json --on-conflict update # Refresh existing jobs from the file
+  jobtrack import jobs.csv --match url --on-conflict ask
 
 Only .json and .csv files are supported.`,
 	Run: func(cmd *cobra.Command, args []string) {
@@ -32,27 +57,34 @@ Only .json and .csv files are supported.`,
 			fmt.Println("No file path provided")
 			return
 		}
+		continueOnError, _ := cmd.Flags().GetBool("continue-on-error")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		match, _ := cmd.Flags().GetString("match")
+		onConflictName, _ := cmd.Flags().GetString("on-conflict")
+		matchKey, err := db.ParseMatchKey(match)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		onConflict, err := db.ParseConflictAction(onConflictName)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		fp := args[0]
 		ext := filepath.Ext(fp)
-		success, failed := 0, 0
+		var rows []*db.ImportRow
 		switch ext {
 		case ".json":
 			data, err := os.ReadFile(fp)
 			if err != nil {
-				log.Fatal("Error opening file:", err)
+				fmt.Println("Error opening file:", err)
+				return
 			}
-			var jobs db.Jobs
-			err = json.Unmarshal(data, &jobs)
+			rows, err = db.ReadJSON(data)
 			if err != nil {
-				log.Fatal("Error unmarshalling JSON:", err)
-			}
-			for _, job := range jobs {
-				err := db.AddJob(SqliteDB, job)
-				if err != nil {
-					failed++
-				} else {
-					success++
-				}
+				fmt.Println(err)
+				return
 			}
 		case ".csv":
 			f, err := os.Open(fp)
@@ -61,28 +93,84 @@ Only .json and .csv files are supported.`,
 				return
 			}
 			defer f.Close()
-			r := csv.NewReader(f)
-			jobs, err := db.FromCSV(r)
+			rows, err = db.ReadCSV(f)
 			if err != nil {
-				fmt.Println("Error reading CSV file")
+				fmt.Println("Error reading CSV file:", err)
 				return
 			}
-			for _, job := range jobs {
-				db.AddJob(SqliteDB, job)
-				if err != nil {
-					failed++
-				} else {
-					success++
-				}
-			}
 		default:
 			fmt.Println("File format", ext, "not supported. Use json or csv")
 			return
 		}
-		fmt.Println("Import from", fp, "complete:", success, "jobs added,", failed, "failed.")
+		result, err := Store.ImportJobs(rows, db.ImportOptions{
+			ContinueOnError: continueOnError,
+			DryRun:          dryRun,
+			MatchKey:        matchKey,
+			OnConflict:      onConflict,
+			Ask:             askConflict,
+		})
+		if err != nil {
+			fmt.Println("Error importing jobs:", err)
+			return
+		}
+		jobPrinter.PrintImportErrors(result.Failed)
+		failed := len(result.Failed)
+		switch {
+		case failed > 0 && !continueOnError:
+			fmt.Println("Import from", fp, "aborted:", failed, "of", len(rows), "rows failed, no jobs were added.")
+			fmt.Println("Fix the rows above or use --continue-on-error to import the others")
+		case dryRun:
+			fmt.Printf(
+				"Dry run of import from %s complete: %d jobs would be added, %d updated, %d skipped as duplicates, %d failed.\n",
+				fp, result.Added, result.Updated, result.Skipped, failed,
+			)
+		default:
+			fmt.Printf(
+				"Import from %s complete: %d jobs added, %d updated, %d skipped as duplicates, %d failed.\n",
+				fp, result.Added, result.Updated, result.Skipped, failed,
+			)
+		}
 	},
 }
 
+// askConflict asks the user what to do with an imported job that duplicates an existing
+// one, defaulting to skipping it.
+func askConflict(row *db.ImportRow, existing *db.Job) db.ConflictAction {
+	fmt.Printf(
+		"Line %d: %s at %s duplicates job %d (%s at %s, applied %s)\n",
+		row.Line,
+		row.Job.Position,
+		row.Job.Company,
+		existing.ID,
+		existing.Position,
+		existing.Company,
+		db.FormatDateTime(*existing.AppliedAt, true),
+	)
+	for {
+		fmt.Print("Skip, update or add as duplicate? ([s]/u/d): ")
+		response, err := stdin.ReadString('\n')
+		switch strings.ToLower(strings.TrimSpace(response)) {
+		case "", "s", "skip":
+			return db.ConflictSkip
+		case "u", "update":
+			return db.ConflictUpdate
+		case "d", "duplicate":
+			return db.ConflictDuplicate
+		}
+		if err != nil {
+			return db.ConflictSkip
+		}
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(importCmd)
+	importCmd.Flags().Bool("continue-on-error", false, "Import the valid rows even if some rows fail")
+	importCmd.Flags().Bool("dry-run", false, "Check the file and report what would be imported without adding anything")
+	importCmd.Flags().String("match", "company,position,applied", "The fields that must be equal for a job to be a duplicate")
+	importCmd.Flags().String("on-conflict", "skip", "What to do with duplicates: skip, update, duplicate or ask")
+	importCmd.RegisterFlagCompletionFunc(
+		"on-conflict",
+		cobra.FixedCompletions(db.ConflictActions, cobra.ShellCompDirectiveNoFileComp),
+	)
 }
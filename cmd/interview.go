@@ -0,0 +1,330 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/valentino7504/jobtrack/internal/db"
+	"github.com/valentino7504/jobtrack/internal/jobPrinter"
+)
+
+var interviewCmd = &cobra.Command{
+	Use:   "interview",
+	Short: "Schedule interview rounds and export them to your calendar.",
+	Long: `Record the interview rounds of your job applications.
+
+Times are given as "YYYY-MM-DD HH:MM" in the time zone passed with --tz (an IANA name such
+as Europe/London), or in your local time zone if --tz is not given.
+
+Examples:
+  jobtrack interview add --job-id 3 --round "Phone Screen" --start "2025-04-02 15:00" --duration 30m
+  jobtrack interview add --job-id 3 --round Onsite --start "2025-04-10 09:00" --end "2025-04-10 13:00" --tz America/New_York
+  jobtrack interview list --job-id 3
+  jobtrack interview upcoming --days 14
+  jobtrack interview update --id 2 --outcome Passed
+  jobtrack interview export --ics --output interviews.ics
+`,
+}
+
+// parseInterviewTime parses a time given on the command line in the given zone.
+func parseInterviewTime(value string, zone *time.Location) (*time.Time, error) {
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04", time.DateTime} {
+		if t, err := time.ParseInLocation(layout, value, zone); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("Time %q is not formatted YYYY-MM-DD HH:MM", value)
+}
+
+// interviewTimes reads the --tz, --start, --end and --duration flags. Unset times are nil.
+func interviewTimes(cmd *cobra.Command) (tz string, startsAt *time.Time, endsAt *time.Time, err error) {
+	tz, _ = cmd.Flags().GetString("tz")
+	start, _ := cmd.Flags().GetString("start")
+	end, _ := cmd.Flags().GetString("end")
+	duration, _ := cmd.Flags().GetDuration("duration")
+	zone := time.Local
+	if tz != "" {
+		if zone, err = time.LoadLocation(tz); err != nil {
+			return "", nil, nil, fmt.Errorf("Unknown time zone %q", tz)
+		}
+	}
+	if start != "" {
+		if startsAt, err = parseInterviewTime(start, zone); err != nil {
+			return "", nil, nil, err
+		}
+	}
+	if end != "" && duration != 0 {
+		return "", nil, nil, fmt.Errorf("Use either --end or --duration, not both")
+	}
+	if end != "" {
+		if endsAt, err = parseInterviewTime(end, zone); err != nil {
+			return "", nil, nil, err
+		}
+	}
+	if duration != 0 {
+		if startsAt == nil {
+			return "", nil, nil, fmt.Errorf("--duration requires --start")
+		}
+		t := startsAt.Add(duration)
+		endsAt = &t
+	}
+	return tz, startsAt, endsAt, nil
+}
+
+var interviewAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Schedule an interview round for a job application.",
+	Run: func(cmd *cobra.Command, args []string) {
+		jobID, ok := jobIDFlag(cmd, "job-id")
+		if !ok {
+			return
+		}
+		round, _ := cmd.Flags().GetString("round")
+		location, _ := cmd.Flags().GetString("location")
+		link, _ := cmd.Flags().GetString("link")
+		interviewers, _ := cmd.Flags().GetString("interviewers")
+		outcome, _ := cmd.Flags().GetString("outcome")
+		if jobID == -1 {
+			fmt.Println("Specify the id of the job the interview is for")
+			return
+		}
+		if round == "" {
+			fmt.Println("Interview round not specified")
+			return
+		}
+		tz, startsAt, endsAt, err := interviewTimes(cmd)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if startsAt == nil {
+			fmt.Println("Interview start time not specified")
+			return
+		}
+		job, err := Store.GetJobByID(jobID)
+		if err != nil {
+			fmt.Println("Error accessing job with id", jobID)
+			return
+		}
+		if job == nil {
+			fmt.Println("No job found with ID:", jobID)
+			return
+		}
+		interview := db.Interview{
+			JobID:        jobID,
+			Round:        round,
+			StartsAt:     startsAt,
+			EndsAt:       endsAt,
+			TimeZone:     optionalSQL(tz),
+			Location:     optionalSQL(location),
+			VideoLink:    optionalSQL(link),
+			Interviewers: optionalSQL(interviewers),
+			Outcome:      optionalSQL(outcome),
+		}
+		if err := Store.AddInterview(&interview); err != nil {
+			fmt.Println("Error adding interview:", err)
+			return
+		}
+		fmt.Printf("%s interview (ID: %d) scheduled for %s at %s\n", round, interview.ID, job.Position, job.Company)
+	},
+}
+
+var interviewListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List interviews, optionally only those of one job application.",
+	Run: func(cmd *cobra.Command, args []string) {
+		jobID, ok := jobIDFlag(cmd, "job-id")
+		if !ok {
+			return
+		}
+		interviews, err := Store.GetInterviews(jobID)
+		if err != nil {
+			fmt.Println("Error getting interviews:", err)
+			return
+		}
+		if len(interviews) == 0 {
+			fmt.Println("No interviews found")
+			return
+		}
+		jobPrinter.PrintInterviewsTable(interviews)
+	},
+}
+
+var interviewUpcomingCmd = &cobra.Command{
+	Use:   "upcoming",
+	Short: "List the interviews coming up in the next few days.",
+	Run: func(cmd *cobra.Command, args []string) {
+		days, _ := cmd.Flags().GetInt("days")
+		if days < 0 {
+			fmt.Println("The number of days must not be negative")
+			return
+		}
+		interviews, err := Store.GetUpcomingInterviews(days)
+		if err != nil {
+			fmt.Println("Error getting interviews:", err)
+			return
+		}
+		if len(interviews) == 0 {
+			fmt.Println("No interviews in the next", days, "days")
+			return
+		}
+		jobPrinter.PrintInterviewsTable(interviews)
+	},
+}
+
+var interviewUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Update an interview, for example to reschedule it or record the outcome.",
+	Run: func(cmd *cobra.Command, args []string) {
+		id, _ := cmd.Flags().GetInt("id")
+		if id == -1 {
+			fmt.Println("Specify the id of the interview you want to update")
+			return
+		}
+		round, _ := cmd.Flags().GetString("round")
+		location, _ := cmd.Flags().GetString("location")
+		link, _ := cmd.Flags().GetString("link")
+		interviewers, _ := cmd.Flags().GetString("interviewers")
+		outcome, _ := cmd.Flags().GetString("outcome")
+		tz, startsAt, endsAt, err := interviewTimes(cmd)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		interview, err := Store.UpdateInterview(id, db.UpdatedInterviewParams{
+			Round:        processParam(round),
+			StartsAt:     startsAt,
+			EndsAt:       endsAt,
+			TimeZone:     processParam(tz),
+			Location:     processParam(location),
+			VideoLink:    processParam(link),
+			Interviewers: processParam(interviewers),
+			Outcome:      processParam(outcome),
+		})
+		if err != nil {
+			fmt.Println("Error updating interview:", err)
+			return
+		}
+		if interview == nil {
+			fmt.Println("No interview found with ID:", id)
+			return
+		}
+		fmt.Println("Interview with id:", interview.ID, "has been updated")
+	},
+}
+
+var interviewDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Delete an interview.",
+	Run: func(cmd *cobra.Command, args []string) {
+		id, _ := cmd.Flags().GetInt("id")
+		if id == -1 {
+			fmt.Println("Specify the id of the interview you want to delete")
+			return
+		}
+		deleted, err := Store.DeleteInterview(id)
+		if err != nil {
+			fmt.Println("Error deleting interview:", err)
+			return
+		}
+		if !deleted {
+			fmt.Println("No interview found with ID:", id)
+			return
+		}
+		fmt.Println("Interview with id:", id, "has been deleted")
+	},
+}
+
+var interviewExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export interviews as an iCalendar file.",
+	Long: `Export interviews as an RFC 5545 iCalendar (.ics) file.
+
+The file can be imported into, or subscribed to from, any calendar client. Each interview keeps
+the same event UID across exports, so re-exporting updates events rather than duplicating them.
+
+Examples:
+  jobtrack interview export --ics --output interviews.ics
+  jobtrack interview export --ics --job-id 3`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ics, _ := cmd.Flags().GetBool("ics")
+		jobID, ok := jobIDFlag(cmd, "job-id")
+		if !ok {
+			return
+		}
+		filename, _ := cmd.Flags().GetString("output")
+		if !ics {
+			fmt.Println("Specify the export format, only --ics is supported")
+			return
+		}
+		interviews, err := Store.GetInterviews(jobID)
+		if err != nil {
+			fmt.Println("Error getting interviews:", err)
+			return
+		}
+		f := os.Stdout
+		if filename != "" {
+			f, err = os.Create(filename)
+			if err != nil {
+				fmt.Println("Error creating export file:", err)
+				return
+			}
+			defer f.Close()
+		}
+		if _, err := f.WriteString(interviews.ToICS()); err != nil {
+			fmt.Println("Error writing calendar file:", err)
+			return
+		}
+		if filename != "" {
+			fmt.Println("Export successful:", filename)
+		}
+	},
+}
+
+// addInterviewFlags adds the flags for the details of an interview to cmd.
+func addInterviewFlags(cmd *cobra.Command) {
+	cmd.Flags().String("round", "", "The name of the interview round, e.g. Phone Screen or Onsite")
+	cmd.Flags().String("start", "", "When the interview starts, formatted YYYY-MM-DD HH:MM")
+	cmd.Flags().String("end", "", "When the interview ends, formatted YYYY-MM-DD HH:MM")
+	cmd.Flags().Duration("duration", 0, "How long the interview lasts, e.g. 45m, instead of --end")
+	cmd.Flags().String("tz", "", "The IANA time zone of --start and --end, e.g. Europe/London (default local)")
+	cmd.Flags().String("location", "", "Where the interview takes place")
+	cmd.Flags().String("link", "", "The video call link of the interview")
+	cmd.Flags().String("interviewers", "", "Who is interviewing you")
+	cmd.Flags().String("outcome", "", "The outcome of the interview, e.g. Pending, Passed, Failed or Cancelled")
+	cmd.RegisterFlagCompletionFunc("outcome", cobra.FixedCompletions(db.InterviewOutcomes, cobra.ShellCompDirectiveNoFileComp))
+}
+
+func init() {
+	rootCmd.AddCommand(interviewCmd)
+	interviewCmd.AddCommand(
+		interviewAddCmd,
+		interviewListCmd,
+		interviewUpcomingCmd,
+		interviewUpdateCmd,
+		interviewDeleteCmd,
+		interviewExportCmd,
+	)
+	addJobIDFlag(interviewAddCmd, "job-id", "Specify the job the interview is for")
+	addInterviewFlags(interviewAddCmd)
+
+	addJobIDFlag(interviewListCmd, "job-id", "List only the interviews of this job")
+
+	interviewUpcomingCmd.Flags().Int("days", 7, "How many days ahead to look")
+
+	interviewUpdateCmd.Flags().Int("id", -1, "Specify the ID of the interview to update")
+	addInterviewFlags(interviewUpdateCmd)
+
+	interviewDeleteCmd.Flags().Int("id", -1, "Specify the ID of the interview to delete")
+
+	interviewExportCmd.Flags().Bool("ics", false, "Export as an iCalendar file")
+	addJobIDFlag(interviewExportCmd, "job-id", "Export only the interviews of this job")
+	interviewExportCmd.Flags().StringP(
+		"output",
+		"o",
+		"",
+		"Specify the output file (leave empty to print to stdout)",
+	)
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// addJobIDFlag adds a flag identifying a job by its ID or a prefix of its UUID.
+func addJobIDFlag(cmd *cobra.Command, name string, usage string) {
+	cmd.Flags().String(name, "", usage+" (its ID or a prefix of its UUID)")
+}
+
+// jobIDFlag returns the ID of the job given by a flag added with addJobIDFlag, or -1 if
+// the flag is not set. It prints an error and returns false if no single job matches.
+func jobIDFlag(cmd *cobra.Command, name string) (int, bool) {
+	ref, _ := cmd.Flags().GetString(name)
+	if ref == "" {
+		return -1, true
+	}
+	id, err := Store.ResolveJobID(ref)
+	if err != nil {
+		fmt.Println(err)
+		return 0, false
+	}
+	return id, true
+}
@@ -2,40 +2,181 @@ package cmd
 
 import (
 	"fmt"
-	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/valentino7504/jobtrack/internal/db"
 	"github.com/valentino7504/jobtrack/internal/jobPrinter"
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
 )
 
+// initializeQuery builds a job query from the filter, sort and pagination flags of listCmd.
+func initializeQuery(cmd *cobra.Command) *db.JobQuery {
+	var query db.JobQuery
+	statuses, _ := cmd.Flags().GetStringSlice("status")
+	for _, name := range statuses {
+		status, ok := resolveStatus(name)
+		if !ok {
+			return nil
+		}
+		query.Statuses = append(query.Statuses, status)
+	}
+	if after, _ := cmd.Flags().GetString("after"); after != "" {
+		afterTime, err := db.ParseDateTime(after, true)
+		if err != nil {
+			fmt.Println("Invalid --after date:", err)
+			return nil
+		}
+		query.AppliedAfter = afterTime
+	}
+	if before, _ := cmd.Flags().GetString("before"); before != "" {
+		beforeTime, err := db.ParseDateTime(before, true)
+		if err != nil {
+			fmt.Println("Invalid --before date:", err)
+			return nil
+		}
+		query.AppliedBefore = beforeTime
+	}
+	query.Company, _ = cmd.Flags().GetString("company")
+	query.Position, _ = cmd.Flags().GetString("position")
+	query.Location, _ = cmd.Flags().GetString("location")
+	query.Contact, _ = cmd.Flags().GetString("contact")
+	remote, _ := cmd.Flags().GetBool("remote")
+	mode, _ := cmd.Flags().GetString("work-mode")
+	if remote && mode != "" {
+		fmt.Println("Use either --remote or --work-mode, not both")
+		return nil
+	}
+	if remote {
+		query.WorkMode = db.REMOTE
+	} else if mode != "" {
+		workMode, err := db.ParseWorkMode(mode)
+		if err != nil {
+			fmt.Println(err)
+			return nil
+		}
+		query.WorkMode = workMode
+	}
+	if country, _ := cmd.Flags().GetString("country"); country != "" {
+		code, err := db.NormalizeCountry(country)
+		if err != nil {
+			fmt.Println(err)
+			return nil
+		}
+		query.Country = code
+	}
+	if city, _ := cmd.Flags().GetString("city"); city != "" {
+		query.City = db.NormalizeCity(city)
+	}
+	tags, ok := tagsFlag(cmd, "tag")
+	if !ok {
+		return nil
+	}
+	query.Tags = tags
+	query.AnyTag, _ = cmd.Flags().GetBool("any-tag")
+	fields, ok := fieldsFlag(cmd, "where")
+	if !ok {
+		return nil
+	}
+	for name, value := range fields {
+		query.Fields = append(query.Fields, db.FieldFilter{Name: name, Value: value})
+	}
+	query.MinSalary, _ = cmd.Flags().GetFloat64("min-salary")
+	if query.MinSalary < 0 {
+		fmt.Println("The minimum salary must not be negative")
+		return nil
+	}
+	if code, _ := cmd.Flags().GetString("currency"); code != "" {
+		currency, err := db.NormalizeCurrency(code)
+		if err != nil {
+			fmt.Println(err)
+			return nil
+		}
+		query.Currency = currency
+	}
+
+	sort, _ := cmd.Flags().GetString("sort")
+	latest, _ := cmd.Flags().GetBool("latest")
+	if latest && sort != "" {
+		fmt.Println("Use either --latest or --sort, not both")
+		return nil
+	}
+	if latest {
+		sort = "applied:desc"
+	}
+	var sortFields []db.SortField
+	var err error
+	if sort == "" {
+		sortFields, err = Config.ListSort()
+	} else {
+		sortFields, err = db.ParseSort(sort)
+	}
+	if err != nil {
+		fmt.Println(err)
+		return nil
+	}
+	query.Sort = sortFields
+	query.Limit, _ = cmd.Flags().GetInt("limit")
+	query.Offset, _ = cmd.Flags().GetInt("offset")
+	return &query
+}
+
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List job applications with optional filters and sorting.",
 	Long: `Retrieve job applications from the database.
 
-By default, this command lists all jobs. You can filter results by ID, status, or applied date,
-and sort them by latest or oldest.
+By default, this command lists all jobs, oldest application first. Filters can be combined and
+a job must match all of them to be listed. Results can be sorted by any field and paginated.
+
+Sort fields are id, company, position, status, location, country, city, salary, applied, created and updated,
+each optionally followed by :asc or :desc. Separate several fields with commas. The default
+order and the columns shown can be changed with the list.sort and list.columns settings.
+
+Salaries are parsed from the salary range entered, so "$120,000 - $150,000", "120-150k" and
+"45/hr" can be filtered and sorted. --min-salary and the salary sort compare the top of each
+range as a yearly amount, counting 2080 hours or 12 months a year. Jobs whose salary range has
+no amount are left out by --min-salary and --currency.
+
+Locations are parsed too, into a work mode (remote, hybrid or onsite), city, region and country,
+so "Remote - US", "NYC (hybrid)" and "Austin, TX" can be filtered with --remote, --work-mode,
+--country and --city. Countries are matched by name or ISO code, and well known cities by their
+usual abbreviations, such as NYC or SF. See jobtrack locations for applications by location.
+
+With --in-currency, salaries are converted using the exchange rates entered with jobtrack rates
+before being compared and shown. Salaries converted with stale rates are marked with a *, and
+those in a currency without a rate are left out by --min-salary and sorted as if they had none.
+
+Columns are id, uuid, company, position, status, location, work-mode, city, region, country,
+salary, url, tags, applied, follow-up, created and updated. Any other name shows the custom field of that name.
 
 Examples:
-  jobtrack list                           # List all job applications
-  jobtrack list --status "Interview"      # List jobs with status "Interview"
-  jobtrack list --latest                  # List jobs sorted by most recent first
-  jobtrack list --after "2024-01-01"      # List jobs applied on or after Jan 1, 2024
+  jobtrack list                                       # List all job applications
+  jobtrack list --id 3                                # Show the job with ID 3
+  jobtrack list --status "Interview"                  # List jobs with status "Interview"
+  jobtrack list --status Applied,Interview            # List jobs with either status
+  jobtrack list --status Interview --after 2025-01-01 # Interviews for jobs applied to this year
+  jobtrack list --company google --position engineer  # Substring matches, ignoring case
+  jobtrack list --contact "jane"                      # Jobs linked to a contact named Jane
+  jobtrack list --tag referral,backend                # Jobs tagged both referral and backend
+  jobtrack list --tag dream,referral --any-tag        # Jobs tagged dream or referral
+  jobtrack list --where team=payments                 # Jobs whose team custom field is Payments
+  jobtrack list --remote --country US                 # Remote jobs in the United States
+  jobtrack list --work-mode hybrid --city NYC         # Hybrid jobs in New York
+  jobtrack list --columns id,company,status,team      # Show the team custom field as a column
+  jobtrack list --min-salary 120000 --currency USD    # Jobs paying at least $120,000 a year
+  jobtrack list --sort salary:desc                    # Best paid jobs first
+  jobtrack list --in-currency EUR --sort salary:desc  # Compare salaries in euros
+  jobtrack list --latest                              # List jobs sorted by most recent first
+  jobtrack list --sort company,applied:desc           # Sort by company, then newest first
+  jobtrack list --limit 10 --offset 10                # Show the second page of 10 jobs
+  jobtrack list --columns id,company,status,follow-up # Choose the columns shown
 `,
 	Run: func(cmd *cobra.Command, args []string) {
-		jobID, _ := cmd.Flags().GetInt("id")
-		status, _ := cmd.Flags().GetString("status")
-		after, _ := cmd.Flags().GetString("after")
-		before, _ := cmd.Flags().GetString("before")
-		caser := cases.Title(language.English)
-		status = caser.String(status)
-
-		switch {
-		case jobID > -1:
-			job, err := db.GetJobByID(SqliteDB, jobID)
+		jobID, ok := jobIDFlag(cmd, "id")
+		if !ok {
+			return
+		}
+		if jobID > -1 {
+			job, err := Store.GetJobByID(jobID)
 			if err != nil {
 				fmt.Println("Error getting job:", err)
 				return
@@ -44,48 +185,84 @@ Examples:
 				fmt.Println("No job found with ID:", jobID)
 				return
 			}
-			jobPrinter.PrintJob(job)
-		case status != "":
-			jobs, err := db.GetJobsByStatus(SqliteDB, db.JobStatus(status))
-			if err != nil {
-				fmt.Println("Error getting jobs:", err)
-				return
-			}
-			if len(jobs) == 0 {
-				fmt.Println("No jobs found with that status")
-				return
-			}
-			jobPrinter.PrintJobsTable(jobs)
-		case cmd.Flags().Changed("after") || cmd.Flags().Changed("before"):
-			jobs, err := db.GetJobsByDate(SqliteDB, before, after)
+			job.Notes, err = Store.GetNotes(jobID)
 			if err != nil {
-				fmt.Println(err)
-				return
-			}
-			if len(jobs) == 0 {
-				fmt.Println("No jobs found within the specified date range")
+				fmt.Println("Error getting notes:", err)
 				return
 			}
-			jobPrinter.PrintJobsTable(jobs)
-		default:
-			jobs, err := db.GetAllJobs(SqliteDB, false)
+			job.Contacts, err = Store.GetJobContacts(jobID)
 			if err != nil {
-				fmt.Println("Error getting jobs", err)
+				fmt.Println("Error getting contacts:", err)
 				return
 			}
-			if len(jobs) == 0 {
-				fmt.Println("No job applications available")
+			jobPrinter.PrintJob(job)
+			return
+		}
+		query := initializeQuery(cmd)
+		if query == nil {
+			return
+		}
+		target, conversions, ok := conversionFlag(cmd)
+		if !ok {
+			return
+		}
+		query.Conversions = conversions
+		jobs, err := Store.QueryJobs(*query)
+		if err != nil {
+			fmt.Println("Error getting jobs:", err)
+			return
+		}
+		printActiveProfile()
+		if len(jobs) == 0 {
+			fmt.Println("No job applications found")
+			return
+		}
+		var columns []string
+		if cmd.Flags().Changed("columns") {
+			value, _ := cmd.Flags().GetString("columns")
+			if columns, err = jobPrinter.ParseColumns(value); err != nil {
+				fmt.Println(err)
 				return
 			}
-			jobPrinter.PrintJobsTable(jobs)
+		}
+		var notes []string
+		if target != "" {
+			notes = convertSalaries(jobs, target, conversions)
+		}
+		jobPrinter.PrintJobsTable(jobs, columns...)
+		for _, note := range notes {
+			fmt.Println(note)
 		}
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(listCmd)
-	listCmd.Flags().Int("id", -1, "The integer index of the job")
-	listCmd.Flags().String("status", "", "The status of the job")
-	listCmd.Flags().String("after", "1970-01-01", "List jobs applied on or after this date")
-	listCmd.Flags().String("before", db.FormatDateTime(time.Now(), true), "List jobs applied on or before this date")
+	addJobIDFlag(listCmd, "id", "Show a single job")
+	listCmd.Flags().StringSlice("status", nil, "List jobs with any of these statuses (comma separated)")
+	listCmd.RegisterFlagCompletionFunc("status", completeStatuses)
+	listCmd.Flags().String("after", "", "List jobs applied on or after this date (YYYY-MM-DD)")
+	listCmd.Flags().String("before", "", "List jobs applied on or before this date (YYYY-MM-DD)")
+	listCmd.Flags().String("company", "", "List jobs whose company contains this text")
+	listCmd.Flags().String("position", "", "List jobs whose position contains this text")
+	listCmd.Flags().String("location", "", "List jobs whose location contains this text")
+	listCmd.Flags().Bool("remote", false, "List remote jobs, same as --work-mode remote")
+	listCmd.Flags().String("work-mode", "", "List jobs with this work mode: remote, hybrid or onsite")
+	listCmd.RegisterFlagCompletionFunc("work-mode", completeWorkModes)
+	listCmd.Flags().String("country", "", "List jobs in this country, by name or ISO code such as US")
+	listCmd.Flags().String("city", "", "List jobs in this city")
+	listCmd.Flags().String("contact", "", "List jobs linked to the contact with this ID or whose name contains this text")
+	listCmd.Flags().StringSlice("tag", nil, "List jobs with all of these tags (comma separated)")
+	listCmd.RegisterFlagCompletionFunc("tag", completeTags)
+	listCmd.Flags().Bool("any-tag", false, "List jobs with any of the --tag tags rather than all of them")
+	listCmd.Flags().StringArray("where", nil, "List jobs whose custom field has this value, formatted name=value (repeatable)")
+	listCmd.RegisterFlagCompletionFunc("where", completeFields)
+	listCmd.Flags().Float64("min-salary", 0, "List jobs paying at least this much a year at the top of their salary range")
+	listCmd.Flags().String("currency", "", "List jobs whose salary is in this currency, such as USD")
+	listCmd.Flags().String("in-currency", "", "Convert salaries to this currency for --min-salary, sorting and display, see jobtrack rates")
+	listCmd.Flags().String("sort", "", "Sort by field[:asc|desc], comma separated (defaults to the list.sort setting)")
+	listCmd.Flags().Bool("latest", false, "Sort by most recent application first, same as --sort applied:desc")
+	listCmd.Flags().Int("limit", 0, "Show at most this many jobs (0 shows all)")
+	listCmd.Flags().Int("offset", 0, "Skip this many jobs before listing")
+	listCmd.Flags().String("columns", "", "The columns to show, comma separated (defaults to the list.columns setting)")
 }
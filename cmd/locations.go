@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/valentino7504/jobtrack/internal/db"
+	"github.com/valentino7504/jobtrack/internal/jobPrinter"
+)
+
+var locationsCmd = &cobra.Command{
+	Use:   "locations",
+	Short: "Report how many applications there are in each location.",
+	Long: `Report how many job applications there are in each country, region, city or work mode,
+and how many of them have each status, the places with the most applications first.
+
+Locations are parsed from the location entered on each job: "Remote - US", "NYC (hybrid)" and
+"Austin, TX" are read as a work mode (remote, hybrid or onsite), city, region and country.
+Applications whose location does not say are counted as Unknown.
+
+Examples:
+  jobtrack locations                  # Applications by country
+  jobtrack locations --by city        # Applications by city
+  jobtrack locations --by mode        # Remote, hybrid and onsite applications
+  jobtrack list --remote --country US # The remote jobs in the United States
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		by, _ := cmd.Flags().GetString("by")
+		counts, err := Store.GetLocationCounts(db.LocationGroup(by))
+		if err != nil {
+			fmt.Println("Error getting locations:", err)
+			return
+		}
+		if len(counts) == 0 {
+			fmt.Println("No jobs found")
+			return
+		}
+		jobPrinter.PrintLocationsTable(counts)
+	},
+}
+
+// completeWorkModes completes a work mode flag.
+func completeWorkModes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	modes := make([]string, 0, len(db.WorkModes))
+	for _, mode := range db.WorkModes {
+		modes = append(modes, string(mode))
+	}
+	return modes, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	rootCmd.AddCommand(locationsCmd)
+	locationsCmd.Flags().String("by", string(db.BY_COUNTRY), "Group applications by mode, country, region or city")
+	locationsCmd.RegisterFlagCompletionFunc("by", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		groups := make([]string, 0, len(db.LocationGroups))
+		for _, group := range db.LocationGroups {
+			groups = append(groups, string(group))
+		}
+		return groups, cobra.ShellCompDirectiveNoFileComp
+	})
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/valentino7504/jobtrack/internal/jobPrinter"
+)
+
+var noteCmd = &cobra.Command{
+	Use:   "note",
+	Short: "Add, list, edit and remove notes on job applications.",
+	Long: `Keep timestamped notes on a job application, such as recruiter names, prep items or feedback.
+
+Notes are shown when viewing a single job and are included in JSON exports.
+
+Examples:
+  jobtrack note add --id 3 "Recruiter is Jane, follow up on Friday"
+  jobtrack note list --id 3
+  jobtrack note edit --note-id 7 "Recruiter is Jane Doe"
+  jobtrack note rm --note-id 7
+`,
+}
+
+var noteAddCmd = &cobra.Command{
+	Use:   "add --id ID TEXT",
+	Short: "Add a note to a job application.",
+	Run: func(cmd *cobra.Command, args []string) {
+		jobID, ok := jobIDFlag(cmd, "id")
+		if !ok {
+			return
+		}
+		if jobID == -1 {
+			fmt.Println("Specify the id of the job to add the note to")
+			return
+		}
+		body := strings.TrimSpace(strings.Join(args, " "))
+		if body == "" {
+			fmt.Println("Note text not specified")
+			return
+		}
+		job, err := Store.GetJobByID(jobID)
+		if err != nil {
+			fmt.Println("Error accessing job with id", jobID)
+			return
+		}
+		if job == nil {
+			fmt.Println("No job found with ID:", jobID)
+			return
+		}
+		note, err := Store.AddNote(jobID, body)
+		if err != nil {
+			fmt.Println("Error adding note:", err)
+			return
+		}
+		fmt.Printf("Note (ID: %d) added to job %d\n", note.ID, jobID)
+	},
+}
+
+var noteListCmd = &cobra.Command{
+	Use:   "list --id ID",
+	Short: "List the notes of a job application.",
+	Run: func(cmd *cobra.Command, args []string) {
+		jobID, ok := jobIDFlag(cmd, "id")
+		if !ok {
+			return
+		}
+		if jobID == -1 {
+			fmt.Println("Specify the id of the job whose notes you want to see")
+			return
+		}
+		notes, err := Store.GetNotes(jobID)
+		if err != nil {
+			fmt.Println("Error getting notes:", err)
+			return
+		}
+		if len(notes) == 0 {
+			fmt.Println("No notes found for job with ID:", jobID)
+			return
+		}
+		jobPrinter.PrintNotes(notes)
+	},
+}
+
+var noteEditCmd = &cobra.Command{
+	Use:   "edit --note-id ID TEXT",
+	Short: "Replace the text of a note.",
+	Run: func(cmd *cobra.Command, args []string) {
+		noteID, _ := cmd.Flags().GetInt("note-id")
+		if noteID == -1 {
+			fmt.Println("Specify the id of the note you want to edit")
+			return
+		}
+		body := strings.TrimSpace(strings.Join(args, " "))
+		if body == "" {
+			fmt.Println("Note text not specified")
+			return
+		}
+		note, err := Store.UpdateNote(noteID, body)
+		if err != nil {
+			fmt.Println("Error updating note:", err)
+			return
+		}
+		if note == nil {
+			fmt.Println("No note found with ID:", noteID)
+			return
+		}
+		fmt.Println("Note with id:", note.ID, "has been updated")
+	},
+}
+
+var noteRmCmd = &cobra.Command{
+	Use:   "rm --note-id ID",
+	Short: "Remove a note.",
+	Run: func(cmd *cobra.Command, args []string) {
+		noteID, _ := cmd.Flags().GetInt("note-id")
+		if noteID == -1 {
+			fmt.Println("Specify the id of the note you want to remove")
+			return
+		}
+		deleted, err := Store.DeleteNote(noteID)
+		if err != nil {
+			fmt.Println("Error removing note:", err)
+			return
+		}
+		if !deleted {
+			fmt.Println("No note found with ID:", noteID)
+			return
+		}
+		fmt.Println("Note with id:", noteID, "has been removed")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(noteCmd)
+	noteCmd.AddCommand(noteAddCmd, noteListCmd, noteEditCmd, noteRmCmd)
+	addJobIDFlag(noteAddCmd, "id", "Specify the job to add the note to")
+	addJobIDFlag(noteListCmd, "id", "Specify the job whose notes you want to see")
+	noteEditCmd.Flags().Int("note-id", -1, "Specify the ID of the note to edit")
+	noteRmCmd.Flags().Int("note-id", -1, "Specify the ID of the note to remove")
+}
@@ -0,0 +1,292 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/valentino7504/jobtrack/internal/db"
+	"github.com/valentino7504/jobtrack/internal/jobPrinter"
+)
+
+var offerCmd = &cobra.Command{
+	Use:   "offer",
+	Short: "Record the offers you receive and compare their compensation.",
+	Long: `Record the compensation offered for a job once it reaches the offer stage, and compare
+offers side by side.
+
+Amounts are written like 150000, 150,000 or 150k, in the currency given with --currency, or
+the currency of the job's salary, or defaults.currency. The bonus is the yearly target bonus,
+either an amount or a percentage of the base salary such as 15%. Equity is the value of the
+whole grant, which vests over the schedule given with --vesting: a number of years it vests
+evenly over such as 4y, or the percentage vesting each year such as 10/20/30/40. Grants vest
+evenly over four years unless told otherwise.
+
+The first year total adds the base salary, bonus, sign-on bonus and the equity vesting in the
+first year. The four year total adds four years of base salary and bonus, the sign-on bonus
+and the equity vesting in the first four years.
+
+Examples:
+  jobtrack offer add --job-id 3 --base 150k --bonus 15% --sign-on 20k --equity 200k --deadline 2025-07-01
+  jobtrack offer add --job-id 5 --base 120000 --currency EUR --vesting 10/20/30/40 --benefits "30 days PTO"
+  jobtrack offer list
+  jobtrack offer show 1
+  jobtrack offer compare 1 2
+  jobtrack offer compare 1 2 --in-currency USD
+`,
+}
+
+// offerIDs parses the offer IDs given as arguments.
+func offerIDs(args []string) ([]int, bool) {
+	ids := make([]int, 0, len(args))
+	for _, arg := range args {
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			fmt.Printf("Invalid offer ID %q\n", arg)
+			return nil, false
+		}
+		ids = append(ids, id)
+	}
+	return ids, true
+}
+
+// offerAmountFlag parses an amount flag of an offer. Percentages, when allowed, are
+// taken of base.
+func offerAmountFlag(cmd *cobra.Command, name string, base float64) (float64, bool) {
+	value, _ := cmd.Flags().GetString(name)
+	if value == "" {
+		return 0, true
+	}
+	if percent, ok := strings.CutSuffix(strings.TrimSpace(value), "%"); ok && base != 0 {
+		p, err := strconv.ParseFloat(strings.TrimSpace(percent), 64)
+		if err != nil {
+			fmt.Printf("Invalid --%s %q, use an amount or a percentage of the base such as 15%%\n", name, value)
+			return 0, false
+		}
+		return base * p / 100, true
+	}
+	amount, err := db.ParseAmount(value)
+	if err != nil {
+		fmt.Printf("Invalid --%s: %s\n", name, err)
+		return 0, false
+	}
+	return amount, true
+}
+
+// offerDateFlag parses a date flag of an offer, nil if it is not set.
+func offerDateFlag(cmd *cobra.Command, name string) (*time.Time, bool) {
+	value, _ := cmd.Flags().GetString(name)
+	if value == "" {
+		return nil, true
+	}
+	date, err := db.ParseRelativeDate(value, time.Now())
+	if err != nil {
+		fmt.Printf("Invalid --%s: %s\n", name, err)
+		return nil, false
+	}
+	return date, true
+}
+
+var offerAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Record the offer made for a job application.",
+	Run: func(cmd *cobra.Command, args []string) {
+		jobID, ok := jobIDFlag(cmd, "job-id")
+		if !ok {
+			return
+		}
+		if jobID == -1 {
+			fmt.Println("Specify the id of the job the offer is for")
+			return
+		}
+		if base, _ := cmd.Flags().GetString("base"); base == "" {
+			fmt.Println("Base salary of the offer not specified")
+			return
+		}
+		offer := db.Offer{JobID: jobID}
+		if offer.Base, ok = offerAmountFlag(cmd, "base", 0); !ok {
+			return
+		}
+		if offer.Bonus, ok = offerAmountFlag(cmd, "bonus", offer.Base); !ok {
+			return
+		}
+		if offer.SignOn, ok = offerAmountFlag(cmd, "sign-on", 0); !ok {
+			return
+		}
+		if offer.Equity, ok = offerAmountFlag(cmd, "equity", 0); !ok {
+			return
+		}
+		if offer.StartDate, ok = offerDateFlag(cmd, "start"); !ok {
+			return
+		}
+		if offer.Deadline, ok = offerDateFlag(cmd, "deadline"); !ok {
+			return
+		}
+		vesting, _ := cmd.Flags().GetString("vesting")
+		benefits, _ := cmd.Flags().GetString("benefits")
+		offer.Vesting = optionalSQL(vesting)
+		offer.Benefits = optionalSQL(benefits)
+		job, err := Store.GetJobByID(jobID)
+		if err != nil {
+			fmt.Println("Error accessing job with id", jobID)
+			return
+		}
+		if job == nil {
+			fmt.Println("No job found with ID:", jobID)
+			return
+		}
+		offer.Currency, _ = cmd.Flags().GetString("currency")
+		if offer.Currency == "" {
+			offer.Currency = Config.DefaultCurrency()
+			if job.Salary != nil {
+				offer.Currency = job.Salary.Currency
+			}
+		}
+		if err := Store.AddOffer(&offer); err != nil {
+			fmt.Println("Error adding offer:", err)
+			exitCode = 1
+			return
+		}
+		fmt.Printf("Offer (ID: %d) recorded for %s at %s\n", offer.ID, job.Position, job.Company)
+		fmt.Printf(
+			"First year total: %s, four year total: %s\n",
+			jobPrinter.FormatAmount(offer.Currency, offer.FirstYear()),
+			jobPrinter.FormatAmount(offer.Currency, offer.FourYear()),
+		)
+	},
+}
+
+var offerListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List offers, soonest decision deadline first.",
+	Run: func(cmd *cobra.Command, args []string) {
+		jobID, ok := jobIDFlag(cmd, "job-id")
+		if !ok {
+			return
+		}
+		offers, err := Store.GetOffers(jobID)
+		if err != nil {
+			fmt.Println("Error getting offers:", err)
+			return
+		}
+		if len(offers) == 0 {
+			fmt.Println("No offers found")
+			return
+		}
+		jobPrinter.PrintOffersTable(offers)
+	},
+}
+
+var offerShowCmd = &cobra.Command{
+	Use:   "show ID",
+	Short: "Show an offer with its compensation breakdown.",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Println("Specify the ID of the offer to show")
+			return
+		}
+		ids, ok := offerIDs(args)
+		if !ok {
+			return
+		}
+		offer, err := Store.GetOfferByID(ids[0])
+		if err != nil {
+			fmt.Println("Error getting offer:", err)
+			return
+		}
+		if offer == nil {
+			fmt.Println("No offer found with ID:", ids[0])
+			return
+		}
+		jobPrinter.PrintOffer(offer)
+	},
+}
+
+var offerCompareCmd = &cobra.Command{
+	Use:   "compare ID ID...",
+	Short: "Compare offers side by side, with their first year and four year totals.",
+	Long: `Compare offers side by side, with the total compensation of the first year and of the
+first four years of each.
+
+Offers in different currencies can be converted to one with --in-currency, using the rates
+entered with jobtrack rates.
+
+Examples:
+  jobtrack offer compare 1 2
+  jobtrack offer compare 1 2 3 --in-currency EUR`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 2 {
+			fmt.Println("Specify the IDs of at least two offers to compare")
+			return
+		}
+		ids, ok := offerIDs(args)
+		if !ok {
+			return
+		}
+		target, conversions, ok := conversionFlag(cmd)
+		if !ok {
+			return
+		}
+		offers := make([]*db.Offer, 0, len(ids))
+		currencies := make(map[string]bool)
+		for _, id := range ids {
+			offer, err := Store.GetOfferByID(id)
+			if err != nil {
+				fmt.Println("Error getting offer:", err)
+				return
+			}
+			if offer == nil {
+				fmt.Println("No offer found with ID:", id)
+				return
+			}
+			offers = append(offers, offer)
+			currencies[offer.Currency] = true
+		}
+		var notes []string
+		if target != "" {
+			staleBefore := Config.StaleRatesBefore(time.Now())
+			for currency := range currencies {
+				if c := conversions[currency]; c != nil && c.RatesOn != nil && c.RatesOn.Before(staleBefore) {
+					notes = append(notes, fmt.Sprintf(
+						"Converted with exchange rates older than %d days, update them with jobtrack rates set",
+						Config.StaleAfterDays(),
+					))
+					break
+				}
+			}
+			missing := db.ConvertOffers(offers, conversions, target)
+			if missing == 1 {
+				notes = append(notes, fmt.Sprintf("1 offer has no exchange rate to %s and is not converted", target))
+			} else if missing > 1 {
+				notes = append(notes, fmt.Sprintf("%d offers have no exchange rate to %s and are not converted", missing, target))
+			}
+		} else if len(currencies) > 1 {
+			notes = append(notes, "The offers are in different currencies, compare them in one with --in-currency")
+		}
+		jobPrinter.PrintOfferComparison(offers)
+		for _, note := range notes {
+			fmt.Println(note)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(offerCmd)
+	offerCmd.AddCommand(offerAddCmd, offerListCmd, offerShowCmd, offerCompareCmd)
+	addJobIDFlag(offerAddCmd, "job-id", "Specify the job the offer is for")
+	offerAddCmd.Flags().String("base", "", "The yearly base salary, e.g. 150000 or 150k")
+	offerAddCmd.Flags().String("bonus", "", "The yearly target bonus, an amount or a percentage of the base such as 15%")
+	offerAddCmd.Flags().String("sign-on", "", "The one-off sign-on bonus")
+	offerAddCmd.Flags().String("equity", "", "The value of the whole equity grant")
+	offerAddCmd.Flags().String("vesting", "", "How the equity vests, e.g. 4y or 10/20/30/40 (default evenly over 4 years)")
+	offerAddCmd.Flags().String("benefits", "", "Notes on the benefits, e.g. health cover or time off")
+	offerAddCmd.Flags().String("start", "", "The start date, formatted YYYY-MM-DD")
+	offerAddCmd.Flags().String("deadline", "", "When you must decide by, formatted YYYY-MM-DD or relative like +7d")
+	offerAddCmd.Flags().String("currency", "", "The currency of the amounts (defaults to the job's salary currency or defaults.currency)")
+
+	addJobIDFlag(offerListCmd, "job-id", "List only the offers of this job")
+
+	offerCompareCmd.Flags().String("in-currency", "", "Convert the offers to this currency with the exchange rates entered")
+}
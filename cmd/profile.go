@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/valentino7504/jobtrack/internal/db"
+)
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Keep separate job searches in their own profiles.",
+	Long: `Keep separate job searches, such as internships and full-time roles or the searches of
+several people you coach, in profiles with a database each.
+
+The active profile is used by every command. Switch profiles with jobtrack profile use, or use
+another one for a single command with --profile or the JOBTRACK_PROFILE environment variable.
+The "default" profile is the database jobtrack uses without profiles.
+
+Profiles are only available when jobs are kept in SQLite, the default storage.
+
+Examples:
+  jobtrack profile create internships           # Create an empty profile
+  jobtrack profile use internships              # Make it the active profile
+  jobtrack profile list                         # Show every profile and its number of jobs
+  jobtrack list --profile default               # List the jobs of another profile
+  jobtrack profile rename internships interns   # Rename a profile
+  jobtrack profile delete interns               # Delete a profile and all its jobs
+`,
+}
+
+var profileCreateCmd = &cobra.Command{
+	Use:   "create NAME",
+	Short: "Create a new, empty profile.",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Println("Specify the name of the profile to create")
+			return
+		}
+		if !checkProfileBackend() {
+			return
+		}
+		if err := db.CreateProfile(args[0]); err != nil {
+			fmt.Println("Error creating profile:", err)
+			return
+		}
+		fmt.Printf("Profile %s created, switch to it with: jobtrack profile use %s\n", args[0], args[0])
+	},
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List every profile, marking the active one.",
+	Run: func(cmd *cobra.Command, args []string) {
+		profiles, err := db.Profiles()
+		if err != nil {
+			fmt.Println("Error listing profiles:", err)
+			return
+		}
+		active := ProfileName()
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.Debug|tabwriter.AlignRight)
+		fmt.Fprintf(w, "Active\tName\tJobs\tDatabase\n")
+		for _, name := range profiles {
+			path := Config.DatabasePath(name)
+			jobs := "N/A"
+			if count, err := db.CountProfileJobs(path); err == nil {
+				jobs = fmt.Sprint(count)
+			}
+			marker := ""
+			if name == active {
+				marker = "*"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", marker, name, jobs, path)
+		}
+		w.Flush()
+	},
+}
+
+var profileUseCmd = &cobra.Command{
+	Use:               "use NAME",
+	Short:             "Make a profile the active one.",
+	ValidArgsFunction: completeProfiles,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Println("Specify the name of the profile to use")
+			return
+		}
+		if err := db.SetActiveProfile(args[0]); err != nil {
+			fmt.Println("Error switching profile:", err)
+			return
+		}
+		fmt.Println("Now using profile", args[0])
+	},
+}
+
+var profileDeleteCmd = &cobra.Command{
+	Use:               "delete NAME",
+	Short:             "Delete a profile along with all of its jobs.",
+	ValidArgsFunction: completeProfiles,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Println("Specify the name of the profile to delete")
+			return
+		}
+		name := args[0]
+		if !db.ProfileExists(name) {
+			fmt.Printf("%s %q\n", db.ErrProfileNotFound, name)
+			return
+		}
+		force, _ := cmd.Flags().GetBool("force")
+		if !force {
+			count, _ := db.CountProfileJobs(Config.DatabasePath(name))
+			if !confirm(fmt.Sprintf("Profile %s and its %d jobs will be deleted. Are you sure?", name, count)) {
+				return
+			}
+		}
+		if err := db.DeleteProfile(name); err != nil {
+			fmt.Println("Error deleting profile:", err)
+			return
+		}
+		fmt.Printf("Profile %s deleted\n", name)
+	},
+}
+
+var profileRenameCmd = &cobra.Command{
+	Use:               "rename OLD NEW",
+	Short:             "Rename a profile.",
+	ValidArgsFunction: completeProfiles,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			fmt.Println("Specify the current and the new name of the profile")
+			return
+		}
+		if err := db.RenameProfile(args[0], args[1]); err != nil {
+			fmt.Println("Error renaming profile:", err)
+			return
+		}
+		fmt.Printf("Profile %s renamed to %s\n", args[0], args[1])
+	},
+}
+
+// printActiveProfile shows which profile is in use, unless no profiles were created
+// and the default one is used.
+func printActiveProfile() {
+	profiles, _ := db.Profiles()
+	if Profile == db.DefaultProfile && len(profiles) <= 1 {
+		return
+	}
+	fmt.Printf("Profile: %s\n\n", Profile)
+}
+
+// checkProfileBackend reports whether the configured storage has profiles, printing why
+// not if it does not.
+func checkProfileBackend() bool {
+	switch Config.Storage.Backend {
+	case "", "sqlite":
+		return true
+	}
+	fmt.Printf("Profiles are only available with the sqlite backend, not %s\n", Config.Storage.Backend)
+	return false
+}
+
+// completeProfiles completes the name of an existing profile.
+func completeProfiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 && cmd.Name() != "rename" || len(args) > 1 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	profiles, _ := db.Profiles()
+	return profiles, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	rootCmd.AddCommand(profileCmd)
+	profileCmd.AddCommand(profileCreateCmd)
+	profileCmd.AddCommand(profileListCmd)
+	profileCmd.AddCommand(profileUseCmd)
+	profileCmd.AddCommand(profileDeleteCmd)
+	profileCmd.AddCommand(profileRenameCmd)
+	profileDeleteCmd.Flags().Bool("force", false, "Skip confirmation prompt")
+}
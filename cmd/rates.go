@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/valentino7504/jobtrack/internal/db"
+	"github.com/valentino7504/jobtrack/internal/jobPrinter"
+)
+
+var ratesCmd = &cobra.Command{
+	Use:   "rates",
+	Short: "Keep the exchange rates used to compare salaries in different currencies.",
+	Long: `Keep a table of exchange rates, used by --in-currency on list and export to convert salaries
+to a single currency for sorting and display.
+
+Rates are entered by you rather than fetched, so conversions work offline. A rate is the value
+of one unit of a currency in a base currency, which is defaults.currency unless --base is given,
+and applies from its effective date until a later rate replaces it. Currencies without a rate in
+the currency converted to are converted through a base currency both have a rate in, and so are
+currencies whose rates through a base currency are more recent than their direct rate.
+
+Conversions using a rate older than rates.stale_after days, 30 unless configured, are flagged
+as stale with a * in tables and "stale": true in exports.
+
+Examples:
+  jobtrack rates set EUR 1.08                       # 1 EUR is worth 1.08 of defaults.currency
+  jobtrack rates set NGN 0.00065 --base USD --date 2025-06-01
+  jobtrack rates import rates.csv                   # Rates from a CSV file
+  jobtrack rates list                               # Every rate entered
+  jobtrack list --in-currency USD --sort salary:desc
+`,
+}
+
+var ratesSetCmd = &cobra.Command{
+	Use:   "set CURRENCY RATE",
+	Short: "Set the exchange rate of a currency.",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			fmt.Println("Specify the currency and its rate, such as: jobtrack rates set EUR 1.08")
+			return
+		}
+		value, err := strconv.ParseFloat(args[1], 64)
+		if err != nil {
+			fmt.Printf("Invalid rate %q, use a number such as 1.08\n", args[1])
+			return
+		}
+		rate := &db.ExchangeRate{Currency: args[0], Base: baseFlag(cmd), Rate: value}
+		if date, _ := cmd.Flags().GetString("date"); date != "" {
+			rate.EffectiveOn, err = db.ParseRelativeDate(date, time.Now())
+			if err != nil {
+				fmt.Println("Invalid --date:", err)
+				return
+			}
+		}
+		if err := Store.SetExchangeRates([]*db.ExchangeRate{rate}); err != nil {
+			fmt.Println(err)
+			exitCode = 1
+			return
+		}
+		fmt.Printf("1 %s = %s %s from %s\n", rate.Currency, args[1], rate.Base, db.FormatDateTime(*rate.EffectiveOn, true))
+	},
+}
+
+var ratesImportCmd = &cobra.Command{
+	Use:   "import FILE",
+	Short: "Import exchange rates from a CSV file.",
+	Long: `Import exchange rates from a CSV file with a currency, rate and effective date column, and
+optionally a base column. With a header row naming the columns they can come in any order,
+without one they must be in that order. Rates without a base use --base, or defaults.currency.
+Rates of a currency taking effect on the same day as an existing one replace it.
+
+  currency,rate,date
+  EUR,1.08,2025-06-01
+  GBP,1.27,2025-06-01
+  NGN,0.00065,2025-06-01
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Println("Specify the CSV file to import rates from")
+			return
+		}
+		f, err := os.Open(args[0])
+		if err != nil {
+			fmt.Println("Error opening rates file:", err)
+			return
+		}
+		defer f.Close()
+		rates, err := db.ReadExchangeRates(f, baseFlag(cmd))
+		if err != nil {
+			fmt.Println("Error reading rates file:", err)
+			exitCode = 1
+			return
+		}
+		if err := Store.SetExchangeRates(rates); err != nil {
+			fmt.Println("Error importing exchange rates:", err)
+			exitCode = 1
+			return
+		}
+		fmt.Printf("Imported %d exchange rates\n", len(rates))
+	},
+}
+
+var ratesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List every exchange rate entered, newest first.",
+	Run: func(cmd *cobra.Command, args []string) {
+		rates, err := Store.GetExchangeRates()
+		if err != nil {
+			fmt.Println("Error getting exchange rates:", err)
+			return
+		}
+		if len(rates) == 0 {
+			fmt.Println("No exchange rates found, add some with jobtrack rates set CURRENCY RATE")
+			return
+		}
+		jobPrinter.PrintRatesTable(rates, Config.StaleRatesBefore(time.Now()))
+	},
+}
+
+// baseFlag returns the base currency of the rates being entered.
+func baseFlag(cmd *cobra.Command) string {
+	if base, _ := cmd.Flags().GetString("base"); base != "" {
+		return base
+	}
+	return Config.DefaultCurrency()
+}
+
+// conversionFlag reads the --in-currency flag, returning the currency salaries are to be
+// converted to and how to convert each currency, or an empty currency if none was given.
+func conversionFlag(cmd *cobra.Command) (string, map[string]*db.Conversion, bool) {
+	code, _ := cmd.Flags().GetString("in-currency")
+	if code == "" {
+		return "", nil, true
+	}
+	target, err := db.NormalizeCurrency(code)
+	if err != nil {
+		fmt.Println(err)
+		return "", nil, false
+	}
+	conversions, err := Store.GetConversions(target, time.Now())
+	if err != nil {
+		fmt.Println("Error getting exchange rates:", err)
+		return "", nil, false
+	}
+	return target, conversions, true
+}
+
+// convertSalaries converts the salaries of jobs to target, returning notes about those
+// that could not be converted or were converted with stale rates.
+func convertSalaries(jobs []*db.Job, target string, conversions map[string]*db.Conversion) []string {
+	missing := db.ConvertSalaries(jobs, conversions, target, Config.StaleRatesBefore(time.Now()))
+	var notes []string
+	for _, job := range jobs {
+		if job.ConvertedSalary != nil && job.ConvertedSalary.Stale {
+			notes = append(notes, fmt.Sprintf(
+				"%s Converted with exchange rates older than %d days, update them with jobtrack rates set",
+				jobPrinter.StaleMarker,
+				Config.StaleAfterDays(),
+			))
+			break
+		}
+	}
+	if missing == 1 {
+		notes = append(notes, fmt.Sprintf("1 salary has no exchange rate to %s and is not converted", target))
+	} else if missing > 1 {
+		notes = append(notes, fmt.Sprintf("%d salaries have no exchange rate to %s and are not converted", missing, target))
+	}
+	return notes
+}
+
+func init() {
+	rootCmd.AddCommand(ratesCmd)
+	ratesCmd.AddCommand(ratesSetCmd)
+	ratesCmd.AddCommand(ratesImportCmd)
+	ratesCmd.AddCommand(ratesListCmd)
+	ratesSetCmd.Flags().String("base", "", "The currency the rate is in (defaults to the defaults.currency setting)")
+	ratesSetCmd.Flags().String("date", "", "The day the rate takes effect, YYYY-MM-DD (defaults to today)")
+	ratesImportCmd.Flags().String("base", "", "The currency of rates without a base column (defaults to the defaults.currency setting)")
+}
@@ -1,15 +1,23 @@
 package cmd
 
 import (
-	"database/sql"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/valentino7504/jobtrack/internal/config"
+	"github.com/valentino7504/jobtrack/internal/db"
 	// "github.com/spf13/cobra/doc"
 )
 
-// A pointer to the SqliteDB handler
-var SqliteDB *sql.DB
+// The store jobs are kept in
+var Store db.Store
+
+// The configuration loaded on startup
+var Config = &config.Config{}
+
+// The profile whose store is open
+var Profile = db.DefaultProfile
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
@@ -18,11 +26,16 @@ var rootCmd = &cobra.Command{
 	Version: "1.0.0",
 }
 
-func Execute() {
-	err := rootCmd.Execute()
-	if err != nil {
-		os.Exit(1)
+// exitCode is the status jobtrack exits with, commands set it to report failure
+// without skipping the cleanup done by main.
+var exitCode = 0
+
+// Execute runs the command given on the command line and returns the status to exit with.
+func Execute() int {
+	if err := rootCmd.Execute(); err != nil {
+		return 1
 	}
+	return exitCode
 }
 
 func init() {
@@ -35,11 +48,89 @@ func init() {
 	// cobra.CheckErr(err)
 	// defer file.Close()
 	// cobra.CheckErr(doc.GenMan(updateCmd, header, file))
+	rootCmd.PersistentFlags().String(
+		"config",
+		"",
+		"Read the configuration from this file instead of the default one, also read from JOBTRACK_CONFIG",
+	)
+	rootCmd.PersistentFlags().String(
+		"profile",
+		"",
+		"Use this profile instead of the active one, also read from JOBTRACK_PROFILE",
+	)
+	rootCmd.PersistentFlags().String(
+		"database-url",
+		"",
+		"PostgreSQL database to use instead of the configured storage, also read from JOBTRACK_DATABASE_URL",
+	)
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 	// rootCmd.CompletionOptions.HiddenDefaultCmd = true
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
 }
 
-func SetDB(dbHandle *sql.DB) {
-	SqliteDB = dbHandle
+// DatabaseURL returns the PostgreSQL database given with --database-url or the
+// JOBTRACK_DATABASE_URL environment variable.
+func DatabaseURL() string {
+	return startupFlag("database-url", "JOBTRACK_DATABASE_URL")
+}
+
+// ProfileName returns the profile given with --profile or the JOBTRACK_PROFILE
+// environment variable, or else the one selected with jobtrack profile use.
+func ProfileName() string {
+	if name := startupFlag("profile", "JOBTRACK_PROFILE"); name != "" {
+		return name
+	}
+	return db.ActiveProfile()
+}
+
+// ConfigPath returns the configuration file given with --config or the JOBTRACK_CONFIG
+// environment variable.
+func ConfigPath() string {
+	return startupFlag("config", "JOBTRACK_CONFIG")
+}
+
+// startupFlag returns the value of a flag needed before the command line is parsed, such
+// as to open the store, by looking it up in the arguments directly. The environment
+// variable is used when the flag is not given.
+func startupFlag(name string, env string) string {
+	args := os.Args[1:]
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if value, ok := strings.CutPrefix(arg, "--"+name+"="); ok {
+			return value
+		}
+		if arg == "--"+name && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return os.Getenv(env)
+}
+
+func SetStore(store db.Store) {
+	Store = store
+}
+
+func SetConfig(cfg *config.Config) {
+	Config = cfg
+}
+
+func SetProfile(profile string) {
+	Profile = profile
+}
+
+// UsesStore reports whether the command being run needs the store. The config and
+// profile commands run without it, so they work while it cannot be opened.
+func UsesStore() bool {
+	c, _, err := rootCmd.Find(os.Args[1:])
+	if err != nil {
+		return true
+	}
+	for ; c != nil; c = c.Parent() {
+		if c == configCmd || c == profileCmd {
+			return false
+		}
+	}
+	return true
 }
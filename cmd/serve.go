@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/valentino7504/jobtrack/internal/api"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the job database over a local JSON REST API.",
+	Long: `Start an HTTP server exposing job applications as a JSON REST API.
+
+The API supports listing jobs with the same filters as jobtrack list, and creating, viewing,
+updating and deleting jobs. Invalid requests are answered with a 400 status and a JSON body
+listing the invalid fields. The OpenAPI description of the API is served at /openapi.json.
+
+By default the server only listens on localhost. When --token or the JOBTRACK_API_TOKEN
+environment variable is set, requests must send it as a bearer token.
+
+Examples:
+  jobtrack serve
+  jobtrack serve --addr 127.0.0.1:9000 --token s3cret
+  curl http://127.0.0.1:8080/jobs?status=Interview&sort=applied:desc
+  curl -X POST -d '{"company":"Google","position":"SRE"}' http://127.0.0.1:8080/jobs
+  curl -X PATCH -d '{"status":"Interview"}' http://127.0.0.1:8080/jobs/3
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		addr, _ := cmd.Flags().GetString("addr")
+		token, _ := cmd.Flags().GetString("token")
+		if token == "" {
+			token = os.Getenv("JOBTRACK_API_TOKEN")
+		}
+		fmt.Println("Serving the jobtrack API on", "http://"+addr)
+		if token == "" {
+			fmt.Println("No token set, requests are not authenticated")
+		}
+		runServer(addr, api.NewHandler(Store, token))
+	},
+}
+
+// runServer serves handler on addr until the server fails or is interrupted, then shuts
+// it down gracefully.
+func runServer(addr string, handler http.Handler) {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       15 * time.Second,
+		WriteTimeout:      15 * time.Second,
+		IdleTimeout:       time.Minute,
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.ListenAndServe()
+	}()
+	select {
+	case err := <-errs:
+		if !errors.Is(err, http.ErrServerClosed) {
+			fmt.Println("Error serving:", err)
+		}
+	case <-ctx.Done():
+		fmt.Println("Shutting down")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			fmt.Println("Error shutting down:", err)
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().String("addr", "127.0.0.1:8080", "The address to listen on")
+	serveCmd.Flags().String("token", "", "Require this bearer token on every request (default $JOBTRACK_API_TOKEN)")
+}
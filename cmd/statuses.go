@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/valentino7504/jobtrack/internal/db"
+)
+
+// resolveStatus returns a status spelled as configured in the pipeline. When the status
+// is not part of the pipeline it prints the valid statuses and returns false.
+func resolveStatus(name string) (db.JobStatus, bool) {
+	pipeline := db.ActivePipeline()
+	status, ok := pipeline.Lookup(name)
+	if !ok {
+		fmt.Println("Specified status is not valid")
+		fmt.Println("Valid statuses are:", db.JoinStatuses(pipeline.Statuses()))
+	}
+	return status, ok
+}
+
+// completeStatuses completes a status flag with the statuses of the configured pipeline.
+func completeStatuses(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var statuses []string
+	for _, status := range db.ActivePipeline().Statuses() {
+		statuses = append(statuses, string(status))
+	}
+	return statuses, cobra.ShellCompDirectiveNoFileComp
+}
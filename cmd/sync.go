@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/valentino7504/jobtrack/internal/jobPrinter"
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Exchange changes with your other devices through a shared directory.",
+	Long: `Keep job applications in step between machines through a directory they share, such as
+a mounted drive or a Syncthing or Dropbox folder.
+
+Each device appends the changes made on it since its last sync to a change log of its own
+in the directory, and reads the change logs of the other devices. Run sync on each machine
+whenever you like, changes made on one arrive on the others the next time they sync.
+
+Changes are merged field by field, so a status changed on your laptop and a salary changed
+on your desktop both survive. When the same field was changed on both, the later change wins,
+going by the time the job was updated, so keep your clocks right. Deleted jobs are logged
+too, and a deletion always wins: a job deleted on one device is deleted everywhere, even if
+it was changed elsewhere in the meantime. Conflicts sync could not settle, such as a field
+changed on two devices at the same second, or a status missing from this device's pipeline,
+are listed so you can settle them yourself.
+
+Jobs are matched across devices by their UUID. Notes, contacts and interviews are not
+synced.
+
+Examples:
+  jobtrack sync --dir ~/Dropbox/jobtrack        # Sync through a Dropbox folder
+  jobtrack sync --dir /mnt/usb/jobtrack         # Sync through a removable drive
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		dir, _ := cmd.Flags().GetString("dir")
+		if dir == "" {
+			fmt.Println("Specify the directory to sync through with --dir")
+			return
+		}
+		result, err := Store.Sync(dir)
+		if err != nil {
+			fmt.Println("Error syncing:", err)
+			return
+		}
+		fmt.Printf(
+			"Synced with %s as %s: %d changes sent, %d received from %d other devices.\n",
+			dir,
+			result.Device,
+			result.Sent,
+			result.Received,
+			result.Devices,
+		)
+		fmt.Printf("%d jobs added, %d updated, %d deleted.\n", result.Added, result.Updated, result.Deleted)
+		if len(result.Conflicts) > 0 {
+			fmt.Printf("\n%d conflicts could not be settled:\n", len(result.Conflicts))
+			jobPrinter.PrintSyncConflicts(result.Conflicts)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+	syncCmd.Flags().String("dir", "", "The directory shared with your other devices")
+	syncCmd.MarkFlagDirname("dir")
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/valentino7504/jobtrack/internal/db"
+	"github.com/valentino7504/jobtrack/internal/jobPrinter"
+)
+
+var tagsCmd = &cobra.Command{
+	Use:   "tags",
+	Short: "List the tags in use and how many jobs have each.",
+	Long: `List every tag given to a job application along with how many jobs have it, the most
+used tags first.
+
+Tags are free-form labels such as referral, dream or visa-sponsor. They are given to jobs with
+--tag on create and update, taken away with --remove-tag on update, and used to filter jobs
+with --tag on list. Tags are lowercase and made of letters, digits, -, _ and .; a tag no job
+has any more is forgotten.
+
+Examples:
+  jobtrack tags                                     # Show every tag with its job count
+  jobtrack create --company "Acme" --position "SRE" --tag referral,backend
+  jobtrack update --id 3 --tag dream --remove-tag backend
+  jobtrack list --tag referral,backend              # Jobs with both tags
+  jobtrack list --tag referral,backend --any-tag    # Jobs with either tag
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		tags, err := Store.GetTagCounts()
+		if err != nil {
+			fmt.Println("Error getting tags:", err)
+			return
+		}
+		if len(tags) == 0 {
+			fmt.Println("No tags found, add some with jobtrack update --id N --tag NAME")
+			return
+		}
+		jobPrinter.PrintTagsTable(tags)
+	},
+}
+
+// tagsFlag reads a comma separated tags flag, printing the problem if a tag is invalid.
+func tagsFlag(cmd *cobra.Command, name string) ([]string, bool) {
+	values, _ := cmd.Flags().GetStringSlice(name)
+	tags, err := db.NormalizeTags(values)
+	if err != nil {
+		fmt.Println(err)
+		return nil, false
+	}
+	return tags, true
+}
+
+// completeTags completes the last tag of a comma separated tags flag with the tags in use.
+func completeTags(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if Store == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	tags, err := Store.GetTagCounts()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	prefix := ""
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		prefix = toComplete[:i+1]
+	}
+	given := strings.Split(prefix, ",")
+	var completions []string
+	for _, tag := range tags {
+		if slices.Contains(given, tag.Name) {
+			continue
+		}
+		description := fmt.Sprintf("%d jobs", tag.Jobs)
+		if tag.Jobs == 1 {
+			description = "1 job"
+		}
+		completions = append(completions, prefix+tag.Name+"\t"+description)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
+
+func init() {
+	rootCmd.AddCommand(tagsCmd)
+}
@@ -1,7 +1,10 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/valentino7504/jobtrack/internal/db"
@@ -22,27 +25,51 @@ func initializeUpdate(cmd *cobra.Command) *db.UpdatedJobParams {
 	salaryRange, _ := cmd.Flags().GetString("salary-range")
 	jobPostingURL, _ := cmd.Flags().GetString("job-posting-url")
 	applied, _ := cmd.Flags().GetString("applied")
+	note, _ := cmd.Flags().GetString("note")
+	force, _ := cmd.Flags().GetBool("force")
+	followUp, _ := cmd.Flags().GetString("follow-up")
+	addTags, _ := cmd.Flags().GetStringSlice("tag")
+	removeTags, _ := cmd.Flags().GetStringSlice("remove-tag")
+	fields, ok := fieldsFlag(cmd, "set")
+	if !ok {
+		return nil
+	}
 	appliedAt, err := db.ParseDateTime(applied, true)
 	if err != nil && applied != "" {
 		fmt.Println(err)
 		return nil
 	}
-	if !db.IsValidStatus(db.JobStatus(status)) && status != "" {
-		fmt.Println("Specified status is not valid")
-		fmt.Println(
-			"Valid statuses are: Applied, Interview, Offer, Accepted, \"Rejected Offer\" and Rejected",
-		)
-		return nil
+	var newStatus *db.JobStatus
+	if status != "" {
+		newStatus = (*db.JobStatus)(&status)
+	}
+	var followUpOn *time.Time
+	if followUp != "" && followUp != "none" {
+		followUpOn, err = db.ParseRelativeDate(followUp, time.Now())
+		if err != nil {
+			fmt.Println("Invalid follow-up date:", err)
+			return nil
+		}
 	}
-
 	updatedParams := db.UpdatedJobParams{
 		Company:       processParam(company),
 		Position:      processParam(position),
-		Status:        (*db.JobStatus)(processParam(status)),
+		Status:        newStatus,
 		Location:      processParam(location),
 		SalaryRange:   processParam(salaryRange),
 		JobPostingURL: processParam(jobPostingURL),
 		AppliedAt:     appliedAt,
+		FollowUpOn:    followUpOn,
+		ClearFollowUp: followUp == "none",
+		Note:          processParam(note),
+		Force:         force,
+		AddTags:       addTags,
+		RemoveTags:    removeTags,
+		SetFields:     fields,
+	}
+	if err := db.ValidateUpdate(&updatedParams); err != nil {
+		printValidationError(err)
+		return nil
 	}
 	return &updatedParams
 }
@@ -54,14 +81,33 @@ var updateCmd = &cobra.Command{
 	Long: `Update a job application in the database using its unique ID.
 
 You can update details such as company name, position, status, location, salary range, job posting URL,
-or the date you applied. Only the fields you specify will be changed, leaving other details untouched.
+or the date you applied, add or remove tags and set custom fields. Status changes are recorded in the
+job's history, optionally with a note. Only the fields you specify will be changed, leaving other details
+untouched.
+
+Custom fields hold anything else you want to keep on a job, such as a referral code or the team name.
+Set them with --set name=value, and remove them with an empty value, --set name=.
+
+Status changes must follow the transitions allowed by the configured pipeline. Use --force
+to move a job to any status regardless. Changing the status clears the follow-up date unless
+a new one is given with --follow-up.
 
 Examples:
   jobtrack update --id 3 --status "Interview"
+  jobtrack update --id 3 --status "Offer" --note "Verbal offer from the hiring manager"
+  jobtrack update --id 3 --status "Applied" --force
+  jobtrack update --id 4 --follow-up +5d
+  jobtrack update --id 4 --follow-up none
   jobtrack update --id 5 --company "Google" --position "Software Engineer"
-  jobtrack update --id 2 --salary-range "$80,000 - $100,000"`,
+  jobtrack update --id 2 --salary-range "$80,000 - $100,000"
+  jobtrack update --id 2 --tag dream,visa-sponsor --remove-tag backend
+  jobtrack update --id 2 --set team=Payments --set stack="Go, Postgres"
+  jobtrack update --id 2 --set team=`,
 	Run: func(cmd *cobra.Command, args []string) {
-		jobID, _ := cmd.Flags().GetInt("id")
+		jobID, ok := jobIDFlag(cmd, "id")
+		if !ok {
+			return
+		}
 		if jobID == -1 {
 			fmt.Println("Please provide a valid job id")
 			return
@@ -70,25 +116,31 @@ Examples:
 		if updatedParams == nil {
 			return
 		}
-		job, err := db.UpdateJob(SqliteDB, jobID, *updatedParams)
+		job, err := Store.UpdateJob(jobID, *updatedParams)
+		var transitionErr *db.TransitionError
+		if errors.As(err, &transitionErr) {
+			fmt.Println("Error updating job:", err)
+			fmt.Println("Use --force to change the status anyway")
+			return
+		}
 		if err != nil {
 			fmt.Println("Error updating job:", err)
 			return
 		}
 		if job == nil {
+			fmt.Println("No job found with ID:", jobID)
 			return
 		}
 		fmt.Println("Job with id:", job.ID, "has been updated")
+		if updatedParams.Status != nil && strings.EqualFold(string(job.Status), string(db.OFFER)) {
+			fmt.Printf("Record the offer with: jobtrack offer add --job-id %d --base AMOUNT\n", job.ID)
+		}
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(updateCmd)
-	updateCmd.Flags().Int(
-		"id",
-		-1,
-		"Specify the ID of the job to be updated",
-	)
+	addJobIDFlag(updateCmd, "id", "Specify the job to be updated")
 	updateCmd.Flags().String(
 		"company",
 		"",
@@ -112,4 +164,18 @@ func init() {
 		"",
 		"The date of the application formatted YYYY-MM-DD",
 	)
+	updateCmd.Flags().String("note", "", "A note to record in the history alongside the status change")
+	updateCmd.Flags().Bool("force", false, "Change the status even if the pipeline does not allow the transition")
+	updateCmd.Flags().String(
+		"follow-up",
+		"",
+		"When to follow up, formatted YYYY-MM-DD or relative like +7d, or none to clear it",
+	)
+	updateCmd.Flags().StringSlice("tag", nil, "Tags to give the job (comma separated)")
+	updateCmd.Flags().StringSlice("remove-tag", nil, "Tags to take away from the job (comma separated)")
+	updateCmd.RegisterFlagCompletionFunc("status", completeStatuses)
+	updateCmd.RegisterFlagCompletionFunc("tag", completeTags)
+	updateCmd.RegisterFlagCompletionFunc("remove-tag", completeTags)
+	updateCmd.Flags().StringArray("set", nil, "Set a custom field, formatted name=value, an empty value removes it (repeatable)")
+	updateCmd.RegisterFlagCompletionFunc("set", completeFields)
 }
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/valentino7504/jobtrack/internal/web"
+)
+
+var webCmd = &cobra.Command{
+	Use:   "web",
+	Short: "Open a dashboard of your job applications in the browser.",
+	Long: `Start a local web dashboard for browsing and editing job applications.
+
+The dashboard is built into jobtrack and needs no internet connection. It offers:
+  - a table of jobs that can be filtered and sorted like jobtrack list
+  - a board with a column per status, where dragging a job to another column changes its status
+  - a page per job with its details, notes and status history
+  - a form for adding jobs, validated like jobtrack create
+
+Status changes follow the configured pipeline, the dashboard asks before forcing a change the
+pipeline does not allow. The dashboard also serves the REST API of jobtrack serve under /api.
+
+Examples:
+  jobtrack web
+  jobtrack web --addr 127.0.0.1:9000
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		addr, _ := cmd.Flags().GetString("addr")
+		fmt.Println("Dashboard running at", "http://"+addr)
+		runServer(addr, web.NewHandler(Store))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(webCmd)
+	webCmd.Flags().String("addr", "127.0.0.1:8080", "The address to listen on")
+}
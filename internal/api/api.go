@@ -0,0 +1,154 @@
+// Package api serves the job database over a JSON REST API.
+package api
+
+import (
+	"crypto/subtle"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/valentino7504/jobtrack/internal/db"
+)
+
+//go:embed openapi.json
+var openAPISpec []byte
+
+// maxBodySize limits the size of request bodies.
+const maxBodySize = 1 << 20
+
+// server holds the state shared by the API handlers.
+type server struct {
+	store db.Store
+}
+
+// errorResponse is the body of every error response. Fields lists the invalid fields of
+// a request that failed validation.
+type errorResponse struct {
+	Error  string          `json:"error"`
+	Fields []db.FieldError `json:"fields,omitempty"`
+}
+
+// NewHandler returns the handler of the REST API. When token is not empty every request
+// except those for the OpenAPI document must carry it as a bearer token.
+func NewHandler(store db.Store, token string) http.Handler {
+	s := &server{store: store}
+	jobs := http.NewServeMux()
+	jobs.HandleFunc("GET /jobs", s.listJobs)
+	jobs.HandleFunc("POST /jobs", s.createJob)
+	jobs.HandleFunc("GET /jobs/{id}", s.getJob)
+	jobs.HandleFunc("PATCH /jobs/{id}", s.updateJob)
+	jobs.HandleFunc("DELETE /jobs/{id}", s.deleteJob)
+	jobs.HandleFunc("GET /jobs/{id}/history", s.getHistory)
+	jobs.HandleFunc("GET /statuses", s.listStatuses)
+	jobs.HandleFunc("GET /tags", s.listTags)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(openAPISpec)
+	})
+	mux.Handle("/", requireToken(token, rejectCrossOrigin(jobs)))
+	return logRequests(mux)
+}
+
+// rejectCrossOrigin stops web pages on other sites from changing jobs through the
+// browser of someone running the server, as the API does not require a token.
+func rejectCrossOrigin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		site := r.Header.Get("Sec-Fetch-Site")
+		crossSite := site != "" && site != "same-origin" && site != "none"
+		if origin := r.Header.Get("Origin"); site == "" && origin != "" {
+			if u, err := url.Parse(origin); err != nil || u.Host != r.Host {
+				crossSite = true
+			}
+		}
+		if crossSite {
+			writeError(w, http.StatusForbidden, "Cross-origin requests are not allowed")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireToken rejects requests that do not carry the bearer token. An empty token
+// disables authentication.
+func requireToken(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="jobtrack"`)
+			writeError(w, http.StatusUnauthorized, "Missing or invalid bearer token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// statusRecorder remembers the status code written by a handler for logging.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		log.Printf("%s %s %d", r.Method, r.URL.RequestURI(), recorder.status)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Println("Error encoding response:", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Error: message})
+}
+
+// writeFailure writes the response for an error returned by the db package, choosing the
+// status code from its type.
+func writeFailure(w http.ResponseWriter, err error) {
+	var validationErr db.ValidationError
+	var transitionErr *db.TransitionError
+	switch {
+	case errors.As(err, &validationErr):
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "Validation failed", Fields: validationErr})
+	case errors.As(err, &transitionErr):
+		writeError(w, http.StatusConflict, transitionErr.Error())
+	default:
+		log.Println("Error handling request:", err)
+		writeError(w, http.StatusInternalServerError, "Internal server error")
+	}
+}
+
+// decodeBody decodes a JSON request body into v, rejecting unknown fields.
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid JSON body: "+err.Error())
+		return false
+	}
+	return true
+}
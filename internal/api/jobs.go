@@ -0,0 +1,396 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/valentino7504/jobtrack/internal/db"
+)
+
+// jobRequest is the body of a request creating a job. Dates are formatted YYYY-MM-DD,
+// follow_up_on also accepts relative dates such as +7d.
+type jobRequest struct {
+	Company       string            `json:"company"`
+	Position      string            `json:"position"`
+	Status        string            `json:"status"`
+	Location      string            `json:"location"`
+	SalaryRange   string            `json:"salary_range"`
+	JobPostingURL string            `json:"job_posting_url"`
+	AppliedAt     string            `json:"applied_at"`
+	FollowUpOn    string            `json:"follow_up_on"`
+	Tags          []string          `json:"tags"`
+	Fields        map[string]string `json:"fields"`
+}
+
+// jobUpdateRequest is the body of a request updating a job. Fields left out are not
+// changed, location, salary_range and job_posting_url set to null are cleared, a
+// follow_up_on of "none" clears the follow-up date and custom fields set to "" are removed.
+type jobUpdateRequest struct {
+	Company       *string           `json:"company"`
+	Position      *string           `json:"position"`
+	Status        *string           `json:"status"`
+	Location      nullableString    `json:"location"`
+	SalaryRange   nullableString    `json:"salary_range"`
+	JobPostingURL nullableString    `json:"job_posting_url"`
+	AppliedAt     *string           `json:"applied_at"`
+	FollowUpOn    *string           `json:"follow_up_on"`
+	Note          *string           `json:"note"`
+	Force         bool              `json:"force"`
+	AddTags       []string          `json:"add_tags"`
+	RemoveTags    []string          `json:"remove_tags"`
+	Fields        map[string]string `json:"fields"`
+}
+
+// nullableString is a field of an update request that can be left out, given a value,
+// or set to null to clear it.
+type nullableString struct {
+	Value *string
+	Null  bool
+}
+
+func (n *nullableString) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		n.Null = true
+		return nil
+	}
+	return json.Unmarshal(data, &n.Value)
+}
+
+// optionalString converts an empty string to null.
+func optionalString(s string) db.NullString {
+	var n db.NullString
+	if s != "" {
+		n.String, n.Valid = s, true
+	}
+	return n
+}
+
+// jobID resolves the job ID or UUID prefix in the request path, writing an error response
+// if it does not identify exactly one job.
+func (s *server) jobID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := s.store.ResolveJobID(r.PathValue("id"))
+	switch {
+	case errors.Is(err, db.ErrJobNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, db.ErrAmbiguousJobID):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case err != nil:
+		writeFailure(w, err)
+	default:
+		return id, true
+	}
+	return 0, false
+}
+
+// parseDate parses a date formatted YYYY-MM-DD, recording an error for the field if it
+// is invalid.
+func parseDate(errs *db.ValidationError, field string, value string) *time.Time {
+	t, err := db.ParseDateTime(value, true)
+	if err != nil {
+		*errs = append(*errs, db.FieldError{Field: field, Message: "Date is not formatted YYYY-MM-DD"})
+	}
+	return t
+}
+
+func parseFollowUp(errs *db.ValidationError, value string) *time.Time {
+	t, err := db.ParseRelativeDate(value, time.Now())
+	if err != nil {
+		*errs = append(*errs, db.FieldError{Field: "follow_up_on", Message: err.Error()})
+	}
+	return t
+}
+
+// jobQuery builds a job query from the query string, accepting the same filters as
+// jobtrack list.
+func jobQuery(r *http.Request) (*db.JobQuery, error) {
+	var query db.JobQuery
+	var errs db.ValidationError
+	values := r.URL.Query()
+	for _, value := range values["status"] {
+		for _, name := range strings.Split(value, ",") {
+			status, ok := db.ActivePipeline().Lookup(strings.TrimSpace(name))
+			if !ok {
+				errs = append(errs, db.FieldError{
+					Field:   "status",
+					Message: "Status " + strconv.Quote(name) + " is not valid, valid statuses are: " + db.JoinStatuses(db.ActivePipeline().Statuses()),
+				})
+				continue
+			}
+			query.Statuses = append(query.Statuses, status)
+		}
+	}
+	if after := values.Get("after"); after != "" {
+		query.AppliedAfter = parseDate(&errs, "after", after)
+	}
+	if before := values.Get("before"); before != "" {
+		query.AppliedBefore = parseDate(&errs, "before", before)
+	}
+	query.Company = values.Get("company")
+	query.Position = values.Get("position")
+	query.Location = values.Get("location")
+	query.Contact = values.Get("contact")
+	if remote, _ := strconv.ParseBool(values.Get("remote")); remote {
+		query.WorkMode = db.REMOTE
+	}
+	if value := values.Get("work_mode"); value != "" {
+		mode, err := db.ParseWorkMode(value)
+		if err != nil {
+			errs = append(errs, db.FieldError{Field: "work_mode", Message: err.Error()})
+		}
+		query.WorkMode = mode
+	}
+	if value := values.Get("country"); value != "" {
+		country, err := db.NormalizeCountry(value)
+		if err != nil {
+			errs = append(errs, db.FieldError{Field: "country", Message: err.Error()})
+		}
+		query.Country = country
+	}
+	if value := values.Get("city"); value != "" {
+		query.City = db.NormalizeCity(value)
+	}
+	for _, value := range values["tag"] {
+		tags, err := db.ParseTags(value)
+		if err != nil {
+			errs = append(errs, db.FieldError{Field: "tag", Message: err.Error()})
+			continue
+		}
+		query.Tags = append(query.Tags, tags...)
+	}
+	query.AnyTag, _ = strconv.ParseBool(values.Get("any_tag"))
+	for _, value := range values["where"] {
+		name, fieldValue, err := db.ParseFieldAssignment(value)
+		if err != nil {
+			errs = append(errs, db.FieldError{Field: "where", Message: err.Error()})
+			continue
+		}
+		query.Fields = append(query.Fields, db.FieldFilter{Name: name, Value: fieldValue})
+	}
+	if value := values.Get("min_salary"); value != "" {
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil || n < 0 {
+			errs = append(errs, db.FieldError{Field: "min_salary", Message: "Must be a non-negative number"})
+		}
+		query.MinSalary = n
+	}
+	if value := values.Get("currency"); value != "" {
+		currency, err := db.NormalizeCurrency(value)
+		if err != nil {
+			errs = append(errs, db.FieldError{Field: "currency", Message: err.Error()})
+		}
+		query.Currency = currency
+	}
+
+	sort := values.Get("sort")
+	if latest, _ := strconv.ParseBool(values.Get("latest")); latest {
+		if sort != "" {
+			errs = append(errs, db.FieldError{Field: "latest", Message: "Use either latest or sort, not both"})
+		}
+		sort = "applied:desc"
+	}
+	sortFields, err := db.ParseSort(sort)
+	if err != nil {
+		errs = append(errs, db.FieldError{Field: "sort", Message: err.Error()})
+	}
+	query.Sort = sortFields
+	for field, dest := range map[string]*int{"limit": &query.Limit, "offset": &query.Offset} {
+		value := values.Get(field)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			errs = append(errs, db.FieldError{Field: field, Message: "Must be a non-negative integer"})
+			continue
+		}
+		*dest = n
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return &query, nil
+}
+
+func (s *server) listJobs(w http.ResponseWriter, r *http.Request) {
+	query, err := jobQuery(r)
+	if err != nil {
+		writeFailure(w, err)
+		return
+	}
+	jobs, err := s.store.QueryJobs(*query)
+	if err != nil {
+		writeFailure(w, err)
+		return
+	}
+	if jobs == nil {
+		jobs = []*db.Job{}
+	}
+	writeJSON(w, http.StatusOK, jobs)
+}
+
+func (s *server) getJob(w http.ResponseWriter, r *http.Request) {
+	id, ok := s.jobID(w, r)
+	if !ok {
+		return
+	}
+	job, err := s.store.GetJobByID(id)
+	if err != nil {
+		writeFailure(w, err)
+		return
+	}
+	if job == nil {
+		writeError(w, http.StatusNotFound, "No job found with ID: "+strconv.Itoa(id))
+		return
+	}
+	if job.Notes, err = s.store.GetNotes(id); err != nil {
+		writeFailure(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+func (s *server) createJob(w http.ResponseWriter, r *http.Request) {
+	var req jobRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	var errs db.ValidationError
+	job := db.Job{
+		Company:       req.Company,
+		Position:      req.Position,
+		Status:        db.JobStatus(req.Status),
+		Location:      optionalString(req.Location),
+		SalaryRange:   optionalString(req.SalaryRange),
+		JobPostingURL: optionalString(req.JobPostingURL),
+		Tags:          req.Tags,
+		Fields:        req.Fields,
+	}
+	if req.AppliedAt != "" {
+		job.AppliedAt = parseDate(&errs, "applied_at", req.AppliedAt)
+	}
+	if req.FollowUpOn != "" {
+		job.FollowUpOn = parseFollowUp(&errs, req.FollowUpOn)
+	}
+	if err := db.ValidateJob(&job); err != nil {
+		errs = append(errs, err.(db.ValidationError)...)
+	}
+	if len(errs) > 0 {
+		writeFailure(w, errs)
+		return
+	}
+	if err := s.store.AddJob(&job); err != nil {
+		writeFailure(w, err)
+		return
+	}
+	created, err := s.store.GetJobByID(job.ID)
+	if err != nil {
+		writeFailure(w, err)
+		return
+	}
+	w.Header().Set("Location", "/jobs/"+strconv.Itoa(job.ID))
+	writeJSON(w, http.StatusCreated, created)
+}
+
+func (s *server) updateJob(w http.ResponseWriter, r *http.Request) {
+	id, ok := s.jobID(w, r)
+	if !ok {
+		return
+	}
+	var req jobUpdateRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	var errs db.ValidationError
+	updates := db.UpdatedJobParams{
+		Company:            req.Company,
+		Position:           req.Position,
+		Location:           req.Location.Value,
+		SalaryRange:        req.SalaryRange.Value,
+		JobPostingURL:      req.JobPostingURL.Value,
+		ClearLocation:      req.Location.Null,
+		ClearSalaryRange:   req.SalaryRange.Null,
+		ClearJobPostingURL: req.JobPostingURL.Null,
+		Note:               req.Note,
+		Force:              req.Force,
+		AddTags:            req.AddTags,
+		RemoveTags:         req.RemoveTags,
+		SetFields:          req.Fields,
+	}
+	if req.Status != nil {
+		status := db.JobStatus(*req.Status)
+		updates.Status = &status
+	}
+	if req.AppliedAt != nil {
+		updates.AppliedAt = parseDate(&errs, "applied_at", *req.AppliedAt)
+	}
+	if req.FollowUpOn != nil {
+		if *req.FollowUpOn == "none" || *req.FollowUpOn == "" {
+			updates.ClearFollowUp = true
+		} else {
+			updates.FollowUpOn = parseFollowUp(&errs, *req.FollowUpOn)
+		}
+	}
+	if err := db.ValidateUpdate(&updates); err != nil {
+		errs = append(errs, err.(db.ValidationError)...)
+	}
+	if len(errs) > 0 {
+		writeFailure(w, errs)
+		return
+	}
+	job, err := s.store.UpdateJob(id, updates)
+	if err != nil {
+		writeFailure(w, err)
+		return
+	}
+	if job == nil {
+		writeError(w, http.StatusNotFound, "No job found with ID: "+strconv.Itoa(id))
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+func (s *server) deleteJob(w http.ResponseWriter, r *http.Request) {
+	id, ok := s.jobID(w, r)
+	if !ok {
+		return
+	}
+	deleted, err := s.store.DeleteJobByID(id)
+	if err != nil {
+		writeFailure(w, err)
+		return
+	}
+	if !deleted {
+		writeError(w, http.StatusNotFound, "No job found with ID: "+strconv.Itoa(id))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) getHistory(w http.ResponseWriter, r *http.Request) {
+	id, ok := s.jobID(w, r)
+	if !ok {
+		return
+	}
+	job, err := s.store.GetJobByID(id)
+	if err != nil {
+		writeFailure(w, err)
+		return
+	}
+	if job == nil {
+		writeError(w, http.StatusNotFound, "No job found with ID: "+strconv.Itoa(id))
+		return
+	}
+	events, err := s.store.GetStatusHistory(id)
+	if err != nil {
+		writeFailure(w, err)
+		return
+	}
+	if events == nil {
+		events = []*db.StatusEvent{}
+	}
+	writeJSON(w, http.StatusOK, events)
+}
@@ -0,0 +1,27 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/valentino7504/jobtrack/internal/db"
+)
+
+// statusResponse describes a status of the pipeline and where jobs can move from it.
+type statusResponse struct {
+	Name  db.JobStatus   `json:"name"`
+	Final bool           `json:"final"`
+	Next  []db.JobStatus `json:"next"`
+}
+
+func (s *server) listStatuses(w http.ResponseWriter, r *http.Request) {
+	pipeline := db.ActivePipeline()
+	statuses := []statusResponse{}
+	for _, status := range pipeline.Statuses() {
+		next := pipeline.Next(status)
+		if next == nil {
+			next = []db.JobStatus{}
+		}
+		statuses = append(statuses, statusResponse{Name: status, Final: pipeline.IsFinal(status), Next: next})
+	}
+	writeJSON(w, http.StatusOK, statuses)
+}
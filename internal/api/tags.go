@@ -0,0 +1,19 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/valentino7504/jobtrack/internal/db"
+)
+
+func (s *server) listTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := s.store.GetTagCounts()
+	if err != nil {
+		writeFailure(w, err)
+		return
+	}
+	if tags == nil {
+		tags = []*db.TagCount{}
+	}
+	writeJSON(w, http.StatusOK, tags)
+}
@@ -0,0 +1,373 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/valentino7504/jobtrack/internal/db"
+	"github.com/valentino7504/jobtrack/internal/jobPrinter"
+)
+
+// Setting is a single value of the configuration file that can be read and changed
+// with jobtrack config. Key is the section and name of the value, such as list.sort.
+type Setting struct {
+	Key         string
+	Description string
+	// get returns the value, or "" when it is not set.
+	get func(c *Config) string
+	set func(c *Config, value string)
+	// fallback returns the value used when the setting is not set.
+	fallback func(c *Config) string
+	// list values are written to the file as an array of strings.
+	list bool
+	// number values are written to the file as integers.
+	number bool
+}
+
+// Env returns the environment variable overriding the setting, such as JOBTRACK_LIST_SORT.
+func (s Setting) Env() string {
+	return "JOBTRACK_" + strings.ToUpper(strings.ReplaceAll(s.Key, ".", "_"))
+}
+
+func fixed(value string) func(c *Config) string {
+	return func(c *Config) string { return value }
+}
+
+// Settings lists every value jobtrack config knows about. The pipeline and follow_up
+// sections hold tables and are edited in the file directly.
+var Settings = []Setting{
+	{
+		Key:         "storage.backend",
+		Description: "Where jobs are kept: sqlite, postgres or git",
+		get:         func(c *Config) string { return c.Storage.Backend },
+		set:         func(c *Config, value string) { c.Storage.Backend = value },
+		fallback:    fixed("sqlite"),
+	},
+	{
+		Key:         "storage.path",
+		Description: "The SQLite database file, or the repository of the git backend",
+		get:         func(c *Config) string { return c.Storage.Path },
+		set:         func(c *Config, value string) { c.Storage.Path = value },
+		fallback: func(c *Config) string {
+			if strings.ToLower(c.Storage.Backend) == "git" {
+				return filepath.Join(db.DataDir(), "git")
+			}
+			return db.DefaultDatabasePath()
+		},
+	},
+	{
+		Key:         "storage.url",
+		Description: "The database URL of the postgres backend",
+		get:         func(c *Config) string { return c.Storage.URL },
+		set:         func(c *Config, value string) { c.Storage.URL = value },
+		fallback:    fixed(""),
+	},
+	{
+		Key:         "defaults.status",
+		Description: "The status new jobs start in",
+		get:         func(c *Config) string { return c.Defaults.Status },
+		set:         func(c *Config, value string) { c.Defaults.Status = value },
+		fallback: func(c *Config) string {
+			if len(c.Pipeline.Statuses) > 0 {
+				return c.Pipeline.Statuses[0]
+			}
+			return string(db.DefaultPipeline().Initial())
+		},
+	},
+	{
+		Key:         "defaults.currency",
+		Description: "The currency of salaries entered without one, such as USD or EUR",
+		get:         func(c *Config) string { return c.Defaults.Currency },
+		set:         func(c *Config, value string) { c.Defaults.Currency = value },
+		fallback:    func(c *Config) string { return c.DefaultCurrency() },
+	},
+	{
+		Key:         "list.columns",
+		Description: "The columns of jobtrack list, see jobtrack list --help",
+		get:         func(c *Config) string { return strings.Join(c.List.Columns, ",") },
+		set:         func(c *Config, value string) { c.List.Columns = splitList(value) },
+		fallback:    fixed(strings.Join(jobPrinter.DefaultColumns, ",")),
+		list:        true,
+	},
+	{
+		Key:         "list.sort",
+		Description: "The sort order of jobtrack list, formatted like --sort",
+		get:         func(c *Config) string { return c.List.Sort },
+		set:         func(c *Config, value string) { c.List.Sort = value },
+		fallback:    fixed("applied"),
+	},
+	{
+		Key:         "display.date_format",
+		Description: "How dates are shown, written with YYYY, MM and DD",
+		get:         func(c *Config) string { return c.Display.DateFormat },
+		set:         func(c *Config, value string) { c.Display.DateFormat = value },
+		fallback:    fixed("YYYY-MM-DD"),
+	},
+	{
+		Key:         "display.color",
+		Description: "Whether to colour output: auto, always or never",
+		get:         func(c *Config) string { return c.Display.Color },
+		set:         func(c *Config, value string) { c.Display.Color = value },
+		fallback:    fixed("auto"),
+	},
+	{
+		Key:         "rates.stale_after",
+		Description: "The number of days after which exchange rates are flagged as stale",
+		get: func(c *Config) string {
+			if c.Rates.StaleAfter == 0 {
+				return ""
+			}
+			return strconv.Itoa(c.Rates.StaleAfter)
+		},
+		set: func(c *Config, value string) {
+			days, err := strconv.Atoi(value)
+			if err != nil && value != "" {
+				// invalid values are reported by Validate
+				days = -1
+			}
+			c.Rates.StaleAfter = days
+		},
+		fallback: fixed(strconv.Itoa(defaultStaleAfter)),
+		number:   true,
+	},
+}
+
+// ErrUnknownSetting is returned for keys missing from Settings.
+var ErrUnknownSetting = errors.New("Unknown setting")
+
+// LookupSetting finds a setting by its key.
+func LookupSetting(key string) (Setting, error) {
+	key = strings.ToLower(strings.TrimSpace(key))
+	for _, setting := range Settings {
+		if setting.Key == key {
+			return setting, nil
+		}
+	}
+	return Setting{}, fmt.Errorf("%w %q, run jobtrack config list to see every setting", ErrUnknownSetting, key)
+}
+
+// Value returns the current value of a setting and where it comes from: "file", "env"
+// or "default" when it is not set.
+func (c *Config) Value(setting Setting) (string, string) {
+	if source, ok := c.sources[setting.Key]; ok {
+		return setting.get(c), source
+	}
+	return setting.fallback(c), "default"
+}
+
+// SetValue changes a setting in the configuration file, creating the file if needed.
+// An empty value removes the setting so its default applies again. The new value is
+// checked first, and the file is left unchanged if it is invalid. Only the lines of the
+// setting are changed, the rest of the file and its comments are kept.
+func SetValue(key string, value string) error {
+	setting, err := LookupSetting(key)
+	if err != nil {
+		return err
+	}
+	// the environment is left out, an invalid JOBTRACK_* variable is not in the file. A
+	// file that cannot be decoded is still edited, the value is checked on its own then.
+	cfg, loadErr := loadFile()
+	if loadErr != nil {
+		cfg = &Config{sources: make(map[string]string)}
+	}
+	setting.set(cfg, value)
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(Path())
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("Error reading config file %s: %w", Path(), err)
+	}
+	section, name, _ := strings.Cut(setting.Key, ".")
+	var newValue any
+	switch {
+	case value == "":
+	case setting.list:
+		newValue = splitList(value)
+	case setting.number:
+		newValue, _ = strconv.Atoi(value)
+	default:
+		newValue = value
+	}
+	edited, err := editKey(data, section, name, newValue)
+	if err != nil {
+		return err
+	}
+	if loadErr == nil && !hasValue(edited, section, name, newValue) {
+		// the setting is written in a way editKey does not follow, such as an inline
+		// table, so the whole file is written again
+		if edited, err = rewriteKey(data, section, name, newValue); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(Path()), os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(Path(), edited, 0o644)
+}
+
+// keyLine returns name = value as TOML, ending with a newline.
+func keyLine(name string, value any) (string, error) {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(map[string]any{name: value}); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// editKey sets a key of a TOML document by editing its lines, adding the key, and its
+// section, when missing. A nil value removes the key.
+func editKey(data []byte, section string, name string, value any) ([]byte, error) {
+	var line string
+	if value != nil {
+		var err error
+		if line, err = keyLine(name, value); err != nil {
+			return nil, err
+		}
+	}
+	key := regexp.MustCompile(`^\s*"?` + regexp.QuoteMeta(name) + `"?\s*=`)
+	lines := strings.SplitAfter(string(data), "\n")
+	current, header, last, start, end := "", -1, -1, -1, -1
+	for i := 0; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(trimmed, "[") {
+			current = ""
+			if closing := strings.Index(trimmed, "]"); !strings.HasPrefix(trimmed, "[[") && closing > 0 {
+				current = strings.Trim(strings.TrimSpace(trimmed[1:closing]), `"`)
+			}
+			if current == section {
+				header, last = i, i
+			}
+			continue
+		}
+		if current != section || trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		last = i
+		if start < 0 && key.MatchString(lines[i]) {
+			// arrays may go on over several lines
+			start, end = i, i+1
+			depth := bracketDepth(lines[i][strings.Index(lines[i], "=")+1:])
+			for ; depth > 0 && end < len(lines); end++ {
+				depth += bracketDepth(lines[end])
+			}
+			last, i = end-1, end-1
+		}
+	}
+	var edited []string
+	switch {
+	case start >= 0:
+		edited = append(append(append(edited, lines[:start]...), line), lines[end:]...)
+	case value == nil:
+		edited = lines
+	case header >= 0:
+		if !strings.HasSuffix(lines[last], "\n") {
+			lines[last] += "\n"
+		}
+		edited = append(append(append(edited, lines[:last+1]...), line), lines[last+1:]...)
+	default:
+		text := string(data)
+		if text != "" {
+			text = strings.TrimRight(text, "\n") + "\n\n"
+		}
+		return []byte(text + "[" + section + "]\n" + line), nil
+	}
+	return []byte(strings.Join(edited, "")), nil
+}
+
+// bracketDepth returns how many more arrays a line of TOML opens than it closes, ignoring
+// strings and comments.
+func bracketDepth(line string) int {
+	depth := 0
+	var quote rune
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case quote != 0:
+			if r == '\\' && quote == '"' {
+				escaped = true
+			} else if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#':
+			return depth
+		case r == '[':
+			depth++
+		case r == ']':
+			depth--
+		}
+	}
+	return depth
+}
+
+// hasValue reports whether a TOML document sets a key to value, or leaves it unset when
+// value is nil.
+func hasValue(data []byte, section string, name string, value any) bool {
+	document := make(map[string]any)
+	if _, err := toml.Decode(string(data), &document); err != nil {
+		return false
+	}
+	table, _ := document[section].(map[string]any)
+	got, ok := table[name]
+	if value == nil || !ok {
+		return value == nil && !ok
+	}
+	gotLine, err := keyLine(name, got)
+	if err != nil {
+		return false
+	}
+	wantLine, err := keyLine(name, value)
+	return err == nil && gotLine == wantLine
+}
+
+// rewriteKey sets a key of a TOML document by decoding it and encoding it again, which
+// keeps unknown keys but not comments. A nil value removes the key.
+func rewriteKey(data []byte, section string, name string, value any) ([]byte, error) {
+	document := make(map[string]any)
+	if _, err := toml.Decode(string(data), &document); err != nil {
+		return nil, fmt.Errorf("Error reading config file %s: %w", Path(), err)
+	}
+	table, _ := document[section].(map[string]any)
+	if table == nil {
+		table = make(map[string]any)
+	}
+	if value == nil {
+		delete(table, name)
+	} else {
+		table[name] = value
+	}
+	if len(table) == 0 {
+		delete(document, section)
+	} else {
+		document[section] = table
+	}
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(document); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// splitList splits a comma separated value, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,461 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Company is a company applied to. Jobs are matched to their company by name, ignoring
+// case, or by one of the company's aliases, so typos and other spellings of a company
+// name can be gathered under one company.
+type Company struct {
+	ID        int        `json:"id" db:"id"`
+	Name      string     `json:"name" db:"name"`
+	Aliases   []string   `json:"aliases,omitempty" db:"-"`
+	Website   NullString `json:"website" db:"website"`
+	Industry  NullString `json:"industry" db:"industry"`
+	Size      NullString `json:"size" db:"size"`
+	Notes     NullString `json:"notes" db:"notes"`
+	CreatedAt *time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at" db:"updated_at"`
+	// Jobs is the number of applications to the company, and Statuses how many of them
+	// have each status.
+	Jobs     int               `json:"jobs" db:"-"`
+	Statuses map[JobStatus]int `json:"statuses,omitempty" db:"-"`
+}
+
+// UpdatedCompanyParams holds the company fields to change, nil fields are left untouched.
+type UpdatedCompanyParams struct {
+	Website       *string
+	Industry      *string
+	Size          *string
+	Notes         *string
+	AddAliases    []string
+	RemoveAliases []string
+}
+
+const companyColumns = `id, name, website, industry, size, notes, created_at, updated_at`
+
+func getCompanies(q querier, query string, params ...any) ([]*Company, error) {
+	rows, err := q.Query(query, params...)
+	if err != nil {
+		return nil, err
+	}
+	var companies []*Company
+	for rows.Next() {
+		var company Company
+		var createdAt, updatedAt string
+		err := rows.Scan(
+			&company.ID,
+			&company.Name,
+			&company.Website,
+			&company.Industry,
+			&company.Size,
+			&company.Notes,
+			&createdAt,
+			&updatedAt,
+		)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		company.CreatedAt, _ = ParseDateTime(createdAt, false)
+		company.UpdatedAt, _ = ParseDateTime(updatedAt, false)
+		companies = append(companies, &company)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return companies, attachCompanyDetails(q, companies)
+}
+
+// attachCompanyDetails loads the aliases and application counts of companies.
+func attachCompanyDetails(q querier, companies []*Company) error {
+	if len(companies) == 0 {
+		return nil
+	}
+	byID := make(map[int]*Company, len(companies))
+	for _, company := range companies {
+		byID[company.ID] = company
+		company.Statuses = make(map[JobStatus]int)
+	}
+	rows, err := q.Query(`SELECT company_id, alias FROM company_aliases ORDER BY alias ASC;`)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var id int
+		var alias string
+		if err := rows.Scan(&id, &alias); err != nil {
+			rows.Close()
+			return err
+		}
+		if company, ok := byID[id]; ok {
+			company.Aliases = append(company.Aliases, alias)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows, err = q.Query(`SELECT company_id, status, COUNT(*) FROM jobs
+		WHERE company_id IS NOT NULL GROUP BY company_id, status;`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id, n int
+		var status JobStatus
+		if err := rows.Scan(&id, &status, &n); err != nil {
+			return err
+		}
+		if company, ok := byID[id]; ok {
+			company.Jobs += n
+			company.Statuses[status] += n
+		}
+	}
+	return rows.Err()
+}
+
+// findCompanyID returns the ID and name of the company with the given name or alias,
+// ignoring case, or 0 if there is none.
+func findCompanyID(q querier, name string) (int, string, error) {
+	const findQuery = `SELECT id, name FROM companies WHERE lower(name) = lower(?)
+		UNION ALL
+		SELECT companies.id, companies.name FROM company_aliases
+		JOIN companies ON companies.id = company_aliases.company_id
+		WHERE lower(company_aliases.alias) = lower(?);`
+	rows, err := q.Query(findQuery, strings.TrimSpace(name), strings.TrimSpace(name))
+	if err != nil {
+		return 0, "", err
+	}
+	defer rows.Close()
+	var id int
+	var companyName string
+	if rows.Next() {
+		if err := rows.Scan(&id, &companyName); err != nil {
+			return 0, "", err
+		}
+	}
+	return id, companyName, rows.Err()
+}
+
+// matchCompany returns the ID and name of the company a job at the named company belongs
+// to, adding the company if there is none of that name or alias yet.
+func matchCompany(tx *sql.Tx, name string) (int, string, error) {
+	name = strings.TrimSpace(name)
+	id, companyName, err := findCompanyID(tx, name)
+	if err != nil || id != 0 {
+		return id, companyName, err
+	}
+	now := currentTimestamp()
+	err = tx.QueryRow(
+		`INSERT INTO companies (name, created_at, updated_at) VALUES (?, ?, ?) RETURNING id;`,
+		name,
+		now,
+		now,
+	).Scan(&id)
+	if err != nil {
+		return 0, "", fmt.Errorf("Error adding company %q: %w", name, err)
+	}
+	return id, name, nil
+}
+
+// linkJobCompany matches a job to its company, spelling its company name the way the
+// company is named.
+func linkJobCompany(tx *sql.Tx, jobID int, name string) error {
+	id, companyName, err := matchCompany(tx, name)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`UPDATE jobs SET company_id = ?, company = ? WHERE id = ?;`, id, companyName, jobID)
+	return err
+}
+
+// linkCompanies matches the jobs that have no company yet to one, adding companies as
+// needed. The first spelling of a company name seen names the company.
+func linkCompanies(tx *sql.Tx) error {
+	rows, err := tx.Query(`SELECT id, company FROM jobs WHERE company_id IS NULL ORDER BY id ASC;`)
+	if err != nil {
+		return err
+	}
+	type unlinked struct {
+		id      int
+		company string
+	}
+	var jobs []unlinked
+	for rows.Next() {
+		var job unlinked
+		if err := rows.Scan(&job.id, &job.company); err != nil {
+			rows.Close()
+			return err
+		}
+		jobs = append(jobs, job)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		if err := linkJobCompany(tx, job.id, job.company); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pruneCompanies removes the companies no job is at any more, unless something was
+// recorded about them.
+func pruneCompanies(q querier) error {
+	_, err := q.Exec(`DELETE FROM companies
+		WHERE id NOT IN (SELECT company_id FROM jobs WHERE company_id IS NOT NULL)
+		AND id NOT IN (SELECT company_id FROM company_aliases)
+		AND website IS NULL AND industry IS NULL AND size IS NULL AND notes IS NULL;`)
+	return err
+}
+
+// addCompanies returns a migration step that creates the company tables with the given
+// statements, then adds every company jobs were entered at and links the jobs to them.
+func addCompanies(createStatements ...string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		statements := append(
+			createStatements,
+			`ALTER TABLE jobs ADD COLUMN company_id INTEGER REFERENCES companies (id) ON DELETE SET NULL;`,
+			`CREATE INDEX idx_jobs_company_id ON jobs (company_id);`,
+		)
+		if err := execStatements(statements...)(tx); err != nil {
+			return err
+		}
+		return linkCompanies(tx)
+	}
+}
+
+// GetCompanies returns every company with its aliases and application counts, by name.
+func GetCompanies(sqliteDB *sql.DB) ([]*Company, error) {
+	companies, err := getCompanies(sqliteDB, `SELECT `+companyColumns+` FROM companies;`)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(companies, func(i, j int) bool {
+		return strings.ToLower(companies[i].Name) < strings.ToLower(companies[j].Name)
+	})
+	return companies, nil
+}
+
+// GetCompanyByID returns a company, or nil if there is no company with that ID.
+func GetCompanyByID(sqliteDB *sql.DB, id int) (*Company, error) {
+	return getCompanyByID(sqliteDB, id)
+}
+
+func getCompanyByID(q querier, id int) (*Company, error) {
+	companies, err := getCompanies(q, `SELECT `+companyColumns+` FROM companies WHERE id = ?;`, id)
+	if err != nil || len(companies) == 0 {
+		return nil, err
+	}
+	return companies[0], nil
+}
+
+// FindCompany returns the company with the given ID, or with the given name or alias
+// ignoring case, or nil if there is none.
+func FindCompany(sqliteDB *sql.DB, ref string) (*Company, error) {
+	if id, err := strconv.Atoi(strings.TrimSpace(ref)); err == nil {
+		if company, err := GetCompanyByID(sqliteDB, id); err != nil || company != nil {
+			return company, err
+		}
+	}
+	id, _, err := findCompanyID(sqliteDB, ref)
+	if err != nil || id == 0 {
+		return nil, err
+	}
+	return GetCompanyByID(sqliteDB, id)
+}
+
+// checkCompanyName returns an error if name is the name or an alias of a company other
+// than the one with the given ID.
+func checkCompanyName(q querier, id int, name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("The company name cannot be empty")
+	}
+	other, otherName, err := findCompanyID(q, name)
+	if err != nil {
+		return err
+	}
+	if other != 0 && other != id {
+		return fmt.Errorf("%q is already a name of %s (ID: %d), merge the companies instead", name, otherName, other)
+	}
+	return nil
+}
+
+// addAlias gives a company another name, unless it already has it.
+func addAlias(q querier, id int, name string, alias string) error {
+	alias = strings.TrimSpace(alias)
+	if strings.EqualFold(alias, name) {
+		return nil
+	}
+	if err := checkCompanyName(q, id, alias); err != nil {
+		return err
+	}
+	_, err := q.Exec(`DELETE FROM company_aliases WHERE company_id = ? AND lower(alias) = lower(?);`, id, alias)
+	if err != nil {
+		return err
+	}
+	_, err = q.Exec(`INSERT INTO company_aliases (company_id, alias) VALUES (?, ?);`, id, alias)
+	return err
+}
+
+// UpdateCompany changes the given fields of a company. It returns nil if there is no
+// company with that ID.
+func UpdateCompany(sqliteDB *sql.DB, id int, updates UpdatedCompanyParams) (*Company, error) {
+	const updateQuery = `UPDATE companies
+		SET
+		website = COALESCE(?, website),
+		industry = COALESCE(?, industry),
+		size = COALESCE(?, size),
+		notes = COALESCE(?, notes),
+		updated_at = ?
+		WHERE id = ?;`
+	tx, err := sqliteDB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	company, err := getCompanyByID(tx, id)
+	if err != nil || company == nil {
+		return nil, err
+	}
+	_, err = tx.Exec(
+		updateQuery,
+		toSQLValue(updates.Website),
+		toSQLValue(updates.Industry),
+		toSQLValue(updates.Size),
+		toSQLValue(updates.Notes),
+		currentTimestamp(),
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, alias := range updates.RemoveAliases {
+		_, err := tx.Exec(`DELETE FROM company_aliases WHERE company_id = ? AND lower(alias) = lower(?);`, id, alias)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, alias := range updates.AddAliases {
+		if err := addAlias(tx, id, company.Name, alias); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return GetCompanyByID(sqliteDB, id)
+}
+
+// RenameCompany changes the name of a company and of the jobs at it. The old name is
+// kept as an alias, so jobs entered with it still match the company. It returns nil if
+// there is no company with that ID.
+func RenameCompany(sqliteDB *sql.DB, id int, name string) (*Company, error) {
+	name = strings.TrimSpace(name)
+	tx, err := sqliteDB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	company, err := getCompanyByID(tx, id)
+	if err != nil || company == nil {
+		return nil, err
+	}
+	if err := checkCompanyName(tx, id, name); err != nil {
+		return nil, err
+	}
+	now := currentTimestamp()
+	if _, err := tx.Exec(`UPDATE companies SET name = ?, updated_at = ? WHERE id = ?;`, name, now, id); err != nil {
+		return nil, err
+	}
+	_, err = tx.Exec(`DELETE FROM company_aliases WHERE company_id = ? AND lower(alias) = lower(?);`, id, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := addAlias(tx, id, name, company.Name); err != nil {
+		return nil, err
+	}
+	_, err = tx.Exec(`UPDATE jobs SET company = ?, updated_at = ? WHERE company_id = ?;`, name, now, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return GetCompanyByID(sqliteDB, id)
+}
+
+// MergeCompanies moves the jobs of one company to another and deletes it. The name and
+// aliases of the merged company become aliases of the one kept, which also takes the
+// details it has no value for. It returns nil if either company does not exist.
+func MergeCompanies(sqliteDB *sql.DB, fromID int, intoID int) (*Company, error) {
+	const detailsQuery = `UPDATE companies SET
+		website = COALESCE(website, ?),
+		industry = COALESCE(industry, ?),
+		size = COALESCE(size, ?),
+		notes = COALESCE(notes, ?),
+		updated_at = ?
+		WHERE id = ?;`
+	if fromID == intoID {
+		return nil, fmt.Errorf("Cannot merge a company into itself")
+	}
+	tx, err := sqliteDB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	from, err := getCompanyByID(tx, fromID)
+	if err != nil || from == nil {
+		return nil, err
+	}
+	into, err := getCompanyByID(tx, intoID)
+	if err != nil || into == nil {
+		return nil, err
+	}
+	now := currentTimestamp()
+	_, err = tx.Exec(
+		`UPDATE jobs SET company_id = ?, company = ?, updated_at = ? WHERE company_id = ?;`,
+		into.ID,
+		into.Name,
+		now,
+		from.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.Exec(
+		detailsQuery,
+		toSQLValue(&from.Website),
+		toSQLValue(&from.Industry),
+		toSQLValue(&from.Size),
+		toSQLValue(&from.Notes),
+		now,
+		into.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(`DELETE FROM companies WHERE id = ?;`, from.ID); err != nil {
+		return nil, err
+	}
+	for _, alias := range append([]string{from.Name}, from.Aliases...) {
+		if err := addAlias(tx, into.ID, into.Name, alias); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return GetCompanyByID(sqliteDB, into.ID)
+}
@@ -0,0 +1,192 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Contact is a person involved in job applications, such as a recruiter, hiring manager
+// or referrer. A contact can be linked to any number of jobs.
+type Contact struct {
+	ID          int        `json:"id" db:"id"`
+	Name        string     `json:"name" db:"name"`
+	Role        NullString `json:"role" db:"role"`
+	Email       NullString `json:"email" db:"email"`
+	Phone       NullString `json:"phone" db:"phone"`
+	LinkedInURL NullString `json:"linkedin_url" db:"linkedin_url"`
+	Company     NullString `json:"company" db:"company"`
+	CreatedAt   *time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   *time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type Contacts []*Contact
+
+// UpdatedContactParams holds the contact fields to change, nil fields are left untouched.
+type UpdatedContactParams struct {
+	Name        *string
+	Role        *string
+	Email       *string
+	Phone       *string
+	LinkedInURL *string
+	Company     *string
+}
+
+const contactColumns = `contacts.id, contacts.name, contacts.role, contacts.email, contacts.phone,
+	contacts.linkedin_url, contacts.company, contacts.created_at, contacts.updated_at`
+
+func scanContacts(rows *sql.Rows) (Contacts, error) {
+	var contacts Contacts
+	for rows.Next() {
+		var contact Contact
+		var createdAt, updatedAt string
+		err := rows.Scan(
+			&contact.ID,
+			&contact.Name,
+			&contact.Role,
+			&contact.Email,
+			&contact.Phone,
+			&contact.LinkedInURL,
+			&contact.Company,
+			&createdAt,
+			&updatedAt,
+		)
+		if err != nil {
+			return contacts, err
+		}
+		contact.CreatedAt, _ = ParseDateTime(createdAt, false)
+		contact.UpdatedAt, _ = ParseDateTime(updatedAt, false)
+		contacts = append(contacts, &contact)
+	}
+	if err := rows.Err(); err != nil {
+		return contacts, err
+	}
+	return contacts, nil
+}
+
+func getContacts(sqliteDB *sql.DB, query string, params ...any) (Contacts, error) {
+	rows, err := sqliteDB.Query(query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanContacts(rows)
+}
+
+// AddContact stores a new contact and sets its ID.
+func AddContact(sqliteDB *sql.DB, contact *Contact) error {
+	const insertQuery = `INSERT INTO contacts
+		(name, role, email, phone, linkedin_url, company, uuid)
+		VALUES
+		(?, ?, ?, ?, ?, ?, ?)
+		RETURNING id;`
+	return sqliteDB.QueryRow(
+		insertQuery,
+		contact.Name,
+		contact.Role,
+		contact.Email,
+		contact.Phone,
+		contact.LinkedInURL,
+		contact.Company,
+		uuid.NewString(),
+	).Scan(&contact.ID)
+}
+
+// GetContactByID returns a contact, or nil if there is no contact with that ID.
+func GetContactByID(sqliteDB *sql.DB, id int) (*Contact, error) {
+	contacts, err := getContacts(sqliteDB, `SELECT `+contactColumns+` FROM contacts WHERE id = ?;`, id)
+	if err != nil || len(contacts) == 0 {
+		return nil, err
+	}
+	return contacts[0], nil
+}
+
+// GetContacts returns every contact sorted by name, optionally only those whose company
+// contains the given text.
+func GetContacts(sqliteDB *sql.DB, company string) (Contacts, error) {
+	var b queryBuilder
+	if company != "" {
+		b.where(`lower(company) LIKE lower(?) ESCAPE '\'`, likePattern(company))
+	}
+	query := `SELECT ` + contactColumns + ` FROM contacts` + b.clause() + ` ORDER BY name ASC, id ASC;`
+	return getContacts(sqliteDB, query, b.params...)
+}
+
+// GetJobContacts returns the contacts linked to a job.
+func GetJobContacts(sqliteDB *sql.DB, jobID int) (Contacts, error) {
+	query := `SELECT ` + contactColumns + ` FROM contacts
+		JOIN job_contacts ON job_contacts.contact_id = contacts.id
+		WHERE job_contacts.job_id = ? ORDER BY contacts.name ASC;`
+	return getContacts(sqliteDB, query, jobID)
+}
+
+// GetContactJobs returns the jobs a contact is linked to.
+func GetContactJobs(sqliteDB *sql.DB, contactID int) ([]*Job, error) {
+	query := `SELECT ` + jobColumns + ` FROM jobs
+		WHERE id IN (SELECT job_id FROM job_contacts WHERE contact_id = ?)
+		ORDER BY applied_at ASC, id ASC;`
+	return getJobs(sqliteDB, query, contactID)
+}
+
+// UpdateContact changes the given fields of a contact. It returns nil if there is no
+// contact with that ID.
+func UpdateContact(sqliteDB *sql.DB, id int, updates UpdatedContactParams) (*Contact, error) {
+	const updateQuery = `UPDATE contacts
+		SET
+		name = COALESCE(?, name),
+		role = COALESCE(?, role),
+		email = COALESCE(?, email),
+		phone = COALESCE(?, phone),
+		linkedin_url = COALESCE(?, linkedin_url),
+		company = COALESCE(?, company),
+		updated_at = ?
+		WHERE id = ?;`
+	result, err := sqliteDB.Exec(
+		updateQuery,
+		toSQLValue(updates.Name),
+		toSQLValue(updates.Role),
+		toSQLValue(updates.Email),
+		toSQLValue(updates.Phone),
+		toSQLValue(updates.LinkedInURL),
+		toSQLValue(updates.Company),
+		currentTimestamp(),
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil, nil
+	}
+	return GetContactByID(sqliteDB, id)
+}
+
+// DeleteContact removes a contact and its links to jobs, reporting whether it existed.
+func DeleteContact(sqliteDB *sql.DB, id int) (bool, error) {
+	result, err := sqliteDB.Exec(`DELETE FROM contacts WHERE id = ?;`, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// LinkContact links a contact to a job. Linking them twice has no effect.
+func LinkContact(sqliteDB *sql.DB, jobID int, contactID int) error {
+	const linkQuery = `INSERT INTO job_contacts (job_id, contact_id) VALUES (?, ?)
+		ON CONFLICT (job_id, contact_id) DO NOTHING;`
+	_, err := sqliteDB.Exec(linkQuery, jobID, contactID)
+	return err
+}
+
+// UnlinkContact removes the link between a contact and a job, reporting whether it existed.
+func UnlinkContact(sqliteDB *sql.DB, jobID int, contactID int) (bool, error) {
+	const unlinkQuery = `DELETE FROM job_contacts WHERE job_id = ? AND contact_id = ?;`
+	result, err := sqliteDB.Exec(unlinkQuery, jobID, contactID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
@@ -13,50 +13,61 @@ import (
 	_ "modernc.org/sqlite"
 )
 
-// GetConnection returns a pointer to the sqlite database handle.
-func GetConnection() (*sql.DB, error) {
-	var dir, path string
+// DataDir returns the directory jobtrack keeps its data in.
+func DataDir() string {
 	if runtime.GOOS == "windows" {
-		appData := os.Getenv("APPDATA")
-		dir = filepath.Join(appData, "jobtrack")
-	} else {
-		home, _ := os.UserHomeDir()
-		dir = filepath.Join(home, ".local", "share", "jobtrack")
-	}
-	path = filepath.Join(dir, "jobs.db")
-	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return filepath.Join(os.Getenv("APPDATA"), "jobtrack")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".local", "share", "jobtrack")
+}
+
+// DefaultDatabasePath returns where the SQLite database is kept unless configured otherwise.
+func DefaultDatabasePath() string {
+	return filepath.Join(DataDir(), "jobs.db")
+}
+
+// GetConnection returns a pointer to the handle of the sqlite database at path, or at
+// DefaultDatabasePath if path is empty.
+func GetConnection(path string) (*sql.DB, error) {
+	if path == "" {
+		path = DefaultDatabasePath()
+	}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
 		return nil, fmt.Errorf("failed to create database directory: %w", err)
 	}
-	sqliteDB, err := sql.Open("sqlite", path)
+	// foreign keys are off by default in SQLite, they are needed for cascading deletes.
+	// The busy timeout lets concurrent writers, such as the API server, wait for the lock.
+	sqliteDB, err := sql.Open("sqlite", path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
 	if err != nil {
 		return nil, fmt.Errorf("couldn't connect to database: %w", err)
 	}
 	return sqliteDB, nil
 }
 
-// InitDB initializes the database, creating the table if it does not exist.
-// It takes a pointer to the database handle
+// InitDB checks the database connection and prepares it for migrations.
+// It takes a pointer to the database handle and refuses to continue if the
+// database was created by a newer version of jobtrack.
 func InitDB(db *sql.DB) error {
-	const schemaQuery = `CREATE TABLE IF NOT EXISTS jobs (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			company TEXT NOT NULL,
-			position TEXT NOT NULL,
-			status TEXT NOT NULL,
-			location TEXT,
-			applied_at TEXT NOT NULL DEFAULT (DATE('now')),
-			salary_range TEXT,
-			job_posting_url TEXT,
-			created_at TEXT NOT NULL DEFAULT (CURRENT_TIMESTAMP),
-			updated_at TEXT NOT NULL DEFAULT (CURRENT_TIMESTAMP)
-		);`
 	if db == nil {
 		return errors.New("The pointer passed to InitDB is nil")
 	}
 	if err := db.Ping(); err != nil {
 		return fmt.Errorf("No connection to the db: %w", err)
 	}
-	if _, err := db.Exec(schemaQuery); err != nil {
-		return fmt.Errorf("Error creating database: %w", err)
+	if err := ensureMigrationsTable(db); err != nil {
+		return err
+	}
+	version, err := SchemaVersion(db)
+	if err != nil {
+		return err
+	}
+	if version > LatestVersion() {
+		return fmt.Errorf(
+			"Database schema version %d is newer than the latest known version %d, please upgrade jobtrack",
+			version,
+			LatestVersion(),
+		)
 	}
 	return nil
 }
@@ -0,0 +1,241 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// MatchKey lists the fields that must all be equal for an imported job to be considered
+// a duplicate of an existing one. Values are compared ignoring case and surrounding
+// whitespace, posting URLs are normalised first.
+type MatchKey []string
+
+// DefaultMatchKey matches jobs at the same company, for the same position, applied to on
+// the same day.
+var DefaultMatchKey = MatchKey{"company", "position", "applied"}
+
+// matchFields maps the field names accepted by ParseMatchKey to the value of a job they
+// compare.
+var matchFields = map[string]func(job *Job) string{
+	"company":      func(job *Job) string { return job.Company },
+	"position":     func(job *Job) string { return job.Position },
+	"status":       func(job *Job) string { return string(job.Status) },
+	"location":     func(job *Job) string { return nullToEmpty(job.Location) },
+	"salary-range": func(job *Job) string { return nullToEmpty(job.SalaryRange) },
+	"url":          func(job *Job) string { return normalizeURL(nullToEmpty(job.JobPostingURL)) },
+	"applied":      func(job *Job) string { return formatOptionalDate(job.AppliedAt) },
+}
+
+// ParseMatchKey parses a comma separated list of fields, for example "company,position"
+// or "url".
+func ParseMatchKey(s string) (MatchKey, error) {
+	var key MatchKey
+	for _, field := range strings.Split(s, ",") {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if field == "" {
+			continue
+		}
+		if _, ok := matchFields[field]; !ok {
+			var valid []string
+			for name := range matchFields {
+				valid = append(valid, name)
+			}
+			sort.Strings(valid)
+			return nil, fmt.Errorf("Cannot match on %q, valid fields are: %s", field, strings.Join(valid, ", "))
+		}
+		key = append(key, field)
+	}
+	if len(key) == 0 {
+		return nil, fmt.Errorf("No fields to match duplicates on")
+	}
+	return key, nil
+}
+
+// value returns the value of the key for a job, or "" if any of its fields are empty, as
+// jobs missing part of the key cannot be told apart from others.
+func (k MatchKey) value(job *Job) string {
+	parts := make([]string, len(k))
+	for i, field := range k {
+		part := strings.ToLower(strings.TrimSpace(matchFields[field](job)))
+		if part == "" {
+			return ""
+		}
+		parts[i] = part
+	}
+	return strings.Join(parts, "\x1f")
+}
+
+// normalizeURL reduces a job posting URL to what identifies the posting, so links that
+// differ only in scheme, a www. prefix, trailing slashes, fragments or tracking
+// parameters are equal.
+func normalizeURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return strings.ToLower(raw)
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	query := u.Query()
+	for name := range query {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "utm_") || lower == "ref" || lower == "source" || lower == "gh_src" {
+			query.Del(name)
+		}
+	}
+	normalized := host + strings.TrimRight(u.EscapedPath(), "/")
+	if encoded := query.Encode(); encoded != "" {
+		normalized += "?" + encoded
+	}
+	return normalized
+}
+
+// ConflictAction decides what happens to an imported job that duplicates an existing one.
+type ConflictAction string
+
+const (
+	// ConflictSkip leaves the existing job as it is and drops the imported one.
+	ConflictSkip ConflictAction = "skip"
+	// ConflictUpdate overwrites the existing job with the non-empty fields of the
+	// imported one and adds any notes it does not have yet.
+	ConflictUpdate ConflictAction = "update"
+	// ConflictDuplicate adds the imported job anyway, with a new UUID if it shares the
+	// UUID of the existing job.
+	ConflictDuplicate ConflictAction = "duplicate"
+	// ConflictAsk asks what to do for every duplicate.
+	ConflictAsk ConflictAction = "ask"
+)
+
+// ConflictActions are the valid values of a ConflictAction.
+var ConflictActions = []string{
+	string(ConflictSkip),
+	string(ConflictUpdate),
+	string(ConflictDuplicate),
+	string(ConflictAsk),
+}
+
+// ParseConflictAction parses the name of a ConflictAction.
+func ParseConflictAction(s string) (ConflictAction, error) {
+	for _, action := range ConflictActions {
+		if strings.EqualFold(strings.TrimSpace(s), action) {
+			return ConflictAction(action), nil
+		}
+	}
+	return "", fmt.Errorf("Invalid conflict action %q, use one of: %s", s, strings.Join(ConflictActions, ", "))
+}
+
+// duplicateIndex finds the existing job an imported job duplicates. A job with the same
+// UUID is always the same job, other jobs are matched by the key.
+type duplicateIndex struct {
+	key    MatchKey
+	jobs   map[string]*Job
+	byUUID map[string]*Job
+}
+
+// newDuplicateIndex indexes every job in the database by the given key.
+func newDuplicateIndex(tx *sql.Tx, key MatchKey) (*duplicateIndex, error) {
+	rows, err := tx.Query(`SELECT ` + jobColumns + ` FROM jobs ORDER BY id ASC;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	jobs, err := FetchJobsFromRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	index := &duplicateIndex{key: key, jobs: make(map[string]*Job), byUUID: make(map[string]*Job)}
+	for _, job := range jobs {
+		index.add(job)
+	}
+	return index, nil
+}
+
+// add indexes a job, keeping the oldest job when several share a key.
+func (d *duplicateIndex) add(job *Job) {
+	if job.UUID != "" {
+		d.byUUID[job.UUID] = job
+	}
+	value := d.key.value(job)
+	if _, ok := d.jobs[value]; value != "" && !ok {
+		d.jobs[value] = job
+	}
+}
+
+// find returns the job that job duplicates, or nil.
+func (d *duplicateIndex) find(job *Job) *Job {
+	if existing, ok := d.byUUID[job.UUID]; ok && job.UUID != "" {
+		return existing
+	}
+	value := d.key.value(job)
+	if value == "" {
+		return nil
+	}
+	return d.jobs[value]
+}
+
+// mergeJob overwrites an existing job with the non-empty fields of an imported row, and
+// adds the imported notes the existing job does not have yet.
+func mergeJob(tx *sql.Tx, existing *Job, row *ImportRow) (*Job, error) {
+	imported := row.Job
+	optional := func(ns NullString) *string {
+		if !ns.Valid || ns.String == "" {
+			return nil
+		}
+		return &ns.String
+	}
+	updates := UpdatedJobParams{
+		Company:       &imported.Company,
+		Position:      &imported.Position,
+		Location:      optional(imported.Location),
+		SalaryRange:   optional(imported.SalaryRange),
+		JobPostingURL: optional(imported.JobPostingURL),
+		AppliedAt:     imported.AppliedAt,
+		FollowUpOn:    imported.FollowUpOn,
+		// imported statuses record what already happened, they are not checked
+		Force:     true,
+		AddTags:   imported.Tags,
+		SetFields: imported.Fields,
+	}
+	if row.hasStatus {
+		updates.Status = &imported.Status
+	}
+	job, err := updateJob(tx, existing.ID, updates)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.Query(`SELECT body FROM notes WHERE job_id = ?;`, existing.ID)
+	if err != nil {
+		return nil, err
+	}
+	bodies := make(map[string]bool)
+	for rows.Next() {
+		var body string
+		if err := rows.Scan(&body); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		bodies[body] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for _, note := range imported.Notes {
+		if bodies[note.Body] {
+			continue
+		}
+		if err := insertNote(tx, existing.ID, note); err != nil {
+			return nil, fmt.Errorf("Error adding job note: %w", err)
+		}
+		bodies[note.Body] = true
+	}
+	return job, nil
+}
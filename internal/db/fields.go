@@ -0,0 +1,204 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Custom fields are values users keep on jobs beyond the built-in ones, such as a
+// referral code or the team name. They are free-form: any name can be set on any job,
+// and a field is removed by setting it to an empty value.
+
+var fieldNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// reservedFieldNames are the names of the built-in fields, along with the names they
+// are known by in the jobs table and CSV files, which custom fields cannot use.
+var reservedFieldNames = []string{
+	"id", "uuid", "company", "position", "status", "location", "salary", "salary_range", "url",
+	"job_posting_url", "applied", "applied_at", "follow_up", "follow_up_on", "created",
+	"created_at", "updated", "updated_at", "tags", "notes", "fields", "converted_salary_min",
+	"converted_salary_max", "converted_currency", "stale_rate",
+}
+
+// FieldFilter matches jobs whose custom field Name is Value, ignoring case.
+type FieldFilter struct {
+	Name  string
+	Value string
+}
+
+// NormalizeFieldName lowercases the name of a custom field and spells - and spaces as
+// _, returning an error if it is not a valid name or is the name of a built-in field.
+func NormalizeFieldName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.NewReplacer("-", "_", " ", "_").Replace(name)
+	if !fieldNamePattern.MatchString(name) {
+		return "", fmt.Errorf("Invalid field name %q, use letters, digits and _, starting with a letter", name)
+	}
+	for _, reserved := range reservedFieldNames {
+		if name == reserved || csvColumnKey(name) == csvColumnKey(reserved) {
+			return "", fmt.Errorf("%q is a built-in field and cannot be used as a custom field", name)
+		}
+	}
+	return name, nil
+}
+
+// NormalizeFields normalises the names of custom fields with NormalizeFieldName and
+// trims their values. With dropEmpty, fields with an empty value are left out.
+func NormalizeFields(fields map[string]string, dropEmpty bool) (map[string]string, error) {
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	normalized := make(map[string]string, len(fields))
+	for name, value := range fields {
+		name, err := NormalizeFieldName(name)
+		if err != nil {
+			return nil, err
+		}
+		value = strings.TrimSpace(value)
+		if dropEmpty && value == "" {
+			continue
+		}
+		normalized[name] = value
+	}
+	if len(normalized) == 0 {
+		return nil, nil
+	}
+	return normalized, nil
+}
+
+// ParseFieldAssignment parses a custom field written name=value.
+func ParseFieldAssignment(s string) (string, string, error) {
+	name, value, ok := strings.Cut(s, "=")
+	if !ok {
+		return "", "", fmt.Errorf("Expected name=value, found %q", s)
+	}
+	name, err := NormalizeFieldName(name)
+	if err != nil {
+		return "", "", err
+	}
+	return name, strings.TrimSpace(value), nil
+}
+
+// FieldNames returns the names of the custom fields set on any of the jobs, sorted.
+func FieldNames(jobs []*Job) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, job := range jobs {
+		for name := range job.Fields {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// encodeFields writes custom fields as a JSON object with sorted keys, or "" if there are
+// none, so equal fields are always encoded the same.
+func encodeFields(fields map[string]string) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(fields)
+	return string(data)
+}
+
+// decodeFields reads custom fields written by encodeFields.
+func decodeFields(s string) (map[string]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var fields map[string]string
+	if err := json.Unmarshal([]byte(s), &fields); err != nil {
+		return nil, fmt.Errorf("Invalid custom fields %q: %w", s, err)
+	}
+	return NormalizeFields(fields, true)
+}
+
+// attachFields loads the custom fields of every given job into its Fields field.
+func attachFields(q querier, jobs []*Job) error {
+	if len(jobs) == 0 {
+		return nil
+	}
+	var b queryBuilder
+	if len(jobs) == 1 {
+		b.where("job_id = ?", jobs[0].ID)
+	}
+	rows, err := q.Query(`SELECT job_id, name, value FROM job_fields`+b.clause()+`;`, b.params...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	byJob := make(map[int]map[string]string)
+	for rows.Next() {
+		var jobID int
+		var name, value string
+		if err := rows.Scan(&jobID, &name, &value); err != nil {
+			return err
+		}
+		if byJob[jobID] == nil {
+			byJob[jobID] = make(map[string]string)
+		}
+		byJob[jobID][name] = value
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		job.Fields = byJob[job.ID]
+	}
+	return nil
+}
+
+// setJobFields sets custom fields of a job, removing those set to an empty value.
+func setJobFields(q querier, jobID int, fields map[string]string) error {
+	const (
+		upsertQuery = `INSERT INTO job_fields (job_id, name, value) VALUES (?, ?, ?)
+			ON CONFLICT (job_id, name) DO UPDATE SET value = excluded.value;`
+		deleteQuery = `DELETE FROM job_fields WHERE job_id = ? AND name = ?;`
+	)
+	for name, value := range fields {
+		var err error
+		if value == "" {
+			_, err = q.Exec(deleteQuery, jobID, name)
+		} else {
+			_, err = q.Exec(upsertQuery, jobID, name, value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceJobFields makes fields the only custom fields of a job.
+func replaceJobFields(q querier, jobID int, fields map[string]string) error {
+	if _, err := q.Exec(`DELETE FROM job_fields WHERE job_id = ?;`, jobID); err != nil {
+		return err
+	}
+	return setJobFields(q, jobID, fields)
+}
+
+// GetFieldNames returns the names of every custom field set on a job, sorted.
+func GetFieldNames(sqliteDB *sql.DB) ([]string, error) {
+	rows, err := sqliteDB.Query(`SELECT DISTINCT name FROM job_fields ORDER BY name ASC;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
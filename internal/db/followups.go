@@ -0,0 +1,90 @@
+package db
+
+import (
+	"database/sql"
+	"sort"
+	"time"
+)
+
+// FollowUp is a job application that should be chased on or before DueOn.
+type FollowUp struct {
+	Job   *Job
+	DueOn time.Time
+	// Automatic is true when DueOn comes from the default for the job's status rather
+	// than a follow-up date set on the job.
+	Automatic bool
+}
+
+// lastStatusChanges returns when each job whose status was ever changed entered its
+// current status. Jobs still in the status they were created with are left out.
+func lastStatusChanges(sqliteDB *sql.DB) (map[int]time.Time, error) {
+	const selectQuery = `SELECT job_id, MAX(changed_at) FROM status_events
+		WHERE old_status IS NOT NULL GROUP BY job_id;`
+	rows, err := sqliteDB.Query(selectQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	changes := make(map[int]time.Time)
+	for rows.Next() {
+		var jobID int
+		var changedAt string
+		if err := rows.Scan(&jobID, &changedAt); err != nil {
+			return nil, err
+		}
+		if t, err := ParseDateTime(changedAt, false); err == nil {
+			changes[jobID] = *t
+		}
+	}
+	return changes, rows.Err()
+}
+
+// GetFollowUps returns the follow-ups due on or before until, most overdue first. Jobs in
+// a final status of the pipeline are skipped. Jobs without a follow-up date of their own are
+// due the number of days given by defaults after entering their current status, and are
+// skipped if their status has no default. Jobs still in the initial status they were created
+// with are due that many days after they were applied to, so applications entered after the
+// fact are not given a follow-up in the future.
+func GetFollowUps(sqliteDB *sql.DB, defaults map[JobStatus]int, until time.Time) ([]*FollowUp, error) {
+	jobs, err := GetAllJobs(sqliteDB, false)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := lastStatusChanges(sqliteDB)
+	if err != nil {
+		return nil, err
+	}
+	var followUps []*FollowUp
+	for _, job := range jobs {
+		if _, known := activePipeline.Lookup(string(job.Status)); known && activePipeline.IsFinal(job.Status) {
+			continue
+		}
+		followUp := FollowUp{Job: job}
+		switch days, hasDefault := defaults[activePipeline.canonical(job.Status)]; {
+		case job.FollowUpOn != nil:
+			followUp.DueOn = *job.FollowUpOn
+		case hasDefault:
+			since, ok := changes[job.ID]
+			switch {
+			case ok:
+			case job.AppliedAt != nil && activePipeline.canonical(job.Status) == activePipeline.Initial():
+				since = *job.AppliedAt
+			default:
+				since = *job.CreatedAt
+			}
+			since = time.Date(since.Year(), since.Month(), since.Day(), 0, 0, 0, 0, time.UTC)
+			followUp.DueOn = since.AddDate(0, 0, days)
+			followUp.Automatic = true
+		default:
+			continue
+		}
+		if followUp.DueOn.After(until) {
+			continue
+		}
+		followUps = append(followUps, &followUp)
+	}
+	sort.SliceStable(followUps, func(i, j int) bool {
+		return followUps[i].DueOn.Before(followUps[j].DueOn)
+	})
+	return followUps, nil
+}
@@ -0,0 +1,42 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetFollowUpsCountsFromApplied(t *testing.T) {
+	sqliteDB := openTestDB(t)
+	today := time.Now().UTC()
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	applied := today.AddDate(0, 0, -30)
+	backfilled := &Job{Company: "Acme", Position: "Developer", Status: APPLIED, AppliedAt: &applied}
+	if err := AddJob(sqliteDB, backfilled); err != nil {
+		t.Fatal(err)
+	}
+	interviewing := &Job{Company: "Globex", Position: "Tester", Status: APPLIED, AppliedAt: &applied}
+	if err := AddJob(sqliteDB, interviewing); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := UpdateJob(sqliteDB, interviewing.ID, UpdatedJobParams{Status: ptr(INTERVIEW)}); err != nil {
+		t.Fatal(err)
+	}
+
+	defaults := map[JobStatus]int{APPLIED: 10, INTERVIEW: 7}
+	followUps, err := GetFollowUps(sqliteDB, defaults, today.AddDate(1, 0, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	due := make(map[int]time.Time)
+	for _, followUp := range followUps {
+		due[followUp.Job.ID] = followUp.DueOn
+	}
+	// an application entered after the fact is due counting from when it was made
+	if want := applied.AddDate(0, 0, 10); !due[backfilled.ID].Equal(want) {
+		t.Errorf("backfilled job is due on %s, want %s", due[backfilled.ID].Format(time.DateOnly), want.Format(time.DateOnly))
+	}
+	// a status change counts from when it was made
+	if want := today.AddDate(0, 0, 7); !due[interviewing.ID].Equal(want) {
+		t.Errorf("interviewing job is due on %s, want %s", due[interviewing.ID].Format(time.DateOnly), want.Format(time.DateOnly))
+	}
+}
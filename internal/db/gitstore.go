@@ -0,0 +1,929 @@
+package db
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// gitTable is a table kept in the git tree as a directory with a file per row. The file
+// also holds the rows of the child tables that reference the row through their ref
+// column, so everything about a job is in the job's file.
+//
+// Integer IDs are local to the temporary database and left out of the files, since two
+// clones would give the same ID to different rows. They are assigned again on load, and
+// child rows are linked to the row of the file they are in.
+type gitTable struct {
+	table string
+	// name is the key of the row in its file.
+	name string
+	// key holds the columns the file is named after, joined with underscores.
+	key []string
+	// omit holds columns of local IDs that are left out of the file besides id.
+	omit []string
+	// mergeOn is a column that is unique ignoring case. Rows with the same value in two
+	// files, such as a company added in two clones, are loaded as one.
+	mergeOn  string
+	children []string
+	ref      string
+}
+
+// gitTables lists the tables kept in the tree, in the order they are loaded.
+var gitTables = []gitTable{
+	{table: "contacts", name: "contact", key: []string{"uuid"}},
+	{table: "tags", name: "tag", key: []string{"name"}},
+	{table: "exchange_rates", name: "rate", key: []string{"currency", "base", "effective_on"}},
+	{
+		table:    "companies",
+		name:     "company",
+		key:      []string{"uuid"},
+		mergeOn:  "name",
+		children: []string{"company_aliases"},
+		ref:      "company_id",
+	},
+	{
+		table: "jobs",
+		name:  "job",
+		key:   []string{"uuid"},
+		// jobs are linked to their company by name on load
+		omit:     []string{"company_id"},
+		children: []string{"notes", "status_events", "interviews", "offers", "job_contacts", "job_tags", "job_fields"},
+		ref:      "job_id",
+	},
+}
+
+// gitRef is a column of a child table referencing a row of another table by its ID. The
+// file holds the key of the referenced row under field instead, e.g. the name of a tag.
+type gitRef struct {
+	column string
+	field  string
+	table  string
+	key    string
+}
+
+// uuidTables are the tables whose rows have a UUID, filled in for rows of files written
+// before they had one and for companies added while linking jobs.
+var uuidTables = []string{"contacts", "companies", "interviews"}
+
+var gitRefs = []gitRef{
+	{column: "tag_id", field: "tag", table: "tags", key: "name"},
+	{column: "contact_id", field: "contact", table: "contacts", key: "uuid"},
+}
+
+// ErrSyncNotSupported is returned by GitStore.Sync, git repositories are synced with git.
+var ErrSyncNotSupported = errors.New("Sync is not available when jobs are kept in git, push and pull the repository instead")
+
+// GitStore keeps jobs and contacts as JSON files in a git working tree, one file per job
+// or contact, and commits every change with a message describing it. The files are
+// loaded into a temporary SQLite database when the store is opened, which answers
+// queries, so a GitStore behaves exactly like a SQLStore.
+type GitStore struct {
+	*SQLStore
+	dir    string
+	tmpDir string
+	// mu serialises changes, which each end with a commit.
+	mu sync.Mutex
+	// loaded is the state of the tree when it was last loaded or saved, and files the
+	// files it then had, relative to dir. Changes made to the tree since, by another
+	// jobtrack or a git pull, are loaded before making a change of our own.
+	loaded string
+	files  map[string]bool
+}
+
+// OpenGitStore opens the git working tree at dir, creating the directory and the
+// repository if they do not exist yet.
+func OpenGitStore(dir string) (*GitStore, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, errors.New("Keeping jobs in git needs git to be installed")
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("failed to create git directory: %w", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".git")); os.IsNotExist(err) {
+		if _, err := runGit(dir, "init", "--quiet"); err != nil {
+			return nil, err
+		}
+	}
+	tmpDir, err := os.MkdirTemp("", "jobtrack-git-")
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(tmpDir, "jobs.db")
+	sqliteDB, err := sql.Open("sqlite", path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+	if err == nil {
+		err = InitDB(sqliteDB)
+	}
+	if err == nil {
+		err = Migrate(sqliteDB, LatestVersion())
+	}
+	s := &GitStore{SQLStore: NewSQLStore(sqliteDB), dir: dir, tmpDir: tmpDir}
+	if err == nil {
+		err = s.load()
+	}
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close closes the temporary database and removes it.
+func (s *GitStore) Close() error {
+	var err error
+	if s.db != nil {
+		err = s.db.Close()
+	}
+	os.RemoveAll(s.tmpDir)
+	return err
+}
+
+// runGit runs a git command in dir, returning its output.
+func runGit(dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %s", args[0], strings.TrimSpace(stderr.String()+stdout.String()))
+	}
+	return stdout.String(), nil
+}
+
+// columnName matches the column names accepted from files.
+var columnName = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// rowColumns returns the columns of a row read from a file, sorted, and their values.
+func rowColumns(row map[string]any) ([]string, []any, error) {
+	columns := make([]string, 0, len(row))
+	for column := range row {
+		if !columnName.MatchString(column) {
+			return nil, nil, fmt.Errorf("Invalid column name %q", column)
+		}
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	values := make([]any, len(columns))
+	for i, column := range columns {
+		values[i] = row[column]
+		// numbers are decoded as json.Number to keep them exact
+		if number, ok := values[i].(json.Number); ok {
+			if n, err := number.Int64(); err == nil {
+				values[i] = n
+			} else if f, err := number.Float64(); err == nil {
+				values[i] = f
+			}
+		}
+	}
+	return columns, values, nil
+}
+
+// insertRow inserts a row read from a file into table, returning its ID. With orIgnore,
+// a row that breaks a unique constraint is skipped.
+func insertRow(tx *sql.Tx, table string, row map[string]any, orIgnore bool) (int64, error) {
+	columns, values, err := rowColumns(row)
+	if err != nil {
+		return 0, err
+	}
+	insert := "INSERT"
+	if orIgnore {
+		insert = "INSERT OR IGNORE"
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	query := fmt.Sprintf(`%s INTO %s (%s) VALUES (%s);`, insert, table, strings.Join(columns, ", "), placeholders)
+	result, err := tx.Exec(query, values...)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// mergeRow finds the row of table with the same value in the mergeOn column as a row read
+// from a file, giving it the values of the row for columns it has none for. It returns
+// the ID of the row found, or 0 if there is none.
+func mergeRow(tx *sql.Tx, table gitTable, row map[string]any) (int64, error) {
+	var id int64
+	query := fmt.Sprintf(`SELECT id FROM %s WHERE lower(%s) = lower(?);`, table.table, table.mergeOn)
+	err := tx.QueryRow(query, row[table.mergeOn]).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	columns, values, err := rowColumns(row)
+	if err != nil {
+		return 0, err
+	}
+	for i, column := range columns {
+		if column == table.mergeOn || slices.Contains(table.key, column) {
+			continue
+		}
+		update := fmt.Sprintf(`UPDATE %s SET %s = COALESCE(%s, ?) WHERE id = ?;`, table.table, column, column)
+		if _, err := tx.Exec(update, values[i], id); err != nil {
+			return 0, err
+		}
+	}
+	return id, nil
+}
+
+// resolveRefs replaces the keys of the rows a child row references with their IDs. It
+// returns false if a referenced row does not exist, e.g. a contact deleted in another
+// clone, and the child row should be dropped. ids maps the IDs stored in files written
+// before IDs were left out to the IDs the rows were loaded with.
+func resolveRefs(tx *sql.Tx, row map[string]any, ids map[string]map[string]int64) (bool, error) {
+	for _, ref := range gitRefs {
+		if key, ok := row[ref.field]; ok {
+			delete(row, ref.field)
+			var id int64
+			query := fmt.Sprintf(`SELECT id FROM %s WHERE %s = ?;`, ref.table, ref.key)
+			err := tx.QueryRow(query, key).Scan(&id)
+			if errors.Is(err, sql.ErrNoRows) {
+				return false, nil
+			}
+			if err != nil {
+				return false, err
+			}
+			row[ref.column] = id
+		} else if oldID, ok := row[ref.column]; ok {
+			id, ok := ids[ref.table][fmt.Sprint(oldID)]
+			if !ok {
+				return false, nil
+			}
+			row[ref.column] = id
+		}
+	}
+	return true, nil
+}
+
+// selectRows returns the rows of a query as maps from column names to values.
+func selectRows(tx *sql.Tx, query string, args ...any) ([]map[string]any, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var result []map[string]any
+	for rows.Next() {
+		values := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		row := make(map[string]any, len(columns))
+		for i, column := range columns {
+			if b, ok := values[i].([]byte); ok {
+				values[i] = string(b)
+			}
+			if t, ok := values[i].(time.Time); ok {
+				values[i] = FormatDateTime(t, false)
+			}
+			row[column] = values[i]
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// treeState describes the commit checked out and the files of the tree, so changes made
+// to the tree by others can be noticed.
+func (s *GitStore) treeState() (string, error) {
+	// a repository without commits has no HEAD yet
+	head, _ := runGit(s.dir, "rev-parse", "--quiet", "--verify", "HEAD")
+	var state strings.Builder
+	state.WriteString(strings.TrimSpace(head))
+	for _, table := range gitTables {
+		entries, err := os.ReadDir(filepath.Join(s.dir, table.table))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		for _, entry := range entries {
+			if !strings.HasSuffix(entry.Name(), ".json") {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return "", err
+			}
+			fmt.Fprintf(&state, "\n%s/%s %d %d", table.table, entry.Name(), info.Size(), info.ModTime().UnixNano())
+		}
+	}
+	return state.String(), nil
+}
+
+// refresh loads the tree again if it changed since it was last loaded or saved.
+func (s *GitStore) refresh() error {
+	state, err := s.treeState()
+	if err != nil || state == s.loaded {
+		return err
+	}
+	return s.load()
+}
+
+// load reads every file of the tree into the temporary database, replacing what it held.
+func (s *GitStore) load() error {
+	state, err := s.treeState()
+	if err != nil {
+		return err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for i := len(gitTables) - 1; i >= 0; i-- {
+		for _, child := range gitTables[i].children {
+			if _, err := tx.Exec(fmt.Sprintf(`DELETE FROM %s;`, child)); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.Exec(fmt.Sprintf(`DELETE FROM %s;`, gitTables[i].table)); err != nil {
+			return err
+		}
+	}
+	// IDs start over from 1, so they stay the same as long as the tree does
+	if _, err := tx.Exec(`DELETE FROM sqlite_sequence;`); err != nil {
+		return err
+	}
+	files := make(map[string]bool)
+	ids := make(map[string]map[string]int64)
+	for _, table := range gitTables {
+		paths, err := filepath.Glob(filepath.Join(s.dir, table.table, "*.json"))
+		if err != nil {
+			return err
+		}
+		var loaded []*gitFile
+		for _, path := range paths {
+			rel, _ := filepath.Rel(s.dir, path)
+			file, err := readGitFile(table, path)
+			if err != nil {
+				return fmt.Errorf("Error loading %s: %w", rel, err)
+			}
+			loaded = append(loaded, file)
+			files[rel] = true
+		}
+		// rows get their IDs in the order they were created
+		sort.SliceStable(loaded, func(i, j int) bool {
+			created, otherCreated := fmt.Sprint(loaded[i].row["created_at"]), fmt.Sprint(loaded[j].row["created_at"])
+			if created != otherCreated {
+				return created < otherCreated
+			}
+			return loaded[i].path < loaded[j].path
+		})
+		ids[table.table] = make(map[string]int64)
+		for _, file := range loaded {
+			if err := loadGitFile(tx, table, file, ids); err != nil {
+				rel, _ := filepath.Rel(s.dir, file.path)
+				return fmt.Errorf("Error loading %s: %w", rel, err)
+			}
+		}
+	}
+	// files written before salaries, locations and companies were parsed only have the
+	// text entered
+	if err := fillSalaries(tx); err != nil {
+		return err
+	}
+	if err := fillPlaces(tx); err != nil {
+		return err
+	}
+	if err := linkCompanies(tx); err != nil {
+		return err
+	}
+	for _, table := range uuidTables {
+		if err := fillUUIDs(tx, table); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	s.loaded, s.files = state, files
+	return nil
+}
+
+// gitFile is a file of the tree, holding a row and the rows of its child tables.
+type gitFile struct {
+	path     string
+	row      map[string]any
+	children map[string][]map[string]any
+}
+
+// readGitFile reads a file of the tree holding a row of table.
+func readGitFile(table gitTable, path string) (*gitFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	decode := func(raw json.RawMessage, v any) error {
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.UseNumber()
+		return decoder.Decode(v)
+	}
+	var content map[string]json.RawMessage
+	if err := decode(data, &content); err != nil {
+		return nil, err
+	}
+	file := &gitFile{path: path, children: make(map[string][]map[string]any)}
+	if err := decode(content[table.name], &file.row); err != nil || file.row == nil {
+		return nil, fmt.Errorf("expected the %s under %q", table.name, table.name)
+	}
+	for _, child := range table.children {
+		raw, ok := content[child]
+		if !ok {
+			continue
+		}
+		var rows []map[string]any
+		if err := decode(raw, &rows); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", child, err)
+		}
+		file.children[child] = rows
+	}
+	return file, nil
+}
+
+// loadGitFile inserts the rows of a file of the tree. Files written before IDs were left
+// out still hold them, ids records the ID each of their rows was loaded with.
+func loadGitFile(tx *sql.Tx, table gitTable, file *gitFile, ids map[string]map[string]int64) error {
+	row := file.row
+	oldID, hasOldID := row["id"]
+	delete(row, "id")
+	for _, column := range table.omit {
+		delete(row, column)
+	}
+	var id int64
+	var err error
+	if table.mergeOn != "" {
+		if id, err = mergeRow(tx, table, row); err != nil {
+			return err
+		}
+	}
+	merged := id != 0
+	if !merged {
+		if id, err = insertRow(tx, table.table, row, false); err != nil {
+			return err
+		}
+	}
+	if hasOldID {
+		ids[table.table][fmt.Sprint(oldID)] = id
+	}
+	for _, child := range table.children {
+		for _, row := range file.children[child] {
+			delete(row, "id")
+			row[table.ref] = id
+			ok, err := resolveRefs(tx, row, ids)
+			if err != nil {
+				return fmt.Errorf("adding %s: %w", child, err)
+			}
+			if !ok {
+				continue
+			}
+			// the row merged into may have the same child rows, such as an alias
+			if _, err := insertRow(tx, child, row, merged); err != nil {
+				return fmt.Errorf("adding %s: %w", child, err)
+			}
+		}
+	}
+	return nil
+}
+
+// save writes every row of the temporary database to the tree, leaving files whose
+// content did not change alone, and commits the changes with message. Only files that
+// were loaded are removed, so a file added to the tree meanwhile is never lost.
+func (s *GitStore) save(message string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, table := range uuidTables {
+		if err := fillUUIDs(tx, table); err != nil {
+			return err
+		}
+	}
+	var dirs []string
+	files := make(map[string]bool)
+	for _, table := range gitTables {
+		dir := filepath.Join(s.dir, table.table)
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return err
+		}
+		dirs = append(dirs, table.table)
+		rows, err := selectRows(tx, fmt.Sprintf(`SELECT * FROM %s ORDER BY id;`, table.table))
+		if err != nil {
+			return err
+		}
+		written := make(map[string]bool)
+		for _, row := range rows {
+			file := map[string]any{table.name: row}
+			for _, child := range table.children {
+				query := fmt.Sprintf(`SELECT * FROM %s WHERE %s = ? ORDER BY rowid;`, child, table.ref)
+				childRows, err := selectRows(tx, query, row["id"])
+				if err != nil {
+					return err
+				}
+				for _, childRow := range childRows {
+					if err := keyRefs(tx, childRow); err != nil {
+						return err
+					}
+					delete(childRow, "id")
+					delete(childRow, table.ref)
+				}
+				if len(childRows) > 0 {
+					file[child] = childRows
+				}
+			}
+			key := make([]string, len(table.key))
+			for i, column := range table.key {
+				key[i] = fmt.Sprint(row[column])
+			}
+			delete(row, "id")
+			for _, column := range table.omit {
+				delete(row, column)
+			}
+			data, err := json.MarshalIndent(file, "", "  ")
+			if err != nil {
+				return err
+			}
+			name := strings.Join(key, "_") + ".json"
+			written[name] = true
+			files[filepath.Join(table.table, name)] = true
+			path := filepath.Join(dir, name)
+			if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, append(data, '\n')) {
+				continue
+			}
+			if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+				return err
+			}
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			rel := filepath.Join(table.table, entry.Name())
+			if s.files[rel] && !written[entry.Name()] {
+				if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	if err := s.commit(message, dirs); err != nil {
+		return err
+	}
+	s.files = files
+	s.loaded, err = s.treeState()
+	return err
+}
+
+// keyRefs replaces the IDs of the rows a child row references with their keys.
+func keyRefs(tx *sql.Tx, row map[string]any) error {
+	for _, ref := range gitRefs {
+		id, ok := row[ref.column]
+		if !ok {
+			continue
+		}
+		var key string
+		query := fmt.Sprintf(`SELECT %s FROM %s WHERE id = ?;`, ref.key, ref.table)
+		if err := tx.QueryRow(query, id).Scan(&key); err != nil {
+			return err
+		}
+		delete(row, ref.column)
+		row[ref.field] = key
+	}
+	return nil
+}
+
+// commit commits the changes to dirs, if there are any.
+func (s *GitStore) commit(message string, dirs []string) error {
+	if _, err := runGit(s.dir, append([]string{"add", "--all", "--"}, dirs...)...); err != nil {
+		return err
+	}
+	status, err := runGit(s.dir, append([]string{"status", "--porcelain", "--"}, dirs...)...)
+	if err != nil || strings.TrimSpace(status) == "" {
+		return err
+	}
+	args := []string{"commit", "--quiet", "--message", message}
+	// commits still work on machines where git has not been told who the user is
+	if name, _ := runGit(s.dir, "config", "user.name"); strings.TrimSpace(name) == "" {
+		args = append([]string{"-c", "user.name=jobtrack"}, args...)
+	}
+	if email, _ := runGit(s.dir, "config", "user.email"); strings.TrimSpace(email) == "" {
+		args = append([]string{"-c", "user.email=jobtrack@localhost"}, args...)
+	}
+	_, err = runGit(s.dir, args...)
+	return err
+}
+
+// describeJob names a job in commit messages, e.g. "Developer at Acme".
+func (s *GitStore) describeJob(jobID int) string {
+	job, err := s.SQLStore.GetJobByID(jobID)
+	if err != nil || job == nil {
+		return fmt.Sprintf("job %d", jobID)
+	}
+	return fmt.Sprintf("%s at %s", job.Position, job.Company)
+}
+
+func (s *GitStore) AddJob(job *Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refresh(); err != nil {
+		return err
+	}
+	if err := s.SQLStore.AddJob(job); err != nil {
+		return err
+	}
+	return s.save(fmt.Sprintf("Add %s at %s (%s)", job.Position, job.Company, job.Status))
+}
+
+func (s *GitStore) UpdateJob(jobID int, updates UpdatedJobParams) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refresh(); err != nil {
+		return nil, err
+	}
+	before, err := s.SQLStore.GetJobByID(jobID)
+	if err != nil || before == nil {
+		return nil, err
+	}
+	job, err := s.SQLStore.UpdateJob(jobID, updates)
+	if err != nil || job == nil {
+		return job, err
+	}
+	message := fmt.Sprintf("Update %s at %s", job.Position, job.Company)
+	if job.Status != before.Status {
+		message = fmt.Sprintf("Move %s at %s from %s to %s", job.Position, job.Company, before.Status, job.Status)
+	}
+	return job, s.save(message)
+}
+
+func (s *GitStore) DeleteJobByID(jobID int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refresh(); err != nil {
+		return false, err
+	}
+	description := s.describeJob(jobID)
+	deleted, err := s.SQLStore.DeleteJobByID(jobID)
+	if err != nil || !deleted {
+		return deleted, err
+	}
+	return true, s.save("Delete " + description)
+}
+
+func (s *GitStore) ImportJobs(rows []*ImportRow, options ImportOptions) (*ImportResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refresh(); err != nil {
+		return nil, err
+	}
+	result, err := s.SQLStore.ImportJobs(rows, options)
+	if err != nil || options.DryRun {
+		return result, err
+	}
+	message := fmt.Sprintf("Import %d jobs", result.Added)
+	if result.Updated > 0 {
+		message += fmt.Sprintf(" and update %d", result.Updated)
+	}
+	return result, s.save(message)
+}
+
+func (s *GitStore) SetExchangeRates(rates []*ExchangeRate) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refresh(); err != nil {
+		return err
+	}
+	if err := s.SQLStore.SetExchangeRates(rates); err != nil {
+		return err
+	}
+	if len(rates) == 1 {
+		return s.save(fmt.Sprintf("Set %s rate to %g %s", rates[0].Currency, rates[0].Rate, rates[0].Base))
+	}
+	return s.save(fmt.Sprintf("Set %d exchange rates", len(rates)))
+}
+
+func (s *GitStore) AddNote(jobID int, body string) (*Note, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refresh(); err != nil {
+		return nil, err
+	}
+	note, err := s.SQLStore.AddNote(jobID, body)
+	if err != nil || note == nil {
+		return note, err
+	}
+	return note, s.save("Add note to " + s.describeJob(jobID))
+}
+
+func (s *GitStore) UpdateNote(noteID int, body string) (*Note, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refresh(); err != nil {
+		return nil, err
+	}
+	note, err := s.SQLStore.UpdateNote(noteID, body)
+	if err != nil || note == nil {
+		return note, err
+	}
+	return note, s.save("Edit note on " + s.describeJob(note.JobID))
+}
+
+func (s *GitStore) DeleteNote(noteID int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refresh(); err != nil {
+		return false, err
+	}
+	deleted, err := s.SQLStore.DeleteNote(noteID)
+	if err != nil || !deleted {
+		return deleted, err
+	}
+	return true, s.save(fmt.Sprintf("Delete note %d", noteID))
+}
+
+func (s *GitStore) AddContact(contact *Contact) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refresh(); err != nil {
+		return err
+	}
+	if err := s.SQLStore.AddContact(contact); err != nil {
+		return err
+	}
+	return s.save("Add contact " + contact.Name)
+}
+
+func (s *GitStore) UpdateContact(id int, updates UpdatedContactParams) (*Contact, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refresh(); err != nil {
+		return nil, err
+	}
+	contact, err := s.SQLStore.UpdateContact(id, updates)
+	if err != nil || contact == nil {
+		return contact, err
+	}
+	return contact, s.save("Update contact " + contact.Name)
+}
+
+func (s *GitStore) DeleteContact(id int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refresh(); err != nil {
+		return false, err
+	}
+	deleted, err := s.SQLStore.DeleteContact(id)
+	if err != nil || !deleted {
+		return deleted, err
+	}
+	return true, s.save(fmt.Sprintf("Delete contact %d", id))
+}
+
+func (s *GitStore) LinkContact(jobID int, contactID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refresh(); err != nil {
+		return err
+	}
+	if err := s.SQLStore.LinkContact(jobID, contactID); err != nil {
+		return err
+	}
+	return s.save(fmt.Sprintf("Link contact %d to %s", contactID, s.describeJob(jobID)))
+}
+
+func (s *GitStore) UnlinkContact(jobID int, contactID int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refresh(); err != nil {
+		return false, err
+	}
+	unlinked, err := s.SQLStore.UnlinkContact(jobID, contactID)
+	if err != nil || !unlinked {
+		return unlinked, err
+	}
+	return true, s.save(fmt.Sprintf("Unlink contact %d from %s", contactID, s.describeJob(jobID)))
+}
+
+func (s *GitStore) AddInterview(interview *Interview) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refresh(); err != nil {
+		return err
+	}
+	if err := s.SQLStore.AddInterview(interview); err != nil {
+		return err
+	}
+	return s.save(fmt.Sprintf("Add %s interview for %s", interview.Round, s.describeJob(interview.JobID)))
+}
+
+func (s *GitStore) UpdateInterview(id int, updates UpdatedInterviewParams) (*Interview, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refresh(); err != nil {
+		return nil, err
+	}
+	interview, err := s.SQLStore.UpdateInterview(id, updates)
+	if err != nil || interview == nil {
+		return interview, err
+	}
+	return interview, s.save(fmt.Sprintf("Update %s interview for %s", interview.Round, s.describeJob(interview.JobID)))
+}
+
+func (s *GitStore) DeleteInterview(id int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refresh(); err != nil {
+		return false, err
+	}
+	deleted, err := s.SQLStore.DeleteInterview(id)
+	if err != nil || !deleted {
+		return deleted, err
+	}
+	return true, s.save(fmt.Sprintf("Delete interview %d", id))
+}
+
+func (s *GitStore) AddOffer(offer *Offer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refresh(); err != nil {
+		return err
+	}
+	if err := s.SQLStore.AddOffer(offer); err != nil {
+		return err
+	}
+	return s.save(fmt.Sprintf("Add offer for %s", s.describeJob(offer.JobID)))
+}
+
+func (s *GitStore) UpdateCompany(id int, updates UpdatedCompanyParams) (*Company, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refresh(); err != nil {
+		return nil, err
+	}
+	company, err := s.SQLStore.UpdateCompany(id, updates)
+	if err != nil || company == nil {
+		return company, err
+	}
+	return company, s.save("Update company " + company.Name)
+}
+
+func (s *GitStore) RenameCompany(id int, name string) (*Company, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refresh(); err != nil {
+		return nil, err
+	}
+	before, err := GetCompanyByID(s.db, id)
+	if err != nil || before == nil {
+		return nil, err
+	}
+	company, err := s.SQLStore.RenameCompany(id, name)
+	if err != nil || company == nil {
+		return company, err
+	}
+	return company, s.save(fmt.Sprintf("Rename company %s to %s", before.Name, company.Name))
+}
+
+func (s *GitStore) MergeCompanies(fromID int, intoID int) (*Company, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refresh(); err != nil {
+		return nil, err
+	}
+	from, err := GetCompanyByID(s.db, fromID)
+	if err != nil || from == nil {
+		return nil, err
+	}
+	company, err := s.SQLStore.MergeCompanies(fromID, intoID)
+	if err != nil || company == nil {
+		return company, err
+	}
+	return company, s.save(fmt.Sprintf("Merge company %s into %s", from.Name, company.Name))
+}
+
+func (s *GitStore) Sync(dir string) (*SyncResult, error) {
+	return nil, ErrSyncNotSupported
+}
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// StatusEvent records a single change of a job's status.
+type StatusEvent struct {
+	ID        int        `json:"id"`
+	JobID     int        `json:"job_id"`
+	OldStatus NullString `json:"old_status"`
+	NewStatus JobStatus  `json:"new_status"`
+	Note      NullString `json:"note"`
+	ChangedAt *time.Time `json:"changed_at"`
+}
+
+// recordStatusEvent stores a status transition as part of the given transaction.
+// oldStatus is null for the first status a job is created with.
+func recordStatusEvent(tx *sql.Tx, jobID int, oldStatus NullString, newStatus JobStatus, note NullString) error {
+	const insertQuery = `INSERT INTO status_events
+		(job_id, old_status, new_status, note)
+		VALUES
+		(?, ?, ?, ?);`
+	_, err := tx.Exec(
+		insertQuery,
+		jobID,
+		oldStatus,
+		newStatus,
+		note,
+	)
+	return err
+}
+
+// GetStatusHistory returns every status transition of a job, oldest first.
+func GetStatusHistory(sqliteDB *sql.DB, jobID int) ([]*StatusEvent, error) {
+	const selectQuery = `SELECT
+		id, job_id, old_status, new_status, note, changed_at
+		FROM status_events WHERE job_id = ? ORDER BY changed_at ASC, id ASC;`
+
+	rows, err := sqliteDB.Query(selectQuery, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var events []*StatusEvent
+	for rows.Next() {
+		var event StatusEvent
+		var changedAt string
+		err := rows.Scan(
+			&event.ID,
+			&event.JobID,
+			&event.OldStatus,
+			&event.NewStatus,
+			&event.Note,
+			&changedAt,
+		)
+		if err != nil {
+			return events, err
+		}
+		event.ChangedAt, _ = ParseDateTime(changedAt, false)
+		events = append(events, &event)
+	}
+	if err := rows.Err(); err != nil {
+		return events, err
+	}
+	return events, nil
+}
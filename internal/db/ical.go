@@ -0,0 +1,81 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// icsTimeFormat is the UTC date-time format of iCalendar files.
+const icsTimeFormat = "20060102T150405Z"
+
+// defaultInterviewLength is used as the duration of interviews without an end time.
+const defaultInterviewLength = time.Hour
+
+// ToICS marshals an interview into an iCalendar VEVENT. The UID is derived from the
+// interview UUID, which unlike its ID is unique across profiles and never changes, so
+// calendar clients update the event instead of duplicating or overwriting another.
+func (i *Interview) ToICS() string {
+	var b strings.Builder
+	write := func(line string) {
+		b.WriteString(foldContentLine(line))
+	}
+	endsAt := i.StartsAt.Add(defaultInterviewLength)
+	if i.EndsAt != nil {
+		endsAt = *i.EndsAt
+	}
+	stamp := time.Now().UTC()
+	if i.UpdatedAt != nil {
+		stamp = *i.UpdatedAt
+	}
+	var description []string
+	description = append(description, fmt.Sprintf("%s at %s", i.Position, i.Company))
+	if i.Interviewers.Valid {
+		description = append(description, "Interviewers: "+i.Interviewers.String)
+	}
+	if i.VideoLink.Valid {
+		description = append(description, "Video link: "+i.VideoLink.String)
+	}
+	if i.Outcome.Valid {
+		description = append(description, "Outcome: "+i.Outcome.String)
+	}
+
+	write("BEGIN:VEVENT")
+	write(fmt.Sprintf("UID:interview-%s@jobtrack", i.UUID))
+	write("DTSTAMP:" + stamp.UTC().Format(icsTimeFormat))
+	write("DTSTART:" + i.StartsAt.UTC().Format(icsTimeFormat))
+	write("DTEND:" + endsAt.UTC().Format(icsTimeFormat))
+	write("SUMMARY:" + textEscaper.Replace(fmt.Sprintf("%s - %s (%s)", i.Round, i.Company, i.Position)))
+	write("DESCRIPTION:" + textEscaper.Replace(strings.Join(description, "\n")))
+	switch {
+	case i.Location.Valid:
+		write("LOCATION:" + textEscaper.Replace(i.Location.String))
+	case i.VideoLink.Valid:
+		write("LOCATION:" + textEscaper.Replace(i.VideoLink.String))
+	}
+	if i.VideoLink.Valid {
+		write("URL:" + i.VideoLink.String)
+	}
+	if strings.EqualFold(i.Outcome.String, "Cancelled") {
+		write("STATUS:CANCELLED")
+	} else {
+		write("STATUS:CONFIRMED")
+	}
+	write("END:VEVENT")
+	return b.String()
+}
+
+// ToICS marshals interviews into an RFC 5545 calendar.
+func (interviews Interviews) ToICS() string {
+	var b strings.Builder
+	b.WriteString(foldContentLine("BEGIN:VCALENDAR"))
+	b.WriteString(foldContentLine("VERSION:2.0"))
+	b.WriteString(foldContentLine("PRODID:-//jobtrack//Interviews//EN"))
+	b.WriteString(foldContentLine("CALSCALE:GREGORIAN"))
+	b.WriteString(foldContentLine("X-WR-CALNAME:Job Interviews"))
+	for _, interview := range interviews {
+		b.WriteString(interview.ToICS())
+	}
+	b.WriteString(foldContentLine("END:VCALENDAR"))
+	return b.String()
+}
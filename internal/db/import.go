@@ -0,0 +1,383 @@
+package db
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ImportRow is a job read from an import file together with the line it starts on. Err
+// holds the reasons the row cannot be imported, if any.
+type ImportRow struct {
+	Line int
+	Job  *Job
+	Err  error
+	// hasStatus records whether the row gave a status before validation filled in the
+	// initial status, so updating a duplicate does not reset its status.
+	hasStatus bool
+}
+
+// ImportOptions control how ImportJobs writes rows.
+type ImportOptions struct {
+	// ContinueOnError imports the valid rows even if others fail. Otherwise a single
+	// failing row means nothing is imported.
+	ContinueOnError bool
+	// DryRun checks every row, including against the database, without keeping any.
+	DryRun bool
+	// MatchKey decides which jobs are duplicates, it defaults to DefaultMatchKey.
+	MatchKey MatchKey
+	// OnConflict decides what happens to duplicates, it defaults to ConflictSkip.
+	OnConflict ConflictAction
+	// Ask is called for each duplicate when OnConflict is ConflictAsk, and returns what
+	// to do with it.
+	Ask func(row *ImportRow, existing *Job) ConflictAction
+}
+
+// ImportResult reports the outcome of an import.
+type ImportResult struct {
+	Added   int
+	Updated int
+	// Skipped counts the duplicates that were not imported.
+	Skipped int
+	Failed  []*ImportRow
+}
+
+// minCSVColumns is the number of columns in files exported before follow-ups existed,
+// which had no FollowUpOn or UUID columns.
+const minCSVColumns = 9
+
+// csvColumnAliases maps other common names of columns to those of csvHeader.
+var csvColumnAliases = map[string]string{
+	"url":        "jobpostingurl",
+	"postingurl": "jobpostingurl",
+	"joburl":     "jobpostingurl",
+	"salary":     "salaryrange",
+	"applied":    "appliedat",
+	"appliedon":  "appliedat",
+	"followup":   "followupon",
+}
+
+// csvColumnKey normalises a CSV header so that "AppliedAt", "applied_at" and
+// "Applied On" name the same column.
+func csvColumnKey(name string) string {
+	name = strings.TrimPrefix(name, "\ufeff")
+	key := strings.ToLower(strings.NewReplacer("_", "", " ", "", "-", "").Replace(strings.TrimSpace(name)))
+	if alias, ok := csvColumnAliases[key]; ok {
+		return alias
+	}
+	return key
+}
+
+// isCSVHeader reports whether a row is a header rather than a job, that is whether it
+// names both the Company and Position columns.
+func isCSVHeader(row []string) bool {
+	var company, position bool
+	for _, cell := range row {
+		switch csvColumnKey(cell) {
+		case "company":
+			company = true
+		case "position":
+			position = true
+		}
+	}
+	return company && position
+}
+
+// ReadCSV reads jobs from a CSV file. A header row is optional: with one, columns are
+// matched by name and may come in any order, and columns that are not built-in are read
+// as custom fields. Without one they must be in the order written by Jobs.ToCSV, with no
+// custom fields. Rows that cannot be parsed are returned with their error set,
+// an error is only returned if the file itself cannot be read.
+func ReadCSV(r io.Reader) ([]*ImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	var rows []*ImportRow
+	var columns map[string]int
+	var fields map[string]int
+	width := 0
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return rows, err
+		}
+		line, _ := reader.FieldPos(0)
+		if first {
+			// spreadsheet programs often start UTF-8 files with a byte order mark
+			record[0] = strings.TrimPrefix(record[0], "\ufeff")
+		}
+		if first && isCSVHeader(record) {
+			columns = make(map[string]int, len(record))
+			fields = make(map[string]int)
+			builtIn := positionalColumns()
+			for i, name := range record {
+				key := csvColumnKey(name)
+				if _, ok := builtIn[key]; ok {
+					columns[key] = i
+				} else if field, err := NormalizeFieldName(name); err == nil {
+					fields[field] = i
+				}
+			}
+			width = len(record)
+			continue
+		}
+		row := &ImportRow{Line: line}
+		switch {
+		case columns != nil && len(record) != width:
+			row.Err = fmt.Errorf("Expected %d columns like the header, found %d", width, len(record))
+		case columns == nil && (len(record) < minCSVColumns || len(record) > len(csvHeader)):
+			row.Err = fmt.Errorf(
+				"Expected between %d and %d columns, found %d",
+				minCSVColumns,
+				len(csvHeader),
+				len(record),
+			)
+		case columns == nil:
+			row.Job, row.Err = jobFromCSV(record, positionalColumns(), nil)
+		default:
+			row.Job, row.Err = jobFromCSV(record, columns, fields)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// positionalColumns maps the normalised column names to their index in files without a
+// header, whose columns are in the order of csvHeader.
+func positionalColumns() map[string]int {
+	columns := make(map[string]int, len(csvHeader))
+	for i, name := range csvHeader {
+		columns[csvColumnKey(name)] = i
+	}
+	return columns
+}
+
+// jobFromCSV parses a CSV row. columns maps the normalised column names to their index,
+// and fields the names of custom fields to theirs.
+func jobFromCSV(record []string, columns map[string]int, fields map[string]int) (*Job, error) {
+	value := func(name string) string {
+		i, ok := columns[csvColumnKey(name)]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	var errs ValidationError
+	date := func(name string, dateOnly bool) *time.Time {
+		s := value(name)
+		if s == "" {
+			return nil
+		}
+		t, err := ParseDateTime(s, dateOnly)
+		if err != nil {
+			format := "YYYY-MM-DD HH:MM:SS"
+			if dateOnly {
+				format = "YYYY-MM-DD"
+			}
+			errs = append(errs, FieldError{
+				Field:   name,
+				Message: fmt.Sprintf("%s %q is not formatted %s", name, s, format),
+			})
+		}
+		return t
+	}
+	job := Job{
+		Company:       value("Company"),
+		Position:      value("Position"),
+		Status:        JobStatus(value("Status")),
+		Location:      emptyToNull(value("Location")),
+		SalaryRange:   emptyToNull(value("SalaryRange")),
+		JobPostingURL: emptyToNull(value("JobPostingURL")),
+		AppliedAt:     date("AppliedAt", true),
+		CreatedAt:     date("CreatedAt", false),
+		UpdatedAt:     date("UpdatedAt", false),
+		FollowUpOn:    date("FollowUpOn", true),
+		UUID:          value("UUID"),
+	}
+	if tags, err := ParseTags(value("Tags")); err != nil {
+		errs = append(errs, FieldError{Field: "Tags", Message: err.Error()})
+	} else {
+		job.Tags = tags
+	}
+	for name, i := range fields {
+		if value := strings.TrimSpace(record[i]); value != "" {
+			if job.Fields == nil {
+				job.Fields = make(map[string]string)
+			}
+			job.Fields[name] = value
+		}
+	}
+	if len(errs) > 0 {
+		return &job, errs
+	}
+	return &job, nil
+}
+
+// ReadJSON reads jobs from a JSON array as written by jobtrack export. Each job is
+// decoded separately so the line it starts on can be reported.
+func ReadJSON(data []byte) ([]*ImportRow, error) {
+	lineAt := func(offset int64) int {
+		// the decoder's offset is just past the previous token, skip to the job itself
+		for offset < int64(len(data)) && strings.IndexByte(" \t\r\n,", data[offset]) >= 0 {
+			offset++
+		}
+		return bytes.Count(data[:offset], []byte("\n")) + 1
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, fmt.Errorf("Error reading JSON: %w", err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return nil, errors.New("Error reading JSON: expected an array of jobs")
+	}
+	var rows []*ImportRow
+	for decoder.More() {
+		line := lineAt(decoder.InputOffset())
+		var job Job
+		if err := decoder.Decode(&job); err != nil {
+			return rows, fmt.Errorf("Line %d: Error reading JSON: %w", line, err)
+		}
+		rows = append(rows, &ImportRow{Line: line, Job: &job})
+	}
+	return rows, nil
+}
+
+// validateUUID checks the UUID of an imported job, spelling it in canonical form.
+func validateUUID(job *Job) error {
+	if job.UUID == "" {
+		return nil
+	}
+	parsed, err := uuid.Parse(job.UUID)
+	if err != nil {
+		return ValidationError{{Field: "uuid", Message: fmt.Sprintf("UUID %q is not valid", job.UUID)}}
+	}
+	job.UUID = parsed.String()
+	return nil
+}
+
+// joinValidationErrors combines the problems found while parsing a row with those found
+// validating it, so every problem of the row is reported at once.
+func joinValidationErrors(parseErr error, validateErr error) error {
+	if validateErr == nil {
+		return parseErr
+	}
+	if parseErr == nil {
+		return validateErr
+	}
+	var parseErrs, validateErrs ValidationError
+	if errors.As(parseErr, &parseErrs) && errors.As(validateErr, &validateErrs) {
+		return append(parseErrs, validateErrs...)
+	}
+	return parseErr
+}
+
+// ImportJobs validates rows and adds the jobs in them in a single transaction. Jobs that
+// duplicate an existing job, including one added earlier in the same import, are handled
+// as options.OnConflict says. Rows that fail are returned in the result. Unless
+// options.ContinueOnError is set, nothing is imported if any row fails.
+func ImportJobs(sqliteDB *sql.DB, rows []*ImportRow, options ImportOptions) (*ImportResult, error) {
+	result := &ImportResult{}
+	for _, row := range rows {
+		if row.Job != nil {
+			row.hasStatus = row.Job.Status != ""
+			row.Err = joinValidationErrors(row.Err, validateUUID(row.Job))
+			row.Err = joinValidationErrors(row.Err, ValidateJob(row.Job))
+		}
+		if row.Err != nil {
+			result.Failed = append(result.Failed, row)
+		}
+	}
+	if len(result.Failed) > 0 && !options.ContinueOnError {
+		return result, nil
+	}
+
+	key := options.MatchKey
+	if len(key) == 0 {
+		key = DefaultMatchKey
+	}
+	onConflict := options.OnConflict
+	if onConflict == "" {
+		onConflict = ConflictSkip
+	}
+
+	tx, err := sqliteDB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	duplicates, err := newDuplicateIndex(tx, key)
+	if err != nil {
+		return nil, err
+	}
+	var counts ImportResult
+	for _, row := range rows {
+		if row.Err != nil {
+			continue
+		}
+		action := ConflictDuplicate
+		existing := duplicates.find(row.Job)
+		if existing != nil {
+			action = onConflict
+			if action == ConflictAsk {
+				action = options.Ask(row, existing)
+			}
+		}
+		if action == ConflictSkip {
+			counts.Skipped++
+			continue
+		}
+		if action == ConflictDuplicate && existing != nil && existing.UUID == row.Job.UUID {
+			row.Job.UUID = ""
+		}
+		// a savepoint per row undoes a partly written job, such as one whose notes fail
+		if _, err := tx.Exec(`SAVEPOINT import_row;`); err != nil {
+			return nil, err
+		}
+		var job *Job
+		if action == ConflictUpdate {
+			job, err = mergeJob(tx, existing, row)
+		} else {
+			job, err = row.Job, insertJob(tx, row.Job)
+		}
+		if err != nil {
+			if _, rollbackErr := tx.Exec(`ROLLBACK TO import_row;`); rollbackErr != nil {
+				return nil, rollbackErr
+			}
+			row.Err = err
+			result.Failed = append(result.Failed, row)
+			if !options.ContinueOnError {
+				return result, nil
+			}
+		} else if action == ConflictUpdate {
+			counts.Updated++
+		} else {
+			counts.Added++
+			duplicates.add(job)
+		}
+		if _, err := tx.Exec(`RELEASE import_row;`); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(result.Failed, func(i, j int) bool {
+		return result.Failed[i].Line < result.Failed[j].Line
+	})
+	if !options.DryRun {
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+	}
+	result.Added, result.Updated, result.Skipped = counts.Added, counts.Updated, counts.Skipped
+	return result, nil
+}
@@ -0,0 +1,241 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Interview is a single interview round of a job application. Times are stored in UTC,
+// TimeZone holds the IANA name of the zone the interview was scheduled in, if any.
+type Interview struct {
+	ID           int        `json:"id" db:"id"`
+	UUID         string     `json:"uuid" db:"uuid"`
+	JobID        int        `json:"job_id" db:"job_id"`
+	Round        string     `json:"round" db:"round"`
+	StartsAt     *time.Time `json:"starts_at" db:"starts_at"`
+	EndsAt       *time.Time `json:"ends_at" db:"ends_at"`
+	TimeZone     NullString `json:"time_zone" db:"time_zone"`
+	Location     NullString `json:"location" db:"location"`
+	VideoLink    NullString `json:"video_link" db:"video_link"`
+	Interviewers NullString `json:"interviewers" db:"interviewers"`
+	Outcome      NullString `json:"outcome" db:"outcome"`
+	CreatedAt    *time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    *time.Time `json:"updated_at" db:"updated_at"`
+	// Company and Position describe the job the interview belongs to.
+	Company  string `json:"company" db:"-"`
+	Position string `json:"position" db:"-"`
+}
+
+type Interviews []*Interview
+
+// UpdatedInterviewParams holds the interview fields to change, nil fields are left untouched.
+type UpdatedInterviewParams struct {
+	Round        *string
+	StartsAt     *time.Time
+	EndsAt       *time.Time
+	TimeZone     *string
+	Location     *string
+	VideoLink    *string
+	Interviewers *string
+	Outcome      *string
+}
+
+// InterviewOutcomes are suggested values for the outcome of an interview.
+var InterviewOutcomes = []string{"Pending", "Passed", "Failed", "Cancelled"}
+
+const interviewSelect = `SELECT
+	interviews.id, interviews.uuid, interviews.job_id, interviews.round, interviews.starts_at, interviews.ends_at,
+	interviews.time_zone, interviews.location, interviews.video_link, interviews.interviewers,
+	interviews.outcome, interviews.created_at, interviews.updated_at, jobs.company, jobs.position
+	FROM interviews JOIN jobs ON jobs.id = interviews.job_id`
+
+// Zone returns the time zone the interview was scheduled in, falling back to the
+// local time zone.
+func (i *Interview) Zone() *time.Location {
+	if i.TimeZone.Valid {
+		if loc, err := time.LoadLocation(i.TimeZone.String); err == nil {
+			return loc
+		}
+	}
+	return time.Local
+}
+
+func formatUTC(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return FormatDateTime(t.UTC(), false)
+}
+
+func getInterviews(sqliteDB *sql.DB, query string, params ...any) (Interviews, error) {
+	rows, err := sqliteDB.Query(query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var interviews Interviews
+	for rows.Next() {
+		var interview Interview
+		var startsAt, createdAt, updatedAt string
+		var endsAt sql.NullString
+		err := rows.Scan(
+			&interview.ID,
+			&interview.UUID,
+			&interview.JobID,
+			&interview.Round,
+			&startsAt,
+			&endsAt,
+			&interview.TimeZone,
+			&interview.Location,
+			&interview.VideoLink,
+			&interview.Interviewers,
+			&interview.Outcome,
+			&createdAt,
+			&updatedAt,
+			&interview.Company,
+			&interview.Position,
+		)
+		if err != nil {
+			return interviews, err
+		}
+		interview.StartsAt, _ = ParseDateTime(startsAt, false)
+		if endsAt.Valid {
+			interview.EndsAt, _ = ParseDateTime(endsAt.String, false)
+		}
+		interview.CreatedAt, _ = ParseDateTime(createdAt, false)
+		interview.UpdatedAt, _ = ParseDateTime(updatedAt, false)
+		interviews = append(interviews, &interview)
+	}
+	if err := rows.Err(); err != nil {
+		return interviews, err
+	}
+	return interviews, nil
+}
+
+// validateInterviewTimes checks that an interview does not end before it starts.
+func validateInterviewTimes(startsAt *time.Time, endsAt *time.Time) error {
+	if startsAt != nil && endsAt != nil && endsAt.Before(*startsAt) {
+		return fmt.Errorf("The interview cannot end before it starts")
+	}
+	return nil
+}
+
+// AddInterview stores a new interview round and sets its ID and UUID.
+func AddInterview(sqliteDB *sql.DB, interview *Interview) error {
+	const insertQuery = `INSERT INTO interviews
+		(job_id, round, starts_at, ends_at, time_zone, location, video_link, interviewers, outcome, uuid)
+		VALUES
+		(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id;`
+	if interview.StartsAt == nil {
+		return fmt.Errorf("The interview start time is required")
+	}
+	if err := validateInterviewTimes(interview.StartsAt, interview.EndsAt); err != nil {
+		return err
+	}
+	interview.UUID = uuid.NewString()
+	return sqliteDB.QueryRow(
+		insertQuery,
+		interview.JobID,
+		interview.Round,
+		formatUTC(interview.StartsAt),
+		formatUTC(interview.EndsAt),
+		interview.TimeZone,
+		interview.Location,
+		interview.VideoLink,
+		interview.Interviewers,
+		interview.Outcome,
+		interview.UUID,
+	).Scan(&interview.ID)
+}
+
+// GetInterviewByID returns an interview, or nil if there is no interview with that ID.
+func GetInterviewByID(sqliteDB *sql.DB, id int) (*Interview, error) {
+	interviews, err := getInterviews(sqliteDB, interviewSelect+` WHERE interviews.id = ?;`, id)
+	if err != nil || len(interviews) == 0 {
+		return nil, err
+	}
+	return interviews[0], nil
+}
+
+// GetInterviews returns every interview in chronological order, only those of one job
+// if jobID is not -1.
+func GetInterviews(sqliteDB *sql.DB, jobID int) (Interviews, error) {
+	var b queryBuilder
+	if jobID != -1 {
+		b.where("interviews.job_id = ?", jobID)
+	}
+	query := interviewSelect + b.clause() + ` ORDER BY interviews.starts_at ASC, interviews.id ASC;`
+	return getInterviews(sqliteDB, query, b.params...)
+}
+
+// GetUpcomingInterviews returns the interviews starting between now and the given
+// number of days from now, soonest first.
+func GetUpcomingInterviews(sqliteDB *sql.DB, days int) (Interviews, error) {
+	now := time.Now()
+	until := now.AddDate(0, 0, days)
+	query := interviewSelect + ` WHERE interviews.starts_at >= ? AND interviews.starts_at <= ?
+		ORDER BY interviews.starts_at ASC, interviews.id ASC;`
+	return getInterviews(sqliteDB, query, formatUTC(&now), formatUTC(&until))
+}
+
+// UpdateInterview changes the given fields of an interview. It returns nil if there is no
+// interview with that ID.
+func UpdateInterview(sqliteDB *sql.DB, id int, updates UpdatedInterviewParams) (*Interview, error) {
+	const updateQuery = `UPDATE interviews
+		SET
+		round = COALESCE(?, round),
+		starts_at = COALESCE(?, starts_at),
+		ends_at = COALESCE(?, ends_at),
+		time_zone = COALESCE(?, time_zone),
+		location = COALESCE(?, location),
+		video_link = COALESCE(?, video_link),
+		interviewers = COALESCE(?, interviewers),
+		outcome = COALESCE(?, outcome),
+		updated_at = ?
+		WHERE id = ?;`
+	current, err := GetInterviewByID(sqliteDB, id)
+	if err != nil || current == nil {
+		return nil, err
+	}
+	startsAt, endsAt := current.StartsAt, current.EndsAt
+	if updates.StartsAt != nil {
+		startsAt = updates.StartsAt
+	}
+	if updates.EndsAt != nil {
+		endsAt = updates.EndsAt
+	}
+	if err := validateInterviewTimes(startsAt, endsAt); err != nil {
+		return nil, err
+	}
+	_, err = sqliteDB.Exec(
+		updateQuery,
+		toSQLValue(updates.Round),
+		formatUTC(updates.StartsAt),
+		formatUTC(updates.EndsAt),
+		toSQLValue(updates.TimeZone),
+		toSQLValue(updates.Location),
+		toSQLValue(updates.VideoLink),
+		toSQLValue(updates.Interviewers),
+		toSQLValue(updates.Outcome),
+		currentTimestamp(),
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return GetInterviewByID(sqliteDB, id)
+}
+
+// DeleteInterview removes an interview, reporting whether it existed.
+func DeleteInterview(sqliteDB *sql.DB, id int) (bool, error) {
+	result, err := sqliteDB.Exec(`DELETE FROM interviews WHERE id = ?;`, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
@@ -0,0 +1,624 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// migration is a single, ordered change to the database schema. Migrations are
+// only ever applied upwards, each one inside its own transaction. up is written for
+// SQLite; postgres is the same change for PostgreSQL, or nil when up works for both.
+type migration struct {
+	version     int
+	description string
+	up          func(tx *sql.Tx) error
+	postgres    func(tx *sql.Tx) error
+}
+
+// PostgreSQL columns default to the current UTC time and date formatted as text, which
+// is how SQLite's CURRENT_TIMESTAMP and DATE('now') store them.
+const (
+	postgresNow   = `to_char(CURRENT_TIMESTAMP AT TIME ZONE 'UTC', 'YYYY-MM-DD HH24:MI:SS')`
+	postgresToday = `to_char(CURRENT_TIMESTAMP AT TIME ZONE 'UTC', 'YYYY-MM-DD')`
+)
+
+// migrationLockID identifies the PostgreSQL advisory lock held while migrating, so
+// clients sharing a database never apply the same migration twice.
+const migrationLockID = 7504
+
+// MigrationStatus describes whether a known migration has been applied to the database.
+type MigrationStatus struct {
+	Version     int
+	Description string
+	AppliedAt   *time.Time
+}
+
+// migrations holds every schema change in the order it must be applied. New
+// migrations are appended to the end with the next version number; existing
+// entries must never be edited once released.
+var migrations = []migration{
+	{
+		version:     1,
+		description: "create jobs table",
+		up: execStatements(`CREATE TABLE IF NOT EXISTS jobs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			company TEXT NOT NULL,
+			position TEXT NOT NULL,
+			status TEXT NOT NULL,
+			location TEXT,
+			applied_at TEXT NOT NULL DEFAULT (DATE('now')),
+			salary_range TEXT,
+			job_posting_url TEXT,
+			created_at TEXT NOT NULL DEFAULT (CURRENT_TIMESTAMP),
+			updated_at TEXT NOT NULL DEFAULT (CURRENT_TIMESTAMP)
+		);`),
+		postgres: execStatements(`CREATE TABLE IF NOT EXISTS jobs (
+			id SERIAL PRIMARY KEY,
+			company TEXT NOT NULL,
+			position TEXT NOT NULL,
+			status TEXT NOT NULL,
+			location TEXT,
+			applied_at TEXT NOT NULL DEFAULT ` + postgresToday + `,
+			salary_range TEXT,
+			job_posting_url TEXT,
+			created_at TEXT NOT NULL DEFAULT ` + postgresNow + `,
+			updated_at TEXT NOT NULL DEFAULT ` + postgresNow + `
+		);`),
+	},
+	{
+		version:     2,
+		description: "create status_events table",
+		up: execStatements(
+			`CREATE TABLE status_events (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				job_id INTEGER NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+				old_status TEXT,
+				new_status TEXT NOT NULL,
+				note TEXT,
+				changed_at TEXT NOT NULL DEFAULT (CURRENT_TIMESTAMP)
+			);`,
+			`CREATE INDEX idx_status_events_job_id ON status_events (job_id);`,
+			// Jobs created before history was tracked start with their current status.
+			`INSERT INTO status_events (job_id, old_status, new_status, changed_at)
+				SELECT id, NULL, status, created_at FROM jobs;`,
+		),
+		postgres: execStatements(
+			`CREATE TABLE status_events (
+				id SERIAL PRIMARY KEY,
+				job_id INTEGER NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+				old_status TEXT,
+				new_status TEXT NOT NULL,
+				note TEXT,
+				changed_at TEXT NOT NULL DEFAULT `+postgresNow+`
+			);`,
+			`CREATE INDEX idx_status_events_job_id ON status_events (job_id);`,
+			`INSERT INTO status_events (job_id, old_status, new_status, changed_at)
+				SELECT id, NULL, status, created_at FROM jobs;`,
+		),
+	},
+	{
+		version:     3,
+		description: "create notes table",
+		up: execStatements(
+			`CREATE TABLE notes (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				job_id INTEGER NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+				body TEXT NOT NULL,
+				created_at TEXT NOT NULL DEFAULT (CURRENT_TIMESTAMP),
+				updated_at TEXT NOT NULL DEFAULT (CURRENT_TIMESTAMP)
+			);`,
+			`CREATE INDEX idx_notes_job_id ON notes (job_id);`,
+		),
+		postgres: execStatements(
+			`CREATE TABLE notes (
+				id SERIAL PRIMARY KEY,
+				job_id INTEGER NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+				body TEXT NOT NULL,
+				created_at TEXT NOT NULL DEFAULT `+postgresNow+`,
+				updated_at TEXT NOT NULL DEFAULT `+postgresNow+`
+			);`,
+			`CREATE INDEX idx_notes_job_id ON notes (job_id);`,
+		),
+	},
+	{
+		version:     4,
+		description: "create contacts and job_contacts tables",
+		up: execStatements(
+			`CREATE TABLE contacts (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				name TEXT NOT NULL,
+				role TEXT,
+				email TEXT,
+				phone TEXT,
+				linkedin_url TEXT,
+				company TEXT,
+				created_at TEXT NOT NULL DEFAULT (CURRENT_TIMESTAMP),
+				updated_at TEXT NOT NULL DEFAULT (CURRENT_TIMESTAMP)
+			);`,
+			`CREATE TABLE job_contacts (
+				job_id INTEGER NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+				contact_id INTEGER NOT NULL REFERENCES contacts (id) ON DELETE CASCADE,
+				PRIMARY KEY (job_id, contact_id)
+			);`,
+			`CREATE INDEX idx_job_contacts_contact_id ON job_contacts (contact_id);`,
+		),
+		postgres: execStatements(
+			`CREATE TABLE contacts (
+				id SERIAL PRIMARY KEY,
+				name TEXT NOT NULL,
+				role TEXT,
+				email TEXT,
+				phone TEXT,
+				linkedin_url TEXT,
+				company TEXT,
+				created_at TEXT NOT NULL DEFAULT `+postgresNow+`,
+				updated_at TEXT NOT NULL DEFAULT `+postgresNow+`
+			);`,
+			`CREATE TABLE job_contacts (
+				job_id INTEGER NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+				contact_id INTEGER NOT NULL REFERENCES contacts (id) ON DELETE CASCADE,
+				PRIMARY KEY (job_id, contact_id)
+			);`,
+			`CREATE INDEX idx_job_contacts_contact_id ON job_contacts (contact_id);`,
+		),
+	},
+	{
+		version:     5,
+		description: "create interviews table",
+		up: execStatements(
+			`CREATE TABLE interviews (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				job_id INTEGER NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+				round TEXT NOT NULL,
+				starts_at TEXT NOT NULL,
+				ends_at TEXT,
+				time_zone TEXT,
+				location TEXT,
+				video_link TEXT,
+				interviewers TEXT,
+				outcome TEXT,
+				created_at TEXT NOT NULL DEFAULT (CURRENT_TIMESTAMP),
+				updated_at TEXT NOT NULL DEFAULT (CURRENT_TIMESTAMP)
+			);`,
+			`CREATE INDEX idx_interviews_job_id ON interviews (job_id);`,
+			`CREATE INDEX idx_interviews_starts_at ON interviews (starts_at);`,
+		),
+		postgres: execStatements(
+			`CREATE TABLE interviews (
+				id SERIAL PRIMARY KEY,
+				job_id INTEGER NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+				round TEXT NOT NULL,
+				starts_at TEXT NOT NULL,
+				ends_at TEXT,
+				time_zone TEXT,
+				location TEXT,
+				video_link TEXT,
+				interviewers TEXT,
+				outcome TEXT,
+				created_at TEXT NOT NULL DEFAULT `+postgresNow+`,
+				updated_at TEXT NOT NULL DEFAULT `+postgresNow+`
+			);`,
+			`CREATE INDEX idx_interviews_job_id ON interviews (job_id);`,
+			`CREATE INDEX idx_interviews_starts_at ON interviews (starts_at);`,
+		),
+	},
+	{
+		version:     6,
+		description: "add follow_up_on to jobs",
+		up:          execStatements(`ALTER TABLE jobs ADD COLUMN follow_up_on TEXT;`),
+	},
+	{
+		version:     7,
+		description: "add uuid to jobs",
+		up:          addUUIDs("jobs"),
+	},
+	{
+		version:     8,
+		description: "create sync tables",
+		up: execStatements(
+			// the values of each job's fields as of the last sync, and when they changed
+			`CREATE TABLE sync_state (
+				job_uuid TEXT NOT NULL,
+				field TEXT NOT NULL,
+				value TEXT NOT NULL,
+				updated_at TEXT NOT NULL,
+				PRIMARY KEY (job_uuid, field)
+			);`,
+			// this device, and how far the change log of every other device has been read
+			`CREATE TABLE sync_devices (
+				id TEXT PRIMARY KEY,
+				log_id TEXT,
+				last_seq INTEGER NOT NULL DEFAULT 0,
+				local INTEGER NOT NULL DEFAULT 0
+			);`,
+			`CREATE TABLE deleted_jobs (
+				uuid TEXT PRIMARY KEY,
+				deleted_at TEXT NOT NULL DEFAULT (CURRENT_TIMESTAMP),
+				synced INTEGER NOT NULL DEFAULT 0
+			);`,
+		),
+		postgres: execStatements(
+			`CREATE TABLE sync_state (
+				job_uuid TEXT NOT NULL,
+				field TEXT NOT NULL,
+				value TEXT NOT NULL,
+				updated_at TEXT NOT NULL,
+				PRIMARY KEY (job_uuid, field)
+			);`,
+			`CREATE TABLE sync_devices (
+				id TEXT PRIMARY KEY,
+				log_id TEXT,
+				last_seq INTEGER NOT NULL DEFAULT 0,
+				local INTEGER NOT NULL DEFAULT 0
+			);`,
+			`CREATE TABLE deleted_jobs (
+				uuid TEXT PRIMARY KEY,
+				deleted_at TEXT NOT NULL DEFAULT `+postgresNow+`,
+				synced INTEGER NOT NULL DEFAULT 0
+			);`,
+		),
+	},
+	{
+		version:     9,
+		description: "create tags and job_tags tables",
+		up: execStatements(
+			`CREATE TABLE tags (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				name TEXT NOT NULL UNIQUE
+			);`,
+			`CREATE TABLE job_tags (
+				job_id INTEGER NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+				tag_id INTEGER NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+				PRIMARY KEY (job_id, tag_id)
+			);`,
+			`CREATE INDEX idx_job_tags_tag_id ON job_tags (tag_id);`,
+		),
+		postgres: execStatements(
+			`CREATE TABLE tags (
+				id SERIAL PRIMARY KEY,
+				name TEXT NOT NULL UNIQUE
+			);`,
+			`CREATE TABLE job_tags (
+				job_id INTEGER NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+				tag_id INTEGER NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+				PRIMARY KEY (job_id, tag_id)
+			);`,
+			`CREATE INDEX idx_job_tags_tag_id ON job_tags (tag_id);`,
+		),
+	},
+	{
+		version:     10,
+		description: "create job_fields table",
+		up: execStatements(
+			`CREATE TABLE job_fields (
+				job_id INTEGER NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+				name TEXT NOT NULL,
+				value TEXT NOT NULL,
+				PRIMARY KEY (job_id, name)
+			);`,
+			`CREATE INDEX idx_job_fields_name ON job_fields (name);`,
+		),
+	},
+	{
+		version:     11,
+		description: "add structured salary to jobs",
+		up:          addJobSalaries,
+	},
+	{
+		version:     12,
+		description: "create exchange_rates table",
+		up: execStatements(
+			`CREATE TABLE exchange_rates (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				currency TEXT NOT NULL,
+				base TEXT NOT NULL,
+				rate DOUBLE PRECISION NOT NULL,
+				effective_on TEXT NOT NULL,
+				UNIQUE (currency, base, effective_on)
+			);`,
+		),
+		postgres: execStatements(
+			`CREATE TABLE exchange_rates (
+				id SERIAL PRIMARY KEY,
+				currency TEXT NOT NULL,
+				base TEXT NOT NULL,
+				rate DOUBLE PRECISION NOT NULL,
+				effective_on TEXT NOT NULL,
+				UNIQUE (currency, base, effective_on)
+			);`,
+		),
+	},
+	{
+		version:     13,
+		description: "create offers table",
+		up: execStatements(
+			`CREATE TABLE offers (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				job_id INTEGER NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+				currency TEXT NOT NULL,
+				base DOUBLE PRECISION NOT NULL,
+				bonus DOUBLE PRECISION,
+				sign_on DOUBLE PRECISION,
+				equity DOUBLE PRECISION,
+				vesting TEXT,
+				benefits TEXT,
+				start_date TEXT,
+				deadline TEXT,
+				created_at TEXT NOT NULL DEFAULT (CURRENT_TIMESTAMP),
+				updated_at TEXT NOT NULL DEFAULT (CURRENT_TIMESTAMP)
+			);`,
+			`CREATE INDEX idx_offers_job_id ON offers (job_id);`,
+		),
+		postgres: execStatements(
+			`CREATE TABLE offers (
+				id SERIAL PRIMARY KEY,
+				job_id INTEGER NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+				currency TEXT NOT NULL,
+				base DOUBLE PRECISION NOT NULL,
+				bonus DOUBLE PRECISION,
+				sign_on DOUBLE PRECISION,
+				equity DOUBLE PRECISION,
+				vesting TEXT,
+				benefits TEXT,
+				start_date TEXT,
+				deadline TEXT,
+				created_at TEXT NOT NULL DEFAULT `+postgresNow+`,
+				updated_at TEXT NOT NULL DEFAULT `+postgresNow+`
+			);`,
+			`CREATE INDEX idx_offers_job_id ON offers (job_id);`,
+		),
+	},
+	{
+		version:     14,
+		description: "add structured location to jobs",
+		up:          addJobPlaces,
+	},
+	{
+		version:     15,
+		description: "create companies table",
+		up: addCompanies(
+			`CREATE TABLE companies (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				name TEXT NOT NULL,
+				website TEXT,
+				industry TEXT,
+				size TEXT,
+				notes TEXT,
+				created_at TEXT NOT NULL DEFAULT (CURRENT_TIMESTAMP),
+				updated_at TEXT NOT NULL DEFAULT (CURRENT_TIMESTAMP)
+			);`,
+			`CREATE UNIQUE INDEX idx_companies_name ON companies (lower(name));`,
+			`CREATE TABLE company_aliases (
+				company_id INTEGER NOT NULL REFERENCES companies (id) ON DELETE CASCADE,
+				alias TEXT NOT NULL,
+				PRIMARY KEY (company_id, alias)
+			);`,
+			`CREATE UNIQUE INDEX idx_company_aliases_alias ON company_aliases (lower(alias));`,
+		),
+		postgres: addCompanies(
+			`CREATE TABLE companies (
+				id SERIAL PRIMARY KEY,
+				name TEXT NOT NULL,
+				website TEXT,
+				industry TEXT,
+				size TEXT,
+				notes TEXT,
+				created_at TEXT NOT NULL DEFAULT `+postgresNow+`,
+				updated_at TEXT NOT NULL DEFAULT `+postgresNow+`
+			);`,
+			`CREATE UNIQUE INDEX idx_companies_name ON companies (lower(name));`,
+			`CREATE TABLE company_aliases (
+				company_id INTEGER NOT NULL REFERENCES companies (id) ON DELETE CASCADE,
+				alias TEXT NOT NULL,
+				PRIMARY KEY (company_id, alias)
+			);`,
+			`CREATE UNIQUE INDEX idx_company_aliases_alias ON company_aliases (lower(alias));`,
+		),
+	},
+	{
+		version:     16,
+		description: "add uuid to contacts and companies",
+		up:          addUUIDs("contacts", "companies"),
+	},
+	{
+		version:     17,
+		description: "add uuid to interviews",
+		up:          addUUIDs("interviews"),
+	},
+}
+
+// addUUIDs returns a migration step that adds the uuid column to each table and gives
+// every existing row a new UUID.
+func addUUIDs(tables ...string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		for _, table := range tables {
+			if _, err := tx.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN uuid TEXT;`, table)); err != nil {
+				return err
+			}
+			if err := fillUUIDs(tx, table); err != nil {
+				return err
+			}
+			if _, err := tx.Exec(fmt.Sprintf(`CREATE UNIQUE INDEX idx_%s_uuid ON %s (uuid);`, table, table)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// fillUUIDs gives the rows of table that have no UUID a new one.
+func fillUUIDs(q querier, table string) error {
+	rows, err := q.Query(fmt.Sprintf(`SELECT id FROM %s WHERE uuid IS NULL;`, table))
+	if err != nil {
+		return err
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if _, err := q.Exec(fmt.Sprintf(`UPDATE %s SET uuid = ? WHERE id = ?;`, table), uuid.NewString(), id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// execStatements returns a migration step that executes each statement in order.
+func execStatements(statements ...string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		for _, statement := range statements {
+			if _, err := tx.Exec(statement); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// LatestVersion returns the schema version this build of jobtrack knows about.
+func LatestVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+// ensureMigrationsTable creates the table used to track applied migrations.
+func ensureMigrationsTable(db *sql.DB) error {
+	tableQuery := `CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			description TEXT NOT NULL,
+			applied_at TEXT NOT NULL DEFAULT (CURRENT_TIMESTAMP)
+		);`
+	if isPostgres(db) {
+		tableQuery = `CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			description TEXT NOT NULL,
+			applied_at TEXT NOT NULL DEFAULT ` + postgresNow + `
+		);`
+	}
+	if _, err := db.Exec(tableQuery); err != nil {
+		return fmt.Errorf("Error creating migrations table: %w", err)
+	}
+	return nil
+}
+
+// SchemaVersion returns the highest migration version applied to the database,
+// or 0 for a database that has never been migrated.
+func SchemaVersion(db *sql.DB) (int, error) {
+	const versionQuery = `SELECT COALESCE(MAX(version), 0) FROM schema_migrations;`
+	var version int
+	if err := db.QueryRow(versionQuery).Scan(&version); err != nil {
+		return 0, fmt.Errorf("Error reading schema version: %w", err)
+	}
+	return version, nil
+}
+
+// MigrationStatuses lists every known migration along with when it was applied.
+// Migrations that are still pending have a nil AppliedAt.
+func MigrationStatuses(db *sql.DB) ([]MigrationStatus, error) {
+	const appliedQuery = `SELECT version, applied_at FROM schema_migrations;`
+	rows, err := db.Query(appliedQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	applied := make(map[int]*time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt string
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version], _ = ParseDateTime(appliedAt, false)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		statuses = append(statuses, MigrationStatus{
+			Version:     m.version,
+			Description: m.description,
+			AppliedAt:   applied[m.version],
+		})
+	}
+	return statuses, nil
+}
+
+// Migrate applies every pending migration up to and including the target version.
+// Each migration runs in its own transaction so a failure leaves the database at
+// the last successfully applied version. On PostgreSQL the transaction holds an
+// advisory lock, and migrations another client applied meanwhile are skipped.
+func Migrate(db *sql.DB, target int) error {
+	const recordQuery = `INSERT INTO schema_migrations (version, description) VALUES (?, ?);`
+	current, err := SchemaVersion(db)
+	if err != nil {
+		return err
+	}
+	if target > LatestVersion() || target < 0 {
+		return fmt.Errorf("Unknown schema version %d, latest is %d", target, LatestVersion())
+	}
+	if target < current {
+		return fmt.Errorf("Database is at version %d, migrating down is not supported", current)
+	}
+	postgres := isPostgres(db)
+	for _, m := range migrations {
+		if m.version <= current || m.version > target {
+			continue
+		}
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		up := m.up
+		if postgres {
+			applied, err := lockMigrations(tx, m.version)
+			if err != nil {
+				tx.Rollback()
+				return fmt.Errorf("Error locking migration %d: %w", m.version, err)
+			}
+			if applied {
+				tx.Rollback()
+				continue
+			}
+			if m.postgres != nil {
+				up = m.postgres
+			}
+		}
+		if err := up(tx); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("Error applying migration %d (%s): %w", m.version, m.description, err)
+		}
+		if _, err := tx.Exec(recordQuery, m.version, m.description); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("Error recording migration %d: %w", m.version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("Error committing migration %d: %w", m.version, err)
+		}
+	}
+	return nil
+}
+
+// lockMigrations waits for other PostgreSQL clients to finish migrating, then reports
+// whether the given version was applied in the meantime. The lock is released when
+// the transaction ends.
+func lockMigrations(tx *sql.Tx, version int) (bool, error) {
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(?);`, migrationLockID); err != nil {
+		return false, err
+	}
+	var applied bool
+	err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = ?);`, version).Scan(&applied)
+	return applied, err
+}
@@ -2,23 +2,41 @@ package db
 
 import (
 	"database/sql"
-	"encoding/csv"
+	"errors"
 	"fmt"
-	"io"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/google/uuid"
 )
 
+// Job is a job application. Its ID is local to one database, while its UUID identifies
+// it across databases, for example when moving jobs between machines.
 type Job struct {
 	Company       string     `json:"company" db:"company"`
+	CompanyID     int        `json:"company_id,omitempty" db:"company_id"` // matched from Company
 	Position      string     `json:"position" db:"position"`
 	Status        JobStatus  `json:"status" db:"status"`
 	Location      NullString `json:"location" db:"location"`
+	Place         *Place     `json:"place,omitempty" db:"-"` // parsed from Location
 	SalaryRange   NullString `json:"salary_range" db:"salary_range"`
+	Salary        *Salary    `json:"salary,omitempty" db:"-"` // parsed from SalaryRange
 	JobPostingURL NullString `json:"job_posting_url" db:"job_posting_url"`
 	AppliedAt     *time.Time `json:"applied_at" db:"applied_at"`
+	FollowUpOn    *time.Time `json:"follow_up_on" db:"follow_up_on"`
 	CreatedAt     *time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt     *time.Time `json:"updated_at" db:"updated_at"`
 	ID            int        `json:"id" db:"id"`
+	UUID          string     `json:"uuid" db:"uuid"`
+	Tags          []string   `json:"tags,omitempty" db:"-"`
+	Notes         []*Note    `json:"notes,omitempty" db:"-"`
+	// Fields are the custom fields of the job, by name.
+	Fields map[string]string `json:"fields,omitempty" db:"-"`
+	// ConvertedSalary is Salary in another currency, set by ConvertSalaries.
+	ConvertedSalary *ConvertedSalary `json:"converted_salary,omitempty" db:"-"`
+	// Contacts are loaded for display only, they are exported separately as vCards.
+	Contacts Contacts `json:"-" db:"-"`
 }
 
 type Jobs []*Job
@@ -35,73 +53,115 @@ func (j *Job) ToCSV() []string {
 		FormatDateTime(*j.AppliedAt, true),
 		FormatDateTime(*j.CreatedAt, false),
 		FormatDateTime(*j.UpdatedAt, false),
+		formatOptionalDate(j.FollowUpOn),
+		j.UUID,
+		strings.Join(j.Tags, ","),
 	}
 }
 
+// csvHeader names the columns written by Job.ToCSV, in order.
+var csvHeader = []string{
+	"Company",
+	"Position",
+	"Status",
+	"Location",
+	"SalaryRange",
+	"JobPostingURL",
+	"AppliedAt",
+	"CreatedAt",
+	"UpdatedAt",
+	"FollowUpOn",
+	"UUID",
+	"Tags",
+}
+
+// convertedCSVHeader names the columns written for converted salaries.
+var convertedCSVHeader = []string{"ConvertedSalaryMin", "ConvertedSalaryMax", "ConvertedCurrency", "StaleRate"}
+
+// ToCSV marshals jobs into CSV rows, starting with the header. When salaries have been
+// converted with ConvertSalaries the converted amounts follow the built-in columns, then
+// come the custom fields set on any of the jobs, one column each.
 func (jobs Jobs) ToCSV() [][]string {
-	rows := [][]string{{
-		"Company",
-		"Position",
-		"Status",
-		"Location",
-		"SalaryRange",
-		"JobPostingURL",
-		"AppliedAt",
-		"CreatedAt",
-		"UpdatedAt",
-	}}
+	converted := false
 	for _, job := range jobs {
-		rows = append(rows, job.ToCSV())
+		converted = converted || job.ConvertedSalary != nil
+	}
+	fields := FieldNames(jobs)
+	header := append([]string{}, csvHeader...)
+	if converted {
+		header = append(header, convertedCSVHeader...)
+	}
+	rows := [][]string{append(header, fields...)}
+	for _, job := range jobs {
+		row := job.ToCSV()
+		if converted {
+			row = append(row, convertedCSV(job.ConvertedSalary)...)
+		}
+		for _, name := range fields {
+			row = append(row, job.Fields[name])
+		}
+		rows = append(rows, row)
 	}
 	return rows
 }
 
-func fromCSV(row []string) *Job {
-	appliedAt, _ := ParseDateTime(row[6], true)
-	createdAt, _ := ParseDateTime(row[7], false)
-	updatedAt, _ := ParseDateTime(row[8], false)
-	job := Job{
-		Company:       row[0],
-		Position:      row[1],
-		Status:        JobStatus(row[2]),
-		Location:      emptyToNull(row[3]),
-		SalaryRange:   emptyToNull(row[4]),
-		JobPostingURL: emptyToNull(row[5]),
-		AppliedAt:     appliedAt,
-		CreatedAt:     createdAt,
-		UpdatedAt:     updatedAt,
-	}
-	return &job
+// convertedCSV returns the values of the converted salary columns, empty if the salary
+// was not converted.
+func convertedCSV(salary *ConvertedSalary) []string {
+	if salary == nil {
+		return make([]string, len(convertedCSVHeader))
+	}
+	stale := ""
+	if salary.Stale {
+		stale = "yes"
+	}
+	return []string{
+		strconv.FormatFloat(salary.Min, 'f', 2, 64),
+		strconv.FormatFloat(salary.Max, 'f', 2, 64),
+		salary.Currency,
+		stale,
+	}
 }
 
-func FromCSV(r *csv.Reader) (Jobs, error) {
-	var jobs Jobs
-	for {
-		row, err := r.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return jobs, err
-		}
-		jobs = append(jobs, fromCSV(row))
+// AddJob stores a new job together with its first status event and any notes, tags and
+// custom fields, and sets its ID.
+func AddJob(sqliteDB *sql.DB, job *Job) error {
+	tx, err := sqliteDB.Begin()
+	if err != nil {
+		return err
 	}
-	return jobs, nil
+	defer tx.Rollback()
+	if err := insertJob(tx, job); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-func AddJob(sqliteDB *sql.DB, job *Job) error {
+// insertJob stores a new job as part of the given transaction, defaulting its applied
+// date to today and giving it a UUID if it has none, and sets its ID.
+func insertJob(tx *sql.Tx, job *Job) error {
 	const createQuery = `INSERT INTO jobs
-		(company, position, status, location, applied_at, salary_range, job_posting_url)
+		(company, position, status, location, applied_at, salary_range, job_posting_url, follow_up_on, uuid,
+		salary_min, salary_max, salary_currency, salary_period, salary_basis, work_mode, city, region, country,
+		company_id)
 		VALUES
-		(?, ?, ?, ?, ?, ?, ?);`
+		(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id;`
 
-	defaultAppliedAt := time.Now()
+	if job.UUID == "" {
+		job.UUID = uuid.NewString()
+	}
 	if job.AppliedAt == nil {
-		job.AppliedAt = &defaultAppliedAt
+		today := time.Now()
+		job.AppliedAt = &today
 	}
-
-	result, err := sqliteDB.Exec(
-		createQuery,
+	companyID, companyName, err := matchCompany(tx, job.Company)
+	if err != nil {
+		return err
+	}
+	job.CompanyID, job.Company = companyID, companyName
+	var jobDBId int
+	params := []any{
 		job.Company,
 		job.Position,
 		job.Status,
@@ -109,114 +169,162 @@ func AddJob(sqliteDB *sql.DB, job *Job) error {
 		FormatDateTime(*job.AppliedAt, true),
 		toSQLValue(&job.SalaryRange),
 		toSQLValue(&job.JobPostingURL),
-	)
+		toSQLValue(job.FollowUpOn),
+		job.UUID,
+	}
+	params = append(params, salaryValues(job.SalaryRange)...)
+	params = append(params, placeValues(job.Location)...)
+	err = tx.QueryRow(createQuery, append(params, job.CompanyID)...).Scan(&jobDBId)
 	if err != nil {
-		fmt.Println("Error in adding job", err)
-		return err
+		return fmt.Errorf("Error in adding job: %w", err)
 	}
 
-	jobDBId, err := result.LastInsertId()
-	if err != nil {
-		fmt.Println("Error getting new job ID", err)
-		return err
+	if err := recordStatusEvent(tx, jobDBId, NullString{}, job.Status, NullString{}); err != nil {
+		return fmt.Errorf("Error recording job status: %w", err)
+	}
+	for _, note := range job.Notes {
+		if err := insertNote(tx, jobDBId, note); err != nil {
+			return fmt.Errorf("Error adding job note: %w", err)
+		}
+	}
+	if err := setJobTags(tx, jobDBId, job.Tags, nil); err != nil {
+		return fmt.Errorf("Error adding job tags: %w", err)
 	}
-	job.ID = int(jobDBId)
-	fmt.Printf("New job application (ID: %d) added, good luck!\n", jobDBId)
+	if err := setJobFields(tx, jobDBId, job.Fields); err != nil {
+		return fmt.Errorf("Error adding job fields: %w", err)
+	}
+	job.ID = jobDBId
 	return nil
 }
 
-func DeleteJobByID(sqliteDB *sql.DB, jobID int) {
-	const deleteQuery = `DELETE FROM jobs
-		WHERE id = ?
-		RETURNING company, position;`
-
-	var companyName, position string
-	err := sqliteDB.QueryRow(deleteQuery, jobID).Scan(&companyName, &position)
+// DeleteJobByID removes a job along with its notes, history, interviews and tags, reporting
+// whether it existed. The job's UUID is kept as a tombstone so syncing deletes it on
+// other devices too.
+func DeleteJobByID(sqliteDB *sql.DB, jobID int) (bool, error) {
+	tx, err := sqliteDB.Begin()
 	if err != nil {
-		if err == sql.ErrNoRows {
-			fmt.Println("No job found with the provided ID")
-			return
+		return false, err
+	}
+	defer tx.Rollback()
+	const deleteQuery = `INSERT INTO deleted_jobs (uuid, deleted_at) VALUES (?, ?)
+		ON CONFLICT (uuid) DO UPDATE SET deleted_at = excluded.deleted_at, synced = 0;`
+	var jobUUID sql.NullString
+	err = tx.QueryRow(`DELETE FROM jobs WHERE id = ? RETURNING uuid;`, jobID).Scan(&jobUUID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if jobUUID.Valid {
+		if _, err := tx.Exec(deleteQuery, jobUUID.String, currentTimestamp()); err != nil {
+			return false, err
 		}
-		fmt.Println("Error deleting job:", err)
-		return
 	}
+	if err := pruneTags(tx); err != nil {
+		return false, err
+	}
+	if err := pruneCompanies(tx); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+var (
+	// ErrJobNotFound is returned by ResolveJobID when no job matches.
+	ErrJobNotFound = errors.New("No job found with ID or UUID prefix")
+	// ErrAmbiguousJobID is returned by ResolveJobID when a UUID prefix matches several jobs.
+	ErrAmbiguousJobID = errors.New("UUID prefix matches several jobs, use a longer prefix")
+)
 
-	fmt.Printf("Application for %s at %s (ID: %d) deleted\n", position, companyName, jobID)
+// ResolveJobID returns the ID of the job identified by ref, which is either the ID of a
+// job or the start of its UUID. IDs take precedence, so a UUID prefix made up only of
+// digits is used only if no job has that ID.
+func ResolveJobID(sqliteDB *sql.DB, ref string) (int, error) {
+	ref = strings.ToLower(strings.TrimSpace(ref))
+	if id, err := strconv.Atoi(ref); err == nil {
+		var exists bool
+		err := sqliteDB.QueryRow(`SELECT EXISTS (SELECT 1 FROM jobs WHERE id = ?);`, id).Scan(&exists)
+		if err != nil {
+			return 0, err
+		}
+		if exists {
+			return id, nil
+		}
+	}
+	if ref == "" || strings.Trim(ref, "0123456789abcdef-") != "" {
+		return 0, fmt.Errorf("%w: %s", ErrJobNotFound, ref)
+	}
+	rows, err := sqliteDB.Query(`SELECT id FROM jobs WHERE substr(uuid, 1, ?) = ? LIMIT 2;`, len(ref), ref)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	switch len(ids) {
+	case 0:
+		return 0, fmt.Errorf("%w: %s", ErrJobNotFound, ref)
+	case 1:
+		return ids[0], nil
+	default:
+		return 0, fmt.Errorf("%w: %s", ErrAmbiguousJobID, ref)
+	}
 }
 
 func GetJobByID(sqliteDB *sql.DB, id int) (*Job, error) {
-	const selectQuery = `SELECT
-		id, company, position, status, location, salary_range, job_posting_url, applied_at, created_at, updated_at
-		FROM jobs WHERE id = ?;`
+	selectQuery := `SELECT ` + jobColumns + ` FROM jobs WHERE id = ?;`
 
-	var job Job
-	var appliedAt, createdAt, updatedAt string
-	err := sqliteDB.QueryRow(selectQuery, id).Scan(
-		&job.ID,
-		&job.Company,
-		&job.Position,
-		&job.Status,
-		&job.Location,
-		&job.SalaryRange,
-		&job.JobPostingURL,
-		&appliedAt,
-		&createdAt,
-		&updatedAt,
-	)
+	job, err := ParseRow(sqliteDB.QueryRow(selectQuery, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
 		return nil, err
 	}
-	job.AppliedAt, _ = ParseDateTime(appliedAt, true)
-	job.CreatedAt, _ = ParseDateTime(createdAt, false)
-	job.UpdatedAt, _ = ParseDateTime(updatedAt, false)
-	return &job, nil
+	if err := attachDetails(sqliteDB, []*Job{job}); err != nil {
+		return nil, err
+	}
+	return job, nil
 }
 
+// getJobs returns the jobs selected by query, along with their tags and custom fields.
 func getJobs(sqliteDB *sql.DB, query string, params ...any) ([]*Job, error) {
 	rows, err := sqliteDB.Query(query, params...)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 	jobs, err := FetchJobsFromRows(rows)
-	return jobs, err
-}
-
-func GetAllJobs(sqliteDB *sql.DB, includeTimestamps bool) ([]*Job, error) {
-	selectQuery := `SELECT
-		id, company, position, status, location, salary_range, job_posting_url, applied_at, created_at, updated_at`
-	selectQuery += ` FROM jobs;`
-	jobs, err := getJobs(sqliteDB, selectQuery)
-	return jobs, err
-}
-
-func GetJobsByStatus(sqliteDB *sql.DB, jobStatus JobStatus) ([]*Job, error) {
-	const selectQuery = `SELECT
-		id, company, position, status, location, salary_range, job_posting_url, applied_at, created_at, updated_at
-		FROM jobs WHERE status = ? ORDER BY applied_at ASC;`
-	jobs, err := getJobs(sqliteDB, selectQuery, jobStatus)
-	return jobs, err
-}
-
-func GetJobsByDate(sqliteDB *sql.DB, before string, after string) ([]*Job, error) {
-	beforeTime, err := ParseDateTime(before, true)
+	rows.Close()
 	if err != nil {
 		return nil, err
 	}
-	afterTime, err := ParseDateTime(after, true)
-	if err != nil {
+	if err := attachDetails(sqliteDB, jobs); err != nil {
 		return nil, err
 	}
-	if afterTime.After(*beforeTime) {
-		return nil, err
+	return jobs, nil
+}
+
+// attachDetails loads the tags and custom fields of every given job.
+func attachDetails(q querier, jobs []*Job) error {
+	if err := attachTags(q, jobs); err != nil {
+		return err
 	}
-	selectQuery := `SELECT
-		id, company, position, status, location, salary_range, job_posting_url, applied_at, created_at, updated_at
-		FROM jobs WHERE applied_at >= ? AND applied_at <= ?;`
-	jobs, err := getJobs(sqliteDB, selectQuery, afterTime, beforeTime)
+	return attachFields(q, jobs)
+}
+
+func GetAllJobs(sqliteDB *sql.DB, includeTimestamps bool) ([]*Job, error) {
+	selectQuery := `SELECT ` + jobColumns + ` FROM jobs;`
+	jobs, err := getJobs(sqliteDB, selectQuery)
 	return jobs, err
 }
 
@@ -228,6 +336,24 @@ type UpdatedJobParams struct {
 	SalaryRange   *string
 	JobPostingURL *string
 	AppliedAt     *time.Time
+	FollowUpOn    *time.Time
+	// ClearFollowUp removes the follow-up date. A status change also clears it unless
+	// a new FollowUpOn is given, as follow-ups belong to the stage they were set in.
+	ClearFollowUp bool
+	// ClearLocation, ClearSalaryRange and ClearJobPostingURL remove the value of the field,
+	// taking precedence over a new value given for it.
+	ClearLocation      bool
+	ClearSalaryRange   bool
+	ClearJobPostingURL bool
+	// Note is recorded in the status history alongside a status change.
+	Note *string
+	// Force skips the pipeline's transition rules when changing status.
+	Force bool
+	// AddTags and RemoveTags are tags to give the job and to take away from it.
+	AddTags    []string
+	RemoveTags []string
+	// SetFields sets custom fields, removing those set to an empty value.
+	SetFields map[string]string
 }
 
 func toSQLValue[T any](ptr *T) any {
@@ -247,37 +373,130 @@ func toSQLValue[T any](ptr *T) any {
 	}
 }
 
+// UpdateJob changes the given fields of a job, recording a status change in its history.
+// It returns nil if there is no job with that ID, and a *TransitionError if the pipeline
+// does not allow the status change.
 func UpdateJob(sqliteDB *sql.DB, jobID int, updates UpdatedJobParams) (*Job, error) {
-	const updateQuery = `UPDATE jobs
+	tx, err := sqliteDB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	job, err := updateJob(tx, jobID, updates)
+	if err != nil || job == nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// updateJob changes a job as part of the given transaction, see UpdateJob.
+func updateJob(tx *sql.Tx, jobID int, updates UpdatedJobParams) (*Job, error) {
+	updateQuery := `UPDATE jobs
 		SET
 		company = COALESCE(?, company),
+		company_id = COALESCE(?, company_id),
 		position = COALESCE(?, position),
 		status = COALESCE(?, status),
-		location = COALESCE(?, location),
-		salary_range = COALESCE(?, salary_range),
-		job_posting_url = COALESCE(?, job_posting_url),
+		location = CASE WHEN ? THEN NULL ELSE COALESCE(?, location) END,
+		salary_range = CASE WHEN ? THEN NULL ELSE COALESCE(?, salary_range) END,
+		job_posting_url = CASE WHEN ? THEN NULL ELSE COALESCE(?, job_posting_url) END,
 		applied_at = COALESCE(?, applied_at),
-		updated_at = (CURRENT_TIMESTAMP)
+		follow_up_on = CASE WHEN ? THEN NULL ELSE COALESCE(?, follow_up_on) END,
+		updated_at = ?
 		WHERE id = ?
-		RETURNING id, company, position, status, location, salary_range, job_posting_url, applied_at, created_at, updated_at;`
+		RETURNING ` + jobColumns + `;`
 
-	row := sqliteDB.QueryRow(
+	const statusQuery = `SELECT status FROM jobs WHERE id = ?;`
+
+	var oldStatus JobStatus
+	if err := tx.QueryRow(statusQuery, jobID).Scan(&oldStatus); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if updates.Status != nil && !updates.Force {
+		if err := activePipeline.CheckTransition(oldStatus, *updates.Status); err != nil {
+			return nil, err
+		}
+	}
+	// the structured salary and location are set first so the updated row returned has them
+	if updates.ClearSalaryRange {
+		if err := setJobSalary(tx, jobID, NullString{}); err != nil {
+			return nil, err
+		}
+	} else if updates.SalaryRange != nil && *updates.SalaryRange != "" {
+		if err := setJobSalary(tx, jobID, emptyToNull(*updates.SalaryRange)); err != nil {
+			return nil, err
+		}
+	}
+	if updates.ClearLocation {
+		if err := setJobPlace(tx, jobID, NullString{}); err != nil {
+			return nil, err
+		}
+	} else if updates.Location != nil && *updates.Location != "" {
+		if err := setJobPlace(tx, jobID, emptyToNull(*updates.Location)); err != nil {
+			return nil, err
+		}
+	}
+	// jobs are moved to the company the new name matches, spelled the way it is named
+	company, companyID := updates.Company, (*int)(nil)
+	if company != nil && *company != "" {
+		id, name, err := matchCompany(tx, *company)
+		if err != nil {
+			return nil, err
+		}
+		company, companyID = &name, &id
+	}
+	statusChanged := updates.Status != nil && *updates.Status != oldStatus
+	clearFollowUp := updates.ClearFollowUp || (statusChanged && updates.FollowUpOn == nil)
+	row := tx.QueryRow(
 		updateQuery,
-		toSQLValue(updates.Company),
+		toSQLValue(company),
+		toSQLValue(companyID),
 		toSQLValue(updates.Position),
 		toSQLValue(updates.Status),
+		updates.ClearLocation,
 		toSQLValue(updates.Location),
+		updates.ClearSalaryRange,
 		toSQLValue(updates.SalaryRange),
+		updates.ClearJobPostingURL,
 		toSQLValue(updates.JobPostingURL),
 		toSQLValue(updates.AppliedAt),
+		clearFollowUp,
+		toSQLValue(updates.FollowUpOn),
+		currentTimestamp(),
 		jobID,
 	)
 	job, err := ParseRow(row)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			fmt.Println("No job found with that id")
-			return nil, nil
+		return nil, err
+	}
+	if job.Status != oldStatus {
+		var note NullString
+		if updates.Note != nil {
+			note = emptyToNull(*updates.Note)
+		}
+		err := recordStatusEvent(tx, job.ID, emptyToNull(string(oldStatus)), job.Status, note)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := setJobTags(tx, job.ID, updates.AddTags, updates.RemoveTags); err != nil {
+		return nil, err
+	}
+	if err := setJobFields(tx, job.ID, updates.SetFields); err != nil {
+		return nil, err
+	}
+	if companyID != nil {
+		if err := pruneCompanies(tx); err != nil {
+			return nil, err
 		}
+	}
+	if err := attachDetails(tx, []*Job{job}); err != nil {
 		return nil, err
 	}
 	return job, nil
@@ -0,0 +1,153 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Note is a timestamped free-text entry about a job application.
+type Note struct {
+	ID        int        `json:"id" db:"id"`
+	JobID     int        `json:"-" db:"job_id"`
+	Body      string     `json:"body" db:"body"`
+	CreatedAt *time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// insertNote stores a note as part of the given transaction, keeping its timestamps
+// if it already has them so imported notes retain their history.
+func insertNote(tx *sql.Tx, jobID int, note *Note) error {
+	const insertQuery = `INSERT INTO notes
+		(job_id, body, created_at, updated_at)
+		VALUES
+		(?, ?, ?, ?)
+		RETURNING id, created_at, updated_at;`
+
+	createdAt, updatedAt := currentTimestamp(), currentTimestamp()
+	if note.CreatedAt != nil {
+		createdAt = FormatDateTime(*note.CreatedAt, false)
+	}
+	if note.UpdatedAt != nil {
+		updatedAt = FormatDateTime(*note.UpdatedAt, false)
+	}
+	var created, updated string
+	err := tx.QueryRow(insertQuery, jobID, note.Body, createdAt, updatedAt).Scan(&note.ID, &created, &updated)
+	if err != nil {
+		return err
+	}
+	note.JobID = jobID
+	note.CreatedAt, _ = ParseDateTime(created, false)
+	note.UpdatedAt, _ = ParseDateTime(updated, false)
+	return nil
+}
+
+// AddNote adds a note to the job with the given ID.
+func AddNote(sqliteDB *sql.DB, jobID int, body string) (*Note, error) {
+	tx, err := sqliteDB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	note := Note{Body: body}
+	if err := insertNote(tx, jobID, &note); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &note, nil
+}
+
+func scanNotes(rows *sql.Rows) ([]*Note, error) {
+	var notes []*Note
+	for rows.Next() {
+		var note Note
+		var createdAt, updatedAt string
+		if err := rows.Scan(&note.ID, &note.JobID, &note.Body, &createdAt, &updatedAt); err != nil {
+			return notes, err
+		}
+		note.CreatedAt, _ = ParseDateTime(createdAt, false)
+		note.UpdatedAt, _ = ParseDateTime(updatedAt, false)
+		notes = append(notes, &note)
+	}
+	if err := rows.Err(); err != nil {
+		return notes, err
+	}
+	return notes, nil
+}
+
+// GetNotes returns the notes of a job, oldest first.
+func GetNotes(sqliteDB *sql.DB, jobID int) ([]*Note, error) {
+	const selectQuery = `SELECT id, job_id, body, created_at, updated_at
+		FROM notes WHERE job_id = ? ORDER BY created_at ASC, id ASC;`
+	rows, err := sqliteDB.Query(selectQuery, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanNotes(rows)
+}
+
+// GetNoteByID returns a single note, or nil if there is no note with that ID.
+func GetNoteByID(sqliteDB *sql.DB, noteID int) (*Note, error) {
+	const selectQuery = `SELECT id, job_id, body, created_at, updated_at FROM notes WHERE id = ?;`
+	rows, err := sqliteDB.Query(selectQuery, noteID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	notes, err := scanNotes(rows)
+	if err != nil || len(notes) == 0 {
+		return nil, err
+	}
+	return notes[0], nil
+}
+
+// AttachNotes loads the notes of every given job into its Notes field.
+func AttachNotes(sqliteDB *sql.DB, jobs []*Job) error {
+	const selectQuery = `SELECT id, job_id, body, created_at, updated_at
+		FROM notes ORDER BY created_at ASC, id ASC;`
+	rows, err := sqliteDB.Query(selectQuery)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	notes, err := scanNotes(rows)
+	if err != nil {
+		return err
+	}
+	byJob := make(map[int][]*Note)
+	for _, note := range notes {
+		byJob[note.JobID] = append(byJob[note.JobID], note)
+	}
+	for _, job := range jobs {
+		job.Notes = byJob[job.ID]
+	}
+	return nil
+}
+
+// UpdateNote replaces the text of a note. It returns nil if there is no note with that ID.
+func UpdateNote(sqliteDB *sql.DB, noteID int, body string) (*Note, error) {
+	const updateQuery = `UPDATE notes
+		SET body = ?, updated_at = ?
+		WHERE id = ?;`
+	result, err := sqliteDB.Exec(updateQuery, body, currentTimestamp(), noteID)
+	if err != nil {
+		return nil, err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil, nil
+	}
+	return GetNoteByID(sqliteDB, noteID)
+}
+
+// DeleteNote removes a note, reporting whether a note with that ID existed.
+func DeleteNote(sqliteDB *sql.DB, noteID int) (bool, error) {
+	const deleteQuery = `DELETE FROM notes WHERE id = ?;`
+	result, err := sqliteDB.Exec(deleteQuery, noteID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
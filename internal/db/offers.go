@@ -0,0 +1,263 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Offer is the compensation offered for a job. Base and Bonus are paid yearly, SignOn once
+// when starting, and Equity is the value of the whole grant, vesting over the years of
+// Vesting. Amounts left out are 0.
+type Offer struct {
+	ID        int        `json:"id" db:"id"`
+	JobID     int        `json:"job_id" db:"job_id"`
+	Currency  string     `json:"currency" db:"currency"`
+	Base      float64    `json:"base" db:"base"`
+	Bonus     float64    `json:"bonus" db:"bonus"`
+	SignOn    float64    `json:"sign_on" db:"sign_on"`
+	Equity    float64    `json:"equity" db:"equity"`
+	Vesting   NullString `json:"vesting" db:"vesting"`
+	Benefits  NullString `json:"benefits" db:"benefits"`
+	StartDate *time.Time `json:"start_date" db:"start_date"`
+	Deadline  *time.Time `json:"deadline" db:"deadline"`
+	CreatedAt *time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at" db:"updated_at"`
+	// Company and Position describe the job the offer is for.
+	Company  string `json:"company" db:"-"`
+	Position string `json:"position" db:"-"`
+}
+
+// defaultVestingYears is how long equity vests over when no schedule is given.
+const defaultVestingYears = 4
+
+const offerSelect = `SELECT
+	offers.id, offers.job_id, offers.currency, offers.base, offers.bonus, offers.sign_on,
+	offers.equity, offers.vesting, offers.benefits, offers.start_date, offers.deadline,
+	offers.created_at, offers.updated_at, jobs.company, jobs.position
+	FROM offers JOIN jobs ON jobs.id = offers.job_id`
+
+// vestingYears matches a schedule given as a number of years, such as 4y or
+// "4 years, 1 year cliff".
+var vestingYears = regexp.MustCompile(`^(\d+)\s*(?:y|yr|yrs|year|years)\b`)
+
+// ParseVesting reads a vesting schedule, returning the fraction of the grant vesting in
+// each year. A schedule is either a number of years the grant vests evenly over, such as
+// 4y, or the percentage vesting each year, such as 10/20/30/40. An empty schedule vests
+// evenly over four years.
+func ParseVesting(schedule string) ([]float64, error) {
+	schedule = strings.ToLower(strings.TrimSpace(schedule))
+	invalid := fmt.Errorf(
+		"Invalid vesting schedule %q, use a number of years such as 4y or yearly percentages such as 25/25/25/25",
+		schedule,
+	)
+	years := defaultVestingYears
+	if match := vestingYears.FindStringSubmatch(schedule); match != nil {
+		years, _ = strconv.Atoi(match[1])
+	} else if schedule != "" {
+		var fractions []float64
+		total := 0.0
+		for _, part := range strings.FieldsFunc(schedule, func(r rune) bool { return r == '/' || r == ',' || r == ' ' }) {
+			percent, err := strconv.ParseFloat(strings.TrimSuffix(part, "%"), 64)
+			if err != nil || percent < 0 {
+				return nil, invalid
+			}
+			fractions = append(fractions, percent/100)
+			total += percent
+		}
+		if len(fractions) == 0 || math.Abs(total-100) > 0.5 {
+			return nil, fmt.Errorf("The yearly vesting percentages of %q must add up to 100", schedule)
+		}
+		return fractions, nil
+	}
+	if years < 1 || years > 10 {
+		return nil, invalid
+	}
+	fractions := make([]float64, years)
+	for i := range fractions {
+		fractions[i] = 1 / float64(years)
+	}
+	return fractions, nil
+}
+
+// vestedEquity returns the value of the equity vesting in the first years of the offer.
+func (o *Offer) vestedEquity(years int) float64 {
+	fractions, err := ParseVesting(o.Vesting.String)
+	if err != nil {
+		return 0
+	}
+	vested := 0.0
+	for i := 0; i < years && i < len(fractions); i++ {
+		vested += fractions[i]
+	}
+	return o.Equity * vested
+}
+
+// FirstYear returns the total compensation of the first year: base, bonus, the sign-on
+// bonus and the equity vesting that year.
+func (o *Offer) FirstYear() float64 {
+	return o.Base + o.Bonus + o.SignOn + o.vestedEquity(1)
+}
+
+// FourYear returns the total compensation of the first four years, with base and bonus
+// unchanged each year.
+func (o *Offer) FourYear() float64 {
+	return 4*(o.Base+o.Bonus) + o.SignOn + o.vestedEquity(4)
+}
+
+// ParseAmount parses an amount of money such as 150000, 150,000, 150k or 1.2m.
+func ParseAmount(text string) (float64, error) {
+	s := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(text), ",", ""))
+	suffix := ""
+	if strings.HasSuffix(s, "k") || strings.HasSuffix(s, "m") {
+		suffix, s = s[len(s)-1:], strings.TrimSpace(s[:len(s)-1])
+	}
+	if _, err := strconv.ParseFloat(s, 64); err != nil {
+		return 0, fmt.Errorf("Invalid amount %q, use a number such as 150000 or 150k", text)
+	}
+	amount, _ := parseAmount(s, suffix)
+	return amount, nil
+}
+
+// ConvertOffers converts the amounts of offers to target, returning the number of
+// offers in a currency that could not be converted, which are left as they are.
+func ConvertOffers(offers []*Offer, conversions map[string]*Conversion, target string) int {
+	missing := 0
+	for _, offer := range offers {
+		conversion, ok := conversions[offer.Currency]
+		if !ok {
+			missing++
+			continue
+		}
+		for _, amount := range []*float64{&offer.Base, &offer.Bonus, &offer.SignOn, &offer.Equity} {
+			*amount = math.Round(*amount * conversion.Rate)
+		}
+		offer.Currency = target
+	}
+	return missing
+}
+
+// validateOffer normalises the currency of an offer and checks its amounts and vesting
+// schedule.
+func validateOffer(offer *Offer) error {
+	var err error
+	if offer.Currency, err = NormalizeCurrency(offer.Currency); err != nil {
+		return err
+	}
+	if offer.Base <= 0 {
+		return fmt.Errorf("The base salary of an offer must be greater than 0")
+	}
+	if offer.Bonus < 0 || offer.SignOn < 0 || offer.Equity < 0 {
+		return fmt.Errorf("The bonus, sign-on bonus and equity of an offer cannot be negative")
+	}
+	if _, err := ParseVesting(offer.Vesting.String); err != nil {
+		return err
+	}
+	return nil
+}
+
+// optionalAmount stores amounts of 0 as NULL.
+func optionalAmount(amount float64) any {
+	if amount == 0 {
+		return nil
+	}
+	return amount
+}
+
+func getOffers(sqliteDB *sql.DB, query string, params ...any) ([]*Offer, error) {
+	rows, err := sqliteDB.Query(query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var offers []*Offer
+	for rows.Next() {
+		var offer Offer
+		var bonus, signOn, equity sql.NullFloat64
+		var startDate, deadline sql.NullString
+		var createdAt, updatedAt string
+		err := rows.Scan(
+			&offer.ID,
+			&offer.JobID,
+			&offer.Currency,
+			&offer.Base,
+			&bonus,
+			&signOn,
+			&equity,
+			&offer.Vesting,
+			&offer.Benefits,
+			&startDate,
+			&deadline,
+			&createdAt,
+			&updatedAt,
+			&offer.Company,
+			&offer.Position,
+		)
+		if err != nil {
+			return nil, err
+		}
+		offer.Bonus, offer.SignOn, offer.Equity = bonus.Float64, signOn.Float64, equity.Float64
+		if startDate.Valid {
+			offer.StartDate, _ = ParseDateTime(startDate.String, true)
+		}
+		if deadline.Valid {
+			offer.Deadline, _ = ParseDateTime(deadline.String, true)
+		}
+		offer.CreatedAt, _ = ParseDateTime(createdAt, false)
+		offer.UpdatedAt, _ = ParseDateTime(updatedAt, false)
+		offers = append(offers, &offer)
+	}
+	return offers, rows.Err()
+}
+
+// AddOffer stores a new offer and sets its ID.
+func AddOffer(sqliteDB *sql.DB, offer *Offer) error {
+	const insertQuery = `INSERT INTO offers
+		(job_id, currency, base, bonus, sign_on, equity, vesting, benefits, start_date, deadline, created_at, updated_at)
+		VALUES
+		(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id;`
+	if err := validateOffer(offer); err != nil {
+		return err
+	}
+	now := currentTimestamp()
+	return sqliteDB.QueryRow(
+		insertQuery,
+		offer.JobID,
+		offer.Currency,
+		offer.Base,
+		optionalAmount(offer.Bonus),
+		optionalAmount(offer.SignOn),
+		optionalAmount(offer.Equity),
+		offer.Vesting,
+		offer.Benefits,
+		toSQLValue(offer.StartDate),
+		toSQLValue(offer.Deadline),
+		now,
+		now,
+	).Scan(&offer.ID)
+}
+
+// GetOfferByID returns an offer, or nil if there is no offer with that ID.
+func GetOfferByID(sqliteDB *sql.DB, id int) (*Offer, error) {
+	offers, err := getOffers(sqliteDB, offerSelect+` WHERE offers.id = ?;`, id)
+	if err != nil || len(offers) == 0 {
+		return nil, err
+	}
+	return offers[0], nil
+}
+
+// GetOffers returns every offer, soonest deadline first, only those of one job if jobID
+// is not -1.
+func GetOffers(sqliteDB *sql.DB, jobID int) ([]*Offer, error) {
+	var b queryBuilder
+	if jobID != -1 {
+		b.where("offers.job_id = ?", jobID)
+	}
+	query := offerSelect + b.clause() + ` ORDER BY offers.deadline ASC NULLS LAST, offers.id ASC;`
+	return getOffers(sqliteDB, query, b.params...)
+}
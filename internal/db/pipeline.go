@@ -0,0 +1,218 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Pipeline describes the statuses a job can be in and which status changes are allowed.
+// A status without any outgoing transitions is final: once a job reaches it, it can only
+// be moved with a forced update.
+type Pipeline struct {
+	statuses    []JobStatus
+	transitions map[JobStatus][]JobStatus
+	exits       []JobStatus
+	// initial is the status new jobs start in, the first status unless configured.
+	initial JobStatus
+}
+
+// TransitionError is returned when a status change is not allowed by the pipeline.
+type TransitionError struct {
+	From    JobStatus
+	To      JobStatus
+	Allowed []JobStatus
+}
+
+func (e *TransitionError) Error() string {
+	if len(e.Allowed) == 0 {
+		return fmt.Sprintf("%s is a final status, it cannot be changed to %s", e.From, e.To)
+	}
+	return fmt.Sprintf(
+		"cannot move from %s to %s, allowed statuses are: %s",
+		e.From,
+		e.To,
+		JoinStatuses(e.Allowed),
+	)
+}
+
+// activePipeline is the pipeline used to validate statuses. It is replaced on startup
+// when the user configures their own.
+var activePipeline = DefaultPipeline()
+
+// DefaultPipeline returns the built-in pipeline made up of the JobStatus constants.
+func DefaultPipeline() *Pipeline {
+	return &Pipeline{
+		statuses: []JobStatus{APPLIED, INTERVIEW, OFFER, ACCEPTED, REJECTED_OFFER, REJECTED},
+		transitions: map[JobStatus][]JobStatus{
+			APPLIED:   {INTERVIEW, OFFER},
+			INTERVIEW: {OFFER},
+			OFFER:     {ACCEPTED, REJECTED_OFFER},
+		},
+		exits: []JobStatus{REJECTED},
+	}
+}
+
+// NewPipeline builds a pipeline from its ordered statuses, the allowed transitions out of
+// each status and the exit statuses, which can be reached from any status that is not final.
+// Every status referenced by a transition or exit must be listed in statuses.
+func NewPipeline(statuses []string, transitions map[string][]string, exits []string) (*Pipeline, error) {
+	if len(statuses) == 0 {
+		return nil, fmt.Errorf("pipeline has no statuses")
+	}
+	p := &Pipeline{transitions: make(map[JobStatus][]JobStatus)}
+	for _, status := range statuses {
+		if _, ok := p.Lookup(status); ok {
+			return nil, fmt.Errorf("pipeline status %q is listed more than once", status)
+		}
+		p.statuses = append(p.statuses, JobStatus(strings.TrimSpace(status)))
+	}
+	resolve := func(names []string) ([]JobStatus, error) {
+		var resolved []JobStatus
+		for _, name := range names {
+			status, ok := p.Lookup(name)
+			if !ok {
+				return nil, fmt.Errorf("pipeline status %q is not listed in statuses", name)
+			}
+			resolved = append(resolved, status)
+		}
+		return resolved, nil
+	}
+	for from, to := range transitions {
+		fromStatus, ok := p.Lookup(from)
+		if !ok {
+			return nil, fmt.Errorf("pipeline status %q is not listed in statuses", from)
+		}
+		next, err := resolve(to)
+		if err != nil {
+			return nil, err
+		}
+		p.transitions[fromStatus] = next
+	}
+	exitStatuses, err := resolve(exits)
+	if err != nil {
+		return nil, err
+	}
+	p.exits = exitStatuses
+	return p, nil
+}
+
+// SetPipeline replaces the pipeline used to validate statuses.
+func SetPipeline(p *Pipeline) {
+	activePipeline = p
+}
+
+// ActivePipeline returns the pipeline currently used to validate statuses.
+func ActivePipeline() *Pipeline {
+	return activePipeline
+}
+
+// Statuses returns every status of the pipeline in order.
+func (p *Pipeline) Statuses() []JobStatus {
+	return p.statuses
+}
+
+// Initial returns the status new jobs start in when none is given.
+func (p *Pipeline) Initial() JobStatus {
+	if p.initial != "" {
+		return p.initial
+	}
+	return p.statuses[0]
+}
+
+// SetInitial makes new jobs start in the named status instead of the first one.
+func (p *Pipeline) SetInitial(name string) error {
+	status, ok := p.Lookup(name)
+	if !ok {
+		return fmt.Errorf("status %q is not in the pipeline, valid statuses are: %s", name, JoinStatuses(p.statuses))
+	}
+	p.initial = status
+	return nil
+}
+
+// Lookup finds a status by name, ignoring case and surrounding whitespace, and
+// returns it spelled as configured.
+func (p *Pipeline) Lookup(name string) (JobStatus, bool) {
+	name = strings.TrimSpace(name)
+	for _, status := range p.statuses {
+		if strings.EqualFold(string(status), name) {
+			return status, true
+		}
+	}
+	return "", false
+}
+
+// canonical returns status spelled as configured, or unchanged if it is not part of the
+// pipeline. Statuses were stored as typed before the pipeline existed, so jobs may hold
+// "interview" rather than "Interview".
+func (p *Pipeline) canonical(status JobStatus) JobStatus {
+	if known, ok := p.Lookup(string(status)); ok {
+		return known
+	}
+	return status
+}
+
+// IsExit reports whether status is one of the exit statuses of the pipeline.
+func (p *Pipeline) IsExit(status JobStatus) bool {
+	return containsStatus(p.exits, p.canonical(status))
+}
+
+// IsFinal reports whether no further status changes are allowed out of status.
+func (p *Pipeline) IsFinal(status JobStatus) bool {
+	return len(p.transitions[p.canonical(status)]) == 0
+}
+
+// Next returns the statuses a job in the given status may move to.
+func (p *Pipeline) Next(from JobStatus) []JobStatus {
+	from = p.canonical(from)
+	if p.IsFinal(from) {
+		return nil
+	}
+	next := append([]JobStatus{}, p.transitions[from]...)
+	for _, exit := range p.exits {
+		if exit != from && !containsStatus(next, exit) {
+			next = append(next, exit)
+		}
+	}
+	return next
+}
+
+// CheckTransition returns a *TransitionError if a job may not move from one status to
+// another. Staying in the same status is always allowed, as is leaving a status that is
+// not part of the pipeline so jobs can be moved into a newly configured pipeline.
+func (p *Pipeline) CheckTransition(from JobStatus, to JobStatus) error {
+	known, ok := p.Lookup(string(from))
+	if !ok {
+		return nil
+	}
+	to = p.canonical(to)
+	if known == to {
+		return nil
+	}
+	next := p.Next(known)
+	if !containsStatus(next, to) {
+		return &TransitionError{From: known, To: to, Allowed: next}
+	}
+	return nil
+}
+
+// JoinStatuses formats statuses as a comma separated list, quoting those with spaces.
+func JoinStatuses(statuses []JobStatus) string {
+	names := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		if strings.Contains(string(status), " ") {
+			names = append(names, fmt.Sprintf("%q", status))
+		} else {
+			names = append(names, string(status))
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+func containsStatus(statuses []JobStatus, status JobStatus) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
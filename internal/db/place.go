@@ -0,0 +1,509 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/language/display"
+)
+
+// WorkMode is where the work of a job is done.
+type WorkMode string
+
+const (
+	REMOTE WorkMode = "remote"
+	HYBRID WorkMode = "hybrid"
+	ONSITE WorkMode = "onsite"
+)
+
+// WorkModes are the work modes in the order they are shown.
+var WorkModes = []WorkMode{REMOTE, HYBRID, ONSITE}
+
+// Place is the structured form of a job's location, parsed from the text entered. Fields
+// the location does not mention are empty. Country is an ISO 3166 code such as US, and
+// Region the code of a US state or Canadian or Australian province, or the region as
+// written for other countries.
+type Place struct {
+	WorkMode WorkMode `json:"work_mode,omitempty"`
+	City     string   `json:"city,omitempty"`
+	Region   string   `json:"region,omitempty"`
+	Country  string   `json:"country,omitempty"`
+}
+
+// ParseWorkMode reads a work mode written as remote, hybrid or onsite.
+func ParseWorkMode(s string) (WorkMode, error) {
+	if mode := workModeOf(s); mode != "" {
+		return mode, nil
+	}
+	return "", fmt.Errorf("Unknown work mode %q, use remote, hybrid or onsite", s)
+}
+
+// The phrases naming each work mode. Hybrid wins over the others, as hybrid jobs are
+// often described as partly remote, and remote over onsite.
+var (
+	hybridPattern = regexp.MustCompile(`(?i)\bhybrid\b`)
+	remotePattern = regexp.MustCompile(`(?i)\b(remote|wfh|work from home|anywhere|distributed|telecommute)\b`)
+	onsitePattern = regexp.MustCompile(`(?i)\b(on-?site|on site|in-?office|in office|office-based|in-?person|in person)\b`)
+)
+
+// workModeOf returns the work mode named in text, or "" if none is.
+func workModeOf(text string) WorkMode {
+	switch {
+	case hybridPattern.MatchString(text):
+		return HYBRID
+	case remotePattern.MatchString(text):
+		return REMOTE
+	case onsitePattern.MatchString(text):
+		return ONSITE
+	}
+	return ""
+}
+
+// placeSeparators split a location into its parts, such as "New York, NY (hybrid)".
+var placeSeparators = regexp.MustCompile(`[,;/|()\[\]•]|\s[-–—]\s`)
+
+// placeFiller are words that say nothing about where a job is, dropped from the parts
+// of a location. ok is only dropped in lowercase, OK is Oklahoma as in Tulsa, OK.
+var placeFiller = regexp.MustCompile(
+	`\b(?i:only|based|preferred|friendly|first|optional|possible|greater|metro|area|hq|office|offices|days?|week|per|\d+)\b|\b(?:ok|okay)\b`,
+)
+
+// modeOK matches ok written after a work mode, as in Remote OK, which is dropped along
+// with the work mode in any case.
+var modeOK = regexp.MustCompile(`(?i)\b(remote|hybrid|wfh)\s+(ok|okay)\b`)
+
+// wideAreas are areas wider than a country. They are recognised so they are not taken
+// for a city, but are not kept.
+var wideAreas = map[string]bool{
+	"europe": true, "eu": true, "emea": true, "apac": true, "asia": true, "africa": true,
+	"americas": true, "north america": true, "south america": true, "latam": true, "nordics": true,
+	"worldwide": true, "global": true, "international": true, "anywhere": true, "any": true,
+	"est": true, "cet": true, "pst": true, "utc": true, "gmt": true, "time zones": true, "timezones": true,
+}
+
+// provinces are the states and provinces of the countries whose codes are commonly
+// written after a city, such as Austin, TX.
+var provinces = map[string]struct{ name, country string }{
+	"AL": {"Alabama", "US"}, "AK": {"Alaska", "US"}, "AZ": {"Arizona", "US"}, "AR": {"Arkansas", "US"},
+	"CA": {"California", "US"}, "CO": {"Colorado", "US"}, "CT": {"Connecticut", "US"}, "DE": {"Delaware", "US"},
+	"DC": {"District of Columbia", "US"}, "FL": {"Florida", "US"}, "GA": {"Georgia", "US"}, "HI": {"Hawaii", "US"},
+	"ID": {"Idaho", "US"}, "IL": {"Illinois", "US"}, "IN": {"Indiana", "US"}, "IA": {"Iowa", "US"},
+	"KS": {"Kansas", "US"}, "KY": {"Kentucky", "US"}, "LA": {"Louisiana", "US"}, "ME": {"Maine", "US"},
+	"MD": {"Maryland", "US"}, "MA": {"Massachusetts", "US"}, "MI": {"Michigan", "US"}, "MN": {"Minnesota", "US"},
+	"MS": {"Mississippi", "US"}, "MO": {"Missouri", "US"}, "MT": {"Montana", "US"}, "NE": {"Nebraska", "US"},
+	"NV": {"Nevada", "US"}, "NH": {"New Hampshire", "US"}, "NJ": {"New Jersey", "US"}, "NM": {"New Mexico", "US"},
+	"NY": {"New York", "US"}, "NC": {"North Carolina", "US"}, "ND": {"North Dakota", "US"}, "OH": {"Ohio", "US"},
+	"OK": {"Oklahoma", "US"}, "OR": {"Oregon", "US"}, "PA": {"Pennsylvania", "US"}, "RI": {"Rhode Island", "US"},
+	"SC": {"South Carolina", "US"}, "SD": {"South Dakota", "US"}, "TN": {"Tennessee", "US"}, "TX": {"Texas", "US"},
+	"UT": {"Utah", "US"}, "VT": {"Vermont", "US"}, "VA": {"Virginia", "US"}, "WA": {"Washington", "US"},
+	"WV": {"West Virginia", "US"}, "WI": {"Wisconsin", "US"}, "WY": {"Wyoming", "US"},
+	"AB": {"Alberta", "CA"}, "BC": {"British Columbia", "CA"}, "MB": {"Manitoba", "CA"}, "NB": {"New Brunswick", "CA"},
+	"NL": {"Newfoundland and Labrador", "CA"}, "NS": {"Nova Scotia", "CA"}, "ON": {"Ontario", "CA"},
+	"PE": {"Prince Edward Island", "CA"}, "QC": {"Quebec", "CA"}, "SK": {"Saskatchewan", "CA"},
+	"NSW": {"New South Wales", "AU"}, "VIC": {"Victoria", "AU"}, "QLD": {"Queensland", "AU"},
+	"TAS": {"Tasmania", "AU"}, "ACT": {"Australian Capital Territory", "AU"},
+}
+
+// city is a well known city, along with the names it is known by.
+type city struct {
+	name, region, country string
+	aliases               []string
+}
+
+// cities are well known cities, so a location naming one alone is placed in its region
+// and country. Cities not listed are recognised by the region or country written after
+// them.
+var cities = []city{
+	{"New York", "NY", "US", []string{"nyc", "new york city", "manhattan", "brooklyn"}},
+	{"San Francisco", "CA", "US", []string{"sf", "bay area", "sf bay area", "san francisco bay area"}},
+	{"Los Angeles", "CA", "US", []string{"la"}},
+	{"San Jose", "CA", "US", nil}, {"Mountain View", "CA", "US", nil}, {"Palo Alto", "CA", "US", nil},
+	{"San Diego", "CA", "US", nil}, {"Seattle", "WA", "US", nil}, {"Portland", "OR", "US", nil},
+	{"Austin", "TX", "US", nil}, {"Dallas", "TX", "US", nil}, {"Houston", "TX", "US", nil},
+	{"Boston", "MA", "US", nil}, {"Chicago", "IL", "US", nil}, {"Denver", "CO", "US", nil},
+	{"Atlanta", "GA", "US", nil}, {"Miami", "FL", "US", nil}, {"Washington", "DC", "US", []string{"washington dc", "washington d.c."}},
+	{"Philadelphia", "PA", "US", nil}, {"Pittsburgh", "PA", "US", nil}, {"Salt Lake City", "UT", "US", nil},
+	{"Toronto", "ON", "CA", nil}, {"Vancouver", "BC", "CA", nil}, {"Montreal", "QC", "CA", []string{"montréal"}},
+	{"Ottawa", "ON", "CA", nil}, {"Calgary", "AB", "CA", nil},
+	{"Sydney", "NSW", "AU", nil}, {"Melbourne", "VIC", "AU", nil}, {"Brisbane", "QLD", "AU", nil},
+	{"London", "", "GB", nil}, {"Manchester", "", "GB", nil}, {"Edinburgh", "", "GB", nil},
+	{"Dublin", "", "IE", nil}, {"Berlin", "", "DE", nil}, {"Munich", "", "DE", []string{"münchen"}},
+	{"Hamburg", "", "DE", nil}, {"Paris", "", "FR", nil}, {"Amsterdam", "", "NL", nil},
+	{"Zurich", "", "CH", []string{"zürich"}}, {"Stockholm", "", "SE", nil}, {"Copenhagen", "", "DK", nil},
+	{"Oslo", "", "NO", nil}, {"Helsinki", "", "FI", nil}, {"Madrid", "", "ES", nil}, {"Barcelona", "", "ES", nil},
+	{"Lisbon", "", "PT", nil}, {"Warsaw", "", "PL", nil}, {"Prague", "", "CZ", nil}, {"Vienna", "", "AT", nil},
+	{"Tel Aviv", "", "IL", nil}, {"Dubai", "", "AE", nil}, {"Singapore", "", "SG", nil},
+	{"Hong Kong", "", "HK", nil}, {"Tokyo", "", "JP", nil}, {"Seoul", "", "KR", nil},
+	{"Bangalore", "", "IN", []string{"bengaluru"}}, {"Hyderabad", "", "IN", nil}, {"Mumbai", "", "IN", nil},
+	{"Pune", "", "IN", nil}, {"Lagos", "", "NG", nil}, {"Abuja", "", "NG", nil}, {"Nairobi", "", "KE", nil},
+	{"Cape Town", "", "ZA", nil}, {"Johannesburg", "", "ZA", nil}, {"São Paulo", "", "BR", []string{"sao paulo"}},
+	{"Mexico City", "", "MX", nil}, {"Buenos Aires", "", "AR", nil},
+}
+
+// countryAliases are the other names countries are commonly written as.
+var countryAliases = map[string]string{
+	"usa": "US", "u.s.": "US", "u.s.a.": "US", "america": "US", "united states of america": "US",
+	"uk": "GB", "u.k.": "GB", "britain": "GB", "great britain": "GB", "england": "GB",
+	"scotland": "GB", "wales": "GB", "northern ireland": "GB", "holland": "NL", "the netherlands": "NL",
+	"deutschland": "DE", "uae": "AE", "korea": "KR", "czech republic": "CZ",
+}
+
+var (
+	countriesOnce sync.Once
+	// countryNames maps the lowercase English name of every country to its code.
+	countryNames map[string]string
+	// cityNames maps the lowercase name and aliases of every city in cities to it.
+	cityNames map[string]*city
+)
+
+// loadPlaceNames fills countryNames and cityNames.
+func loadPlaceNames() {
+	countryNames = make(map[string]string)
+	for a := 'A'; a <= 'Z'; a++ {
+		for b := 'A'; b <= 'Z'; b++ {
+			code := string([]rune{a, b})
+			region, err := language.ParseRegion(code)
+			// retired codes, such as DD for Germany, are left out
+			if err != nil || !region.IsCountry() || region.Canonicalize().String() != code {
+				continue
+			}
+			if name := display.English.Regions().Name(region); name != "" {
+				countryNames[strings.ToLower(name)] = code
+			}
+		}
+	}
+	cityNames = make(map[string]*city)
+	for i := range cities {
+		c := &cities[i]
+		cityNames[strings.ToLower(c.name)] = c
+		for _, alias := range c.aliases {
+			cityNames[alias] = c
+		}
+	}
+}
+
+// countryCode returns the code of a country written as its English name, a common
+// alias or, when allowCode is set, its ISO 3166 code.
+func countryCode(s string, allowCode bool) string {
+	countriesOnce.Do(loadPlaceNames)
+	lower := strings.ToLower(strings.TrimSpace(s))
+	if code, ok := countryAliases[lower]; ok {
+		return code
+	}
+	if code, ok := countryNames[lower]; ok {
+		return code
+	}
+	if allowCode && len(lower) == 2 {
+		if region, err := language.ParseRegion(lower); err == nil && region.IsCountry() {
+			return region.Canonicalize().String()
+		}
+	}
+	return ""
+}
+
+// NormalizeCountry returns the ISO 3166 code of a country given by its code or name.
+func NormalizeCountry(s string) (string, error) {
+	if code := countryCode(s, true); code != "" {
+		return code, nil
+	}
+	return "", fmt.Errorf("Unknown country %q, use a name such as Germany or a code such as DE", s)
+}
+
+// NormalizeCity returns the usual name of a well known city written another way, such
+// as New York for NYC, or the city as given otherwise.
+func NormalizeCity(s string) string {
+	countriesOnce.Do(loadPlaceNames)
+	if c, ok := cityNames[strings.ToLower(strings.TrimSpace(s))]; ok {
+		return c.name
+	}
+	return strings.TrimSpace(s)
+}
+
+// CountryName returns the English name of a country code, or the code if it is unknown.
+func CountryName(code string) string {
+	region, err := language.ParseRegion(code)
+	if err != nil {
+		return code
+	}
+	if name := display.English.Regions().Name(region); name != "" {
+		return name
+	}
+	return code
+}
+
+// ParsePlace reads the work mode, city, region and country of a location written as
+// free text, such as "Remote - US", "NYC (hybrid)" or "Austin, TX". It returns nil
+// when nothing in the text is recognised. Parts that are not a known city, region or
+// country are taken for the city, then the region, when they come before one that is.
+func ParsePlace(text string) *Place {
+	countriesOnce.Do(loadPlaceNames)
+	place := &Place{WorkMode: workModeOf(text)}
+	text = modeOK.ReplaceAllString(text, "$1")
+	for _, pattern := range []*regexp.Regexp{hybridPattern, remotePattern, onsitePattern} {
+		text = pattern.ReplaceAllString(text, ",")
+	}
+	var parts []placePart
+	for _, part := range placeSeparators.Split(text, -1) {
+		c := cityNames[strings.ToLower(strings.TrimSpace(part))]
+		part = strings.Join(strings.Fields(placeFiller.ReplaceAllString(part, " ")), " ")
+		lowerPart := strings.ToLower(part)
+		if c == nil {
+			c = cityNames[lowerPart]
+		}
+		if part == "" || wideAreas[lowerPart] {
+			continue
+		}
+		parts = append(parts, placePart{text: part, city: c})
+	}
+	var unknown []string
+	known := false
+	for i, pp := range parts {
+		part, lowerPart := pp.text, strings.ToLower(pp.text)
+		if pp.city != nil && place.City == "" {
+			place.City, known = pp.city.name, true
+			if place.Country == "" || place.Country == pp.city.country {
+				place.Region, place.Country = pp.city.region, pp.city.country
+			}
+			continue
+		}
+		upper := strings.ToUpper(part)
+		if p, ok := provinces[upper]; ok && part == upper && isProvince(parts, i, p.country) {
+			place.Region, place.Country, known = upper, p.country, true
+			continue
+		}
+		if code := countryCode(part, part == upper); code != "" {
+			place.Country, known = code, true
+			// a known city of another country, as in London, Canada, is not in its
+			// usual region
+			if p, ok := provinces[place.Region]; ok && p.country != code {
+				place.Region = ""
+			}
+			continue
+		}
+		if code, ok := provinceByName(lowerPart); ok {
+			place.Region, place.Country, known = code, provinces[code].country, true
+			continue
+		}
+		unknown = append(unknown, part)
+	}
+	// unknown parts are only trusted when a known part places them, or when they are
+	// all there is
+	if known || (len(unknown) == 1 && place.WorkMode == "") {
+		for _, part := range unknown {
+			if place.City == "" {
+				place.City = part
+			} else if place.Region == "" {
+				place.Region = part
+			}
+		}
+	}
+	if *place == (Place{}) {
+		return nil
+	}
+	return place
+}
+
+// placePart is a part of a location, with the well known city it names if any.
+type placePart struct {
+	text string
+	city *city
+}
+
+// isProvince reports whether the code of a state or province of country at parts[i] is
+// the state rather than the country of the same code. A code that is no country's is a
+// state. Otherwise it is a state after a known city of the state's country, as in
+// Denver, CO, or between a city and a country, as in Boulder, CO, USA, and a country
+// after other cities, as in Berlin, DE or Lima, PE. CA after a city is California, as
+// Canada is rarely written that way.
+func isProvince(parts []placePart, i int, country string) bool {
+	if countryCode(parts[i].text, true) == "" {
+		return true
+	}
+	if i == 0 {
+		return false
+	}
+	previous := parts[i-1].city
+	return i+1 < len(parts) || (previous != nil && previous.country == country) ||
+		(previous == nil && parts[i].text == "CA")
+}
+
+// provinceByName returns the code of a state or province written out in full.
+func provinceByName(lower string) (string, bool) {
+	for code, p := range provinces {
+		if strings.ToLower(p.name) == lower {
+			return code, true
+		}
+	}
+	return "", false
+}
+
+// placeValues returns the values of the structured location columns for a location, all
+// nil if nothing in it is recognised.
+func placeValues(location NullString) []any {
+	var place *Place
+	if location.Valid {
+		place = ParsePlace(location.String)
+	}
+	if place == nil {
+		return []any{nil, nil, nil, nil}
+	}
+	return []any{
+		emptyToNull(string(place.WorkMode)),
+		emptyToNull(place.City),
+		emptyToNull(place.Region),
+		emptyToNull(place.Country),
+	}
+}
+
+// setJobPlace stores the structured form of a job's location.
+func setJobPlace(q querier, jobID int, location NullString) error {
+	const placeQuery = `UPDATE jobs SET work_mode = ?, city = ?, region = ?, country = ? WHERE id = ?;`
+	_, err := q.Exec(placeQuery, append(placeValues(location), jobID)...)
+	return err
+}
+
+// fillPlaces parses the locations that have no structured location yet.
+func fillPlaces(q querier) error {
+	rows, err := q.Query(`SELECT id, location FROM jobs WHERE location IS NOT NULL
+		AND work_mode IS NULL AND city IS NULL AND region IS NULL AND country IS NULL;`)
+	if err != nil {
+		return err
+	}
+	locations := make(map[int]NullString)
+	for rows.Next() {
+		var id int
+		var location NullString
+		if err := rows.Scan(&id, &location); err != nil {
+			rows.Close()
+			return err
+		}
+		locations[id] = location
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for id, location := range locations {
+		if err := setJobPlace(q, id, location); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addJobPlaces adds the structured location columns to jobs and fills them in from the
+// locations already entered.
+func addJobPlaces(tx *sql.Tx) error {
+	err := execStatements(
+		`ALTER TABLE jobs ADD COLUMN work_mode TEXT;`,
+		`ALTER TABLE jobs ADD COLUMN city TEXT;`,
+		`ALTER TABLE jobs ADD COLUMN region TEXT;`,
+		`ALTER TABLE jobs ADD COLUMN country TEXT;`,
+	)(tx)
+	if err != nil {
+		return err
+	}
+	return fillPlaces(tx)
+}
+
+// LocationGroup is what applications are grouped by in the locations report.
+type LocationGroup string
+
+const (
+	BY_MODE    LocationGroup = "mode"
+	BY_COUNTRY LocationGroup = "country"
+	BY_REGION  LocationGroup = "region"
+	BY_CITY    LocationGroup = "city"
+)
+
+// LocationGroups are the groupings of the locations report.
+var LocationGroups = []LocationGroup{BY_MODE, BY_COUNTRY, BY_REGION, BY_CITY}
+
+// groupColumns are the columns identifying each group of the locations report.
+var groupColumns = map[LocationGroup][]string{
+	BY_MODE:    {"work_mode"},
+	BY_COUNTRY: {"country"},
+	BY_REGION:  {"region", "country"},
+	BY_CITY:    {"city", "region", "country"},
+}
+
+// LocationCount is the number of applications to jobs in a place, in total and with
+// each status. Place only has the fields of the grouping, and is empty for the
+// applications whose location does not say.
+type LocationCount struct {
+	Place    Place
+	Jobs     int
+	Statuses map[JobStatus]int
+}
+
+// GetLocationCounts counts the applications in each place of the given grouping, the
+// places with the most applications first.
+func GetLocationCounts(sqliteDB *sql.DB, by LocationGroup) ([]*LocationCount, error) {
+	columns, ok := groupColumns[by]
+	if !ok {
+		return nil, fmt.Errorf("Cannot group locations by %q, use mode, country, region or city", by)
+	}
+	list := strings.Join(columns, ", ")
+	rows, err := sqliteDB.Query(`SELECT ` + list + `, status, COUNT(*) FROM jobs GROUP BY ` + list + `, status;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	counts := make(map[Place]*LocationCount)
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		dest := make([]any, 0, len(columns)+2)
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+		var status JobStatus
+		var n int
+		if err := rows.Scan(append(dest, &status, &n)...); err != nil {
+			return nil, err
+		}
+		var place Place
+		for i, column := range columns {
+			switch column {
+			case "work_mode":
+				place.WorkMode = WorkMode(values[i].String)
+			case "city":
+				place.City = values[i].String
+			case "region":
+				place.Region = values[i].String
+			case "country":
+				place.Country = values[i].String
+			}
+		}
+		count := counts[place]
+		if count == nil {
+			count = &LocationCount{Place: place, Statuses: make(map[JobStatus]int)}
+			counts[place] = count
+		}
+		count.Jobs += n
+		count.Statuses[status] += n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	result := make([]*LocationCount, 0, len(counts))
+	for _, count := range counts {
+		result = append(result, count)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		// applications whose location does not say come last
+		if (a.Place == Place{}) != (b.Place == Place{}) {
+			return b.Place == Place{}
+		}
+		if a.Jobs != b.Jobs {
+			return a.Jobs > b.Jobs
+		}
+		return fmt.Sprint(a.Place) < fmt.Sprint(b.Place)
+	})
+	return result, nil
+}
@@ -0,0 +1,45 @@
+package db
+
+import "testing"
+
+func TestParsePlace(t *testing.T) {
+	tests := []struct {
+		text string
+		want *Place
+	}{
+		{"Austin, TX", &Place{City: "Austin", Region: "TX", Country: "US"}},
+		{"Tulsa, OK", &Place{City: "Tulsa", Region: "OK", Country: "US"}},
+		{"Oklahoma City, OK", &Place{City: "Oklahoma City", Region: "OK", Country: "US"}},
+		{"Denver, CO", &Place{City: "Denver", Region: "CO", Country: "US"}},
+		{"Boulder, CO, USA", &Place{City: "Boulder", Region: "CO", Country: "US"}},
+		{"Sunnyvale, CA", &Place{City: "Sunnyvale", Region: "CA", Country: "US"}},
+		{"Toronto, CA", &Place{City: "Toronto", Region: "ON", Country: "CA"}},
+		{"Sydney, NSW", &Place{City: "Sydney", Region: "NSW", Country: "AU"}},
+		{"Berlin, DE", &Place{City: "Berlin", Country: "DE"}},
+		{"Munich, DE", &Place{City: "Munich", Country: "DE"}},
+		{"Bangalore, IN", &Place{City: "Bangalore", Country: "IN"}},
+		{"Chennai, IN", &Place{City: "Chennai", Country: "IN"}},
+		{"Tel Aviv, IL", &Place{City: "Tel Aviv", Country: "IL"}},
+		{"Bogota, CO", &Place{City: "Bogota", Country: "CO"}},
+		{"Lima, PE", &Place{City: "Lima", Country: "PE"}},
+		{"Hybrid (Berlin, DE)", &Place{WorkMode: HYBRID, City: "Berlin", Country: "DE"}},
+		{"DE", &Place{Country: "DE"}},
+		{"London, Canada", &Place{City: "London", Country: "CA"}},
+		{"NYC (hybrid)", &Place{WorkMode: HYBRID, City: "New York", Region: "NY", Country: "US"}},
+		{"Remote - US", &Place{WorkMode: REMOTE, Country: "US"}},
+		{"Remote OK", &Place{WorkMode: REMOTE}},
+		{"Remote (US ok)", &Place{WorkMode: REMOTE, Country: "US"}},
+		{"Remote - OK", &Place{WorkMode: REMOTE, Region: "OK", Country: "US"}},
+		{"Austin, TX (hybrid ok)", &Place{WorkMode: HYBRID, City: "Austin", Region: "TX", Country: "US"}},
+		{"Onsite, Lagos", &Place{WorkMode: ONSITE, City: "Lagos", Country: "NG"}},
+		{"Remote - Europe", &Place{WorkMode: REMOTE}},
+		{"somewhere nice", &Place{City: "somewhere nice"}},
+		{"", nil},
+	}
+	for _, test := range tests {
+		got := ParsePlace(test.text)
+		if (got == nil) != (test.want == nil) || (got != nil && *got != *test.want) {
+			t.Errorf("ParsePlace(%q) = %+v, want %+v", test.text, got, test.want)
+		}
+	}
+}
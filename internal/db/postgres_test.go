@@ -0,0 +1,93 @@
+package db
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestRebind(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`SELECT 1;`, `SELECT 1;`},
+		{`SELECT * FROM jobs WHERE id = ?;`, `SELECT * FROM jobs WHERE id = $1;`},
+		{
+			`UPDATE jobs SET status = ?, updated_at = ? WHERE id = ?;`,
+			`UPDATE jobs SET status = $1, updated_at = $2 WHERE id = $3;`,
+		},
+		{`SELECT id FROM jobs WHERE company = 'Who?' AND id = ?;`, `SELECT id FROM jobs WHERE company = 'Who?' AND id = $1;`},
+		{`SELECT "why?" FROM jobs WHERE id = ?;`, `SELECT "why?" FROM jobs WHERE id = $1;`},
+		// doubled quotes escape a quote, the string goes on
+		{`SELECT 'it''s ?' WHERE a = ?;`, `SELECT 'it''s ?' WHERE a = $1;`},
+		{`SELECT '"?' WHERE a = ? AND b = '?"';`, `SELECT '"?' WHERE a = $1 AND b = '?"';`},
+		{`INSERT INTO tags (name) VALUES ('é?') RETURNING ?;`, `INSERT INTO tags (name) VALUES ('é?') RETURNING $1;`},
+	}
+	for _, test := range tests {
+		if got := rebind(test.query); got != test.want {
+			t.Errorf("rebind(%q) = %q, want %q", test.query, got, test.want)
+		}
+	}
+}
+
+// TestMigratePostgres migrates an empty schema of the PostgreSQL database at
+// JOBTRACK_TEST_DATABASE_URL to the latest version. The schema is dropped afterwards.
+func TestMigratePostgres(t *testing.T) {
+	databaseURL := os.Getenv("JOBTRACK_TEST_DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("JOBTRACK_TEST_DATABASE_URL is not set")
+	}
+	admin, err := GetPostgresConnection(databaseURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer admin.Close()
+	schema := fmt.Sprintf("jobtrack_test_%d", time.Now().UnixNano())
+	if _, err := admin.Exec(`CREATE SCHEMA ` + schema + `;`); err != nil {
+		t.Fatal(err)
+	}
+	defer admin.Exec(`DROP SCHEMA ` + schema + ` CASCADE;`)
+
+	parsed, err := url.Parse(databaseURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := parsed.Query()
+	query.Set("search_path", schema)
+	parsed.RawQuery = query.Encode()
+	postgresDB, err := GetPostgresConnection(parsed.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer postgresDB.Close()
+
+	if err := InitDB(postgresDB); err != nil {
+		t.Fatal(err)
+	}
+	if err := Migrate(postgresDB, LatestVersion()); err != nil {
+		t.Fatal(err)
+	}
+	version, err := SchemaVersion(postgresDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != LatestVersion() {
+		t.Errorf("migrated to version %d, want %d", version, LatestVersion())
+	}
+	// migrating again changes nothing
+	if err := Migrate(postgresDB, LatestVersion()); err != nil {
+		t.Errorf("migrating a migrated database: %v", err)
+	}
+
+	job := addTestJob(t, postgresDB, "Acme", "Developer")
+	got, err := GetJobByID(postgresDB, job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || got.Company != "Acme" || got.UUID != job.UUID {
+		t.Errorf("got %+v back, want the job added at Acme", got)
+	}
+}
@@ -0,0 +1,200 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultProfile is the profile using the database jobtrack has always used, it exists
+// without being created and cannot be deleted or renamed.
+const DefaultProfile = "default"
+
+var (
+	// ErrProfileNotFound is returned for profiles that have not been created.
+	ErrProfileNotFound = errors.New("No profile named")
+	// ErrProfileExists is returned when creating or renaming to a profile that exists.
+	ErrProfileExists = errors.New("A profile with that name already exists")
+)
+
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// profilesDir is where the database of every profile but the default one is kept.
+func profilesDir() string {
+	return filepath.Join(DataDir(), "profiles")
+}
+
+// activeProfileFile holds the name of the profile selected with jobtrack profile use.
+func activeProfileFile() string {
+	return filepath.Join(DataDir(), "profile")
+}
+
+// ValidateProfileName checks that name can be used for a new profile.
+func ValidateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("Invalid profile name %q, use letters, digits, - and _", name)
+	}
+	if strings.EqualFold(name, DefaultProfile) {
+		return fmt.Errorf("%w: %s", ErrProfileExists, name)
+	}
+	return nil
+}
+
+// ProfilePath returns the SQLite database of a profile. The default profile uses
+// defaultPath, the configured database, or DefaultDatabasePath if it is empty.
+func ProfilePath(name string, defaultPath string) string {
+	if name == DefaultProfile {
+		if defaultPath == "" {
+			return DefaultDatabasePath()
+		}
+		return defaultPath
+	}
+	return filepath.Join(profilesDir(), name+".db")
+}
+
+// Profiles returns the names of every profile, the default one first and the rest sorted.
+func Profiles() ([]string, error) {
+	profiles := []string{DefaultProfile}
+	entries, err := os.ReadDir(profilesDir())
+	if errors.Is(err, fs.ErrNotExist) {
+		return profiles, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if name, ok := strings.CutSuffix(entry.Name(), ".db"); ok && !entry.IsDir() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return append(profiles, names...), nil
+}
+
+// ProfileExists reports whether a profile has been created.
+func ProfileExists(name string) bool {
+	if name == DefaultProfile {
+		return true
+	}
+	if !profileNamePattern.MatchString(name) {
+		return false
+	}
+	_, err := os.Stat(ProfilePath(name, ""))
+	return err == nil
+}
+
+// ActiveProfile returns the profile selected with SetActiveProfile, or the default one.
+func ActiveProfile() string {
+	data, err := os.ReadFile(activeProfileFile())
+	if err != nil {
+		return DefaultProfile
+	}
+	name := strings.TrimSpace(string(data))
+	if name == "" || !ProfileExists(name) {
+		return DefaultProfile
+	}
+	return name
+}
+
+// SetActiveProfile makes jobtrack use the given profile until another one is selected.
+func SetActiveProfile(name string) error {
+	if !ProfileExists(name) {
+		return fmt.Errorf("%w %q", ErrProfileNotFound, name)
+	}
+	if err := os.MkdirAll(DataDir(), os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(activeProfileFile(), []byte(name+"\n"), 0o644)
+}
+
+// CreateProfile creates the database of a new profile with the latest schema.
+func CreateProfile(name string) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	if ProfileExists(name) {
+		return fmt.Errorf("%w: %s", ErrProfileExists, name)
+	}
+	sqliteDB, err := GetConnection(ProfilePath(name, ""))
+	if err != nil {
+		return err
+	}
+	defer sqliteDB.Close()
+	if err := InitDB(sqliteDB); err != nil {
+		return err
+	}
+	return Migrate(sqliteDB, LatestVersion())
+}
+
+// DeleteProfile removes a profile along with its database. The default profile and the
+// active one cannot be deleted.
+func DeleteProfile(name string) error {
+	if name == DefaultProfile {
+		return errors.New("The default profile cannot be deleted")
+	}
+	if !ProfileExists(name) {
+		return fmt.Errorf("%w %q", ErrProfileNotFound, name)
+	}
+	if ActiveProfile() == name {
+		return fmt.Errorf("Profile %q is in use, switch to another one with jobtrack profile use first", name)
+	}
+	path := ProfilePath(name, "")
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(path + suffix); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return os.Remove(path)
+}
+
+// RenameProfile renames a profile, keeping it active if it was.
+func RenameProfile(oldName string, newName string) error {
+	if oldName == DefaultProfile {
+		return errors.New("The default profile cannot be renamed")
+	}
+	if !ProfileExists(oldName) {
+		return fmt.Errorf("%w %q", ErrProfileNotFound, oldName)
+	}
+	if err := ValidateProfileName(newName); err != nil {
+		return err
+	}
+	if ProfileExists(newName) {
+		return fmt.Errorf("%w: %s", ErrProfileExists, newName)
+	}
+	wasActive := ActiveProfile() == oldName
+	oldPath, newPath := ProfilePath(oldName, ""), ProfilePath(newName, "")
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Rename(oldPath+suffix, newPath+suffix); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	if err := os.Rename(oldPath, newPath); err != nil {
+		return err
+	}
+	if wasActive {
+		return SetActiveProfile(newName)
+	}
+	return nil
+}
+
+// CountProfileJobs returns how many jobs the profile database at path holds, 0 if it
+// has not been created yet.
+func CountProfileJobs(path string) (int, error) {
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	sqliteDB, err := GetConnection(path)
+	if err != nil {
+		return 0, err
+	}
+	defer sqliteDB.Close()
+	var count int
+	err = sqliteDB.QueryRow(`SELECT COUNT(*) FROM jobs;`).Scan(&count)
+	return count, err
+}
@@ -0,0 +1,272 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// jobColumns lists the columns of the jobs table in the order the row parsers scan them.
+const jobColumns = `id, company, position, status, location, salary_range, job_posting_url, applied_at, created_at, updated_at,
+	follow_up_on, uuid, salary_min, salary_max, salary_currency, salary_period, salary_basis,
+	work_mode, city, region, country, company_id`
+
+// sortColumns maps the field names accepted by ParseSort to their columns.
+var sortColumns = map[string]string{
+	"id":        "id",
+	"company":   "company",
+	"position":  "position",
+	"status":    "status",
+	"location":  "location",
+	"applied":   "applied_at",
+	"created":   "created_at",
+	"updated":   "updated_at",
+	"follow-up": "follow_up_on",
+	"salary":    annualSalaryMax,
+	"country":   "country",
+	"city":      "city",
+}
+
+// SortField orders query results by a single column.
+type SortField struct {
+	Column     string
+	Descending bool
+}
+
+// JobQuery describes which jobs to list and how. Every filter that is set must match,
+// zero values leave the corresponding filter out.
+type JobQuery struct {
+	Statuses      []JobStatus
+	AppliedAfter  *time.Time
+	AppliedBefore *time.Time
+	// Company, Position and Location match case-insensitive substrings.
+	Company  string
+	Position string
+	Location string
+	// CompanyID matches the jobs at a company, whatever spelling of its name they were
+	// entered with.
+	CompanyID int
+	// WorkMode, Country and City match the parsed location of jobs. Country is an ISO
+	// 3166 code, and City matches ignoring case.
+	WorkMode WorkMode
+	Country  string
+	City     string
+	// Contact matches jobs linked to a contact with this ID or whose name contains it.
+	Contact string
+	// Tags matches jobs that have every one of these tags, or any of them with AnyTag.
+	Tags   []string
+	AnyTag bool
+	// Fields matches jobs whose custom fields have every one of these values.
+	Fields []FieldFilter
+	// MinSalary matches jobs paying at least this much a year at the top of their range,
+	// and Currency jobs paid in this currency. Jobs without a parsed salary never match.
+	MinSalary float64
+	Currency  string
+	// Conversions, when set, convert salaries to a common currency for MinSalary and the
+	// salary sort. Jobs paid in a currency without a conversion are then left out by
+	// MinSalary and sorted like jobs without a salary.
+	Conversions map[string]*Conversion
+	// Sort defaults to the applied date, oldest first.
+	Sort   []SortField
+	Limit  int
+	Offset int
+}
+
+// queryBuilder collects the conditions of a WHERE clause together with their parameters.
+type queryBuilder struct {
+	conditions []string
+	params     []any
+}
+
+func (b *queryBuilder) where(condition string, params ...any) {
+	b.conditions = append(b.conditions, condition)
+	b.params = append(b.params, params...)
+}
+
+func (b *queryBuilder) clause() string {
+	if len(b.conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(b.conditions, " AND ")
+}
+
+// likePattern escapes the LIKE wildcards in s and wraps it for a substring match.
+func likePattern(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + replacer.Replace(s) + "%"
+}
+
+// ParseSort parses a comma separated list of sort fields formatted field[:asc|desc],
+// for example "status,applied:desc".
+func ParseSort(s string) ([]SortField, error) {
+	var fields []SortField
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, direction, _ := strings.Cut(part, ":")
+		column, ok := sortColumns[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf(
+				"Cannot sort by %q, valid fields are: id, company, position, status, location, country, city, salary, applied, created, updated, follow-up",
+				name,
+			)
+		}
+		field := SortField{Column: column}
+		switch strings.ToLower(direction) {
+		case "", "asc":
+		case "desc":
+			field.Descending = true
+		default:
+			return nil, fmt.Errorf("Invalid sort direction %q, use asc or desc", direction)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+func isSortColumn(column string) bool {
+	for _, c := range sortColumns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+// build returns the SELECT statement and parameters for the query.
+func (q JobQuery) build() (string, []any, error) {
+	var b queryBuilder
+	if len(q.Statuses) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(q.Statuses)), ", ")
+		params := make([]any, 0, len(q.Statuses))
+		for _, status := range q.Statuses {
+			params = append(params, status)
+		}
+		b.where("status IN ("+placeholders+")", params...)
+	}
+	if q.AppliedAfter != nil && q.AppliedBefore != nil && q.AppliedAfter.After(*q.AppliedBefore) {
+		return "", nil, errors.New("The after date must not be later than the before date")
+	}
+	if q.AppliedAfter != nil {
+		b.where("applied_at >= ?", FormatDateTime(*q.AppliedAfter, true))
+	}
+	if q.AppliedBefore != nil {
+		b.where("applied_at <= ?", FormatDateTime(*q.AppliedBefore, true))
+	}
+	if q.Company != "" {
+		b.where(`lower(company) LIKE lower(?) ESCAPE '\'`, likePattern(q.Company))
+	}
+	if q.Position != "" {
+		b.where(`lower(position) LIKE lower(?) ESCAPE '\'`, likePattern(q.Position))
+	}
+	if q.Location != "" {
+		b.where(`lower(location) LIKE lower(?) ESCAPE '\'`, likePattern(q.Location))
+	}
+	if q.WorkMode != "" {
+		b.where("work_mode = ?", q.WorkMode)
+	}
+	if q.CompanyID != 0 {
+		b.where("company_id = ?", q.CompanyID)
+	}
+	if q.Country != "" {
+		b.where("country = ?", q.Country)
+	}
+	if q.City != "" {
+		b.where("lower(city) = lower(?)", q.City)
+	}
+	if q.Contact != "" {
+		b.where(
+			`id IN (SELECT job_contacts.job_id FROM job_contacts
+				JOIN contacts ON contacts.id = job_contacts.contact_id
+				WHERE CAST(contacts.id AS TEXT) = ? OR lower(contacts.name) LIKE lower(?) ESCAPE '\')`,
+			q.Contact,
+			likePattern(q.Contact),
+		)
+	}
+	if len(q.Tags) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(q.Tags)), ", ")
+		params := make([]any, 0, len(q.Tags)+1)
+		for _, tag := range q.Tags {
+			params = append(params, tag)
+		}
+		condition := `id IN (SELECT job_tags.job_id FROM job_tags
+			JOIN tags ON tags.id = job_tags.tag_id
+			WHERE tags.name IN (` + placeholders + `)`
+		if !q.AnyTag {
+			condition += ` GROUP BY job_tags.job_id HAVING COUNT(*) = ?`
+			params = append(params, len(q.Tags))
+		}
+		b.where(condition+")", params...)
+	}
+	for _, field := range q.Fields {
+		b.where(
+			`id IN (SELECT job_id FROM job_fields WHERE name = ? AND lower(value) = lower(?))`,
+			field.Name,
+			field.Value,
+		)
+	}
+	salaryMax, salaryParams := annualSalaryMax, []any(nil)
+	if q.Conversions != nil {
+		salaryMax, salaryParams = convertedSalaryMax(q.Conversions)
+	}
+	if q.MinSalary > 0 {
+		b.where(salaryMax+" >= ?", append(salaryParams, q.MinSalary)...)
+	}
+	if q.Currency != "" {
+		b.where("salary_currency = ?", q.Currency)
+	}
+	if q.Limit < 0 || q.Offset < 0 {
+		return "", nil, errors.New("Limit and offset must not be negative")
+	}
+
+	sort := q.Sort
+	if len(sort) == 0 {
+		sort = []SortField{{Column: "applied_at"}}
+	}
+	var order []string
+	var orderParams []any
+	for _, field := range sort {
+		if !isSortColumn(field.Column) {
+			return "", nil, fmt.Errorf("Cannot sort by unknown column %q", field.Column)
+		}
+		column := field.Column
+		if column == annualSalaryMax {
+			column = salaryMax
+			orderParams = append(orderParams, salaryParams...)
+		}
+		if field.Descending {
+			order = append(order, column+" DESC NULLS LAST")
+		} else {
+			order = append(order, column+" ASC NULLS FIRST")
+		}
+	}
+	// id breaks ties so paginated results are stable
+	order = append(order, "id ASC")
+
+	query := "SELECT " + jobColumns + " FROM jobs" + b.clause() + " ORDER BY " + strings.Join(order, ", ")
+	params := append(b.params, orderParams...)
+	if q.Limit > 0 || q.Offset > 0 {
+		// SQLite requires a LIMIT before OFFSET, so no limit is the largest one
+		limit := int64(q.Limit)
+		if limit == 0 {
+			limit = math.MaxInt64
+		}
+		query += " LIMIT ? OFFSET ?"
+		params = append(params, limit, q.Offset)
+	}
+	return query + ";", params, nil
+}
+
+// QueryJobs returns the jobs matching every filter of the query, in the requested order.
+func QueryJobs(sqliteDB *sql.DB, q JobQuery) ([]*Job, error) {
+	query, params, err := q.build()
+	if err != nil {
+		return nil, err
+	}
+	return getJobs(sqliteDB, query, params...)
+}
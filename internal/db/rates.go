@@ -0,0 +1,316 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExchangeRate is the value of one unit of Currency in Base from the day it takes effect
+// until a later rate replaces it. Rates are entered by the user, jobtrack never fetches
+// them, so conversions work offline.
+type ExchangeRate struct {
+	ID          int        `json:"id"`
+	Currency    string     `json:"currency"`
+	Base        string     `json:"base"`
+	Rate        float64    `json:"rate"`
+	EffectiveOn *time.Time `json:"effective_on"`
+}
+
+// Conversion converts amounts of one currency to another. RatesOn is when the oldest rate
+// it was worked out from took effect, nil when no rate was needed.
+type Conversion struct {
+	Rate    float64
+	RatesOn *time.Time
+}
+
+// ConvertedSalary is a salary converted to another currency. It is stale when the rates
+// used were older than wanted.
+type ConvertedSalary struct {
+	Salary
+	RatesOn *time.Time `json:"rates_on,omitempty"`
+	Stale   bool       `json:"stale,omitempty"`
+}
+
+// ValidateExchangeRate normalises the currencies of a rate, checking that they are known
+// and different and that the rate is positive. The rate takes effect today if no date
+// is given.
+func ValidateExchangeRate(rate *ExchangeRate) error {
+	var err error
+	if rate.Currency, err = NormalizeCurrency(rate.Currency); err != nil {
+		return err
+	}
+	if rate.Base, err = NormalizeCurrency(rate.Base); err != nil {
+		return err
+	}
+	if rate.Currency == rate.Base {
+		return fmt.Errorf("Cannot set a rate of %s in itself", rate.Currency)
+	}
+	if math.IsNaN(rate.Rate) || math.IsInf(rate.Rate, 0) || rate.Rate <= 0 {
+		return fmt.Errorf("The rate of %s must be a number greater than 0", rate.Currency)
+	}
+	if rate.EffectiveOn == nil {
+		today := time.Now()
+		rate.EffectiveOn = &today
+	}
+	return nil
+}
+
+// SetExchangeRates stores rates, replacing those of the same currencies taking effect on
+// the same day.
+func SetExchangeRates(sqliteDB *sql.DB, rates []*ExchangeRate) error {
+	const upsertQuery = `INSERT INTO exchange_rates (currency, base, rate, effective_on) VALUES (?, ?, ?, ?)
+		ON CONFLICT (currency, base, effective_on) DO UPDATE SET rate = excluded.rate
+		RETURNING id;`
+	for _, rate := range rates {
+		if err := ValidateExchangeRate(rate); err != nil {
+			return err
+		}
+	}
+	tx, err := sqliteDB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, rate := range rates {
+		err := tx.QueryRow(
+			upsertQuery,
+			rate.Currency,
+			rate.Base,
+			rate.Rate,
+			FormatDateTime(*rate.EffectiveOn, true),
+		).Scan(&rate.ID)
+		if err != nil {
+			return fmt.Errorf("Error setting exchange rate: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// GetExchangeRates returns every rate entered, by currency and newest first.
+func GetExchangeRates(sqliteDB *sql.DB) ([]*ExchangeRate, error) {
+	return getExchangeRates(sqliteDB, `SELECT id, currency, base, rate, effective_on FROM exchange_rates
+		ORDER BY currency ASC, base ASC, effective_on DESC;`)
+}
+
+func getExchangeRates(sqliteDB *sql.DB, query string, params ...any) ([]*ExchangeRate, error) {
+	rows, err := sqliteDB.Query(query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var rates []*ExchangeRate
+	for rows.Next() {
+		var rate ExchangeRate
+		var effectiveOn string
+		if err := rows.Scan(&rate.ID, &rate.Currency, &rate.Base, &rate.Rate, &effectiveOn); err != nil {
+			return nil, err
+		}
+		rate.EffectiveOn, _ = ParseDateTime(effectiveOn, true)
+		rates = append(rates, &rate)
+	}
+	return rates, rows.Err()
+}
+
+// GetConversions works out how to convert every currency with a rate to target, using the
+// rates in effect on the given day. A currency is converted with a rate from it to target,
+// from target to it, or through a third currency both have a rate in. When several ways
+// exist the one whose oldest rate is the most recent wins, and a direct rate wins ties.
+func GetConversions(sqliteDB *sql.DB, target string, on time.Time) (map[string]*Conversion, error) {
+	rates, err := getExchangeRates(sqliteDB, `SELECT id, currency, base, rate, effective_on FROM exchange_rates
+		WHERE effective_on <= ? ORDER BY effective_on DESC;`, FormatDateTime(on, true))
+	if err != nil {
+		return nil, err
+	}
+
+	// the rates in effect between every pair of currencies, both ways round
+	edges := make(map[string]map[string]*Conversion)
+	addEdge := func(from string, to string, conversion *Conversion) {
+		if edges[from] == nil {
+			edges[from] = make(map[string]*Conversion)
+		}
+		if existing := edges[from][to]; existing == nil || existing.RatesOn.Before(*conversion.RatesOn) {
+			edges[from][to] = conversion
+		}
+	}
+	for _, rate := range rates {
+		addEdge(rate.Currency, rate.Base, &Conversion{Rate: rate.Rate, RatesOn: rate.EffectiveOn})
+		addEdge(rate.Base, rate.Currency, &Conversion{Rate: 1 / rate.Rate, RatesOn: rate.EffectiveOn})
+	}
+
+	conversions := map[string]*Conversion{target: {Rate: 1}}
+	for from, tos := range edges {
+		if from == target {
+			continue
+		}
+		if direct, ok := tos[target]; ok {
+			conversions[from] = direct
+		}
+		vias := make([]string, 0, len(tos))
+		for via := range tos {
+			vias = append(vias, via)
+		}
+		sort.Strings(vias)
+		for _, via := range vias {
+			first := tos[via]
+			second, ok := edges[via][target]
+			if !ok {
+				continue
+			}
+			ratesOn := first.RatesOn
+			if second.RatesOn.Before(*ratesOn) {
+				ratesOn = second.RatesOn
+			}
+			if existing := conversions[from]; existing == nil || existing.RatesOn.Before(*ratesOn) {
+				conversions[from] = &Conversion{Rate: first.Rate * second.Rate, RatesOn: ratesOn}
+			}
+		}
+	}
+	return conversions, nil
+}
+
+// ConvertSalaries sets the ConvertedSalary of every job with a salary in a currency that
+// can be converted, marking it stale if the rates used took effect before staleBefore.
+// It returns the number of jobs whose salary could not be converted.
+func ConvertSalaries(jobs []*Job, conversions map[string]*Conversion, target string, staleBefore time.Time) int {
+	missing := 0
+	for _, job := range jobs {
+		job.ConvertedSalary = nil
+		if job.Salary == nil {
+			continue
+		}
+		conversion, ok := conversions[job.Salary.Currency]
+		if !ok {
+			missing++
+			continue
+		}
+		converted := &ConvertedSalary{Salary: *job.Salary, RatesOn: conversion.RatesOn}
+		// hourly pay keeps its cents, larger amounts are rounded to whole units
+		precision := 1.0
+		if converted.Period == HOURLY {
+			precision = 100
+		}
+		converted.Min = math.Round(converted.Min*conversion.Rate*precision) / precision
+		converted.Max = math.Round(converted.Max*conversion.Rate*precision) / precision
+		converted.Currency = target
+		converted.Stale = conversion.RatesOn != nil && conversion.RatesOn.Before(staleBefore)
+		job.ConvertedSalary = converted
+	}
+	return missing
+}
+
+// conversionRates returns the conversion rate of each currency, sorted by currency so
+// queries using them are always written the same.
+func conversionRates(conversions map[string]*Conversion) ([]string, []float64) {
+	currencies := make([]string, 0, len(conversions))
+	for currency := range conversions {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	rates := make([]float64, len(currencies))
+	for i, currency := range currencies {
+		rates[i] = conversions[currency].Rate
+	}
+	return currencies, rates
+}
+
+// convertedSalaryMax returns the SQL expression of a job's maximum yearly salary in the
+// currency of the conversions, along with its parameters. It is NULL for jobs paid in a
+// currency without a conversion.
+func convertedSalaryMax(conversions map[string]*Conversion) (string, []any) {
+	currencies, rates := conversionRates(conversions)
+	if len(currencies) == 0 {
+		return "NULL", nil
+	}
+	var b strings.Builder
+	params := make([]any, 0, 2*len(currencies))
+	b.WriteString("(" + annualSalaryMax + " * CASE salary_currency")
+	for i, currency := range currencies {
+		b.WriteString(" WHEN ? THEN ?")
+		params = append(params, currency, rates[i])
+	}
+	b.WriteString(" END)")
+	return b.String(), params
+}
+
+// ReadExchangeRates reads rates from a CSV file with a currency, rate and effective date
+// column, and optionally a base column. A header row naming the columns is optional,
+// without one they must be in that order. Rates without a base are in base.
+func ReadExchangeRates(r io.Reader, base string) ([]*ExchangeRate, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	columns := map[string]int{"currency": 0, "rate": 1, "date": 2, "base": 3}
+	var rates []*ExchangeRate
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		if first {
+			record[0] = strings.TrimPrefix(record[0], "\ufeff")
+			if header, ok := rateColumns(record); ok {
+				columns = header
+				continue
+			}
+		}
+		value := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		rate := &ExchangeRate{Currency: value("currency"), Base: value("base")}
+		if rate.Base == "" {
+			rate.Base = base
+		}
+		if rate.Rate, err = strconv.ParseFloat(value("rate"), 64); err != nil {
+			return nil, fmt.Errorf("Line %d: invalid rate %q", line, value("rate"))
+		}
+		if date := value("date"); date != "" {
+			if rate.EffectiveOn, err = ParseDateTime(date, true); err != nil {
+				return nil, fmt.Errorf("Line %d: invalid date %q, use YYYY-MM-DD", line, date)
+			}
+		}
+		if err := ValidateExchangeRate(rate); err != nil {
+			return nil, fmt.Errorf("Line %d: %w", line, err)
+		}
+		rates = append(rates, rate)
+	}
+	if len(rates) == 0 {
+		return nil, errors.New("No exchange rates found in the file")
+	}
+	return rates, nil
+}
+
+// rateColumns reads a header row of an exchange rates file, reporting whether the row
+// is one, that is whether it names the currency and rate columns.
+func rateColumns(record []string) (map[string]int, bool) {
+	columns := make(map[string]int)
+	for i, name := range record {
+		switch csvColumnKey(name) {
+		case "currency", "code":
+			columns["currency"] = i
+		case "rate":
+			columns["rate"] = i
+		case "date", "effective", "effectiveon", "effectivedate":
+			columns["date"] = i
+		case "base", "basecurrency":
+			columns["base"] = i
+		}
+	}
+	_, currency := columns["currency"]
+	_, rate := columns["rate"]
+	return columns, currency && rate
+}
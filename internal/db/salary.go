@@ -0,0 +1,300 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/currency"
+)
+
+// SalaryPeriod is how often a salary is paid.
+type SalaryPeriod string
+
+// SalaryBasis tells a base salary apart from a total compensation figure.
+type SalaryBasis string
+
+const (
+	HOURLY  SalaryPeriod = "hourly"
+	MONTHLY SalaryPeriod = "monthly"
+	ANNUAL  SalaryPeriod = "annual"
+
+	BASE  SalaryBasis = "base"
+	TOTAL SalaryBasis = "total"
+)
+
+// Hours and months in a year, used to compare hourly and monthly pay with annual pay.
+const (
+	hoursPerYear  = 2080
+	monthsPerYear = 12
+)
+
+// Salary is the structured form of a job's salary range, parsed from the text entered.
+// Min and Max are equal when a single amount was given.
+type Salary struct {
+	Min      float64      `json:"min"`
+	Max      float64      `json:"max"`
+	Currency string       `json:"currency"`
+	Period   SalaryPeriod `json:"period"`
+	Basis    SalaryBasis  `json:"basis"`
+}
+
+// Annual returns the minimum and maximum of the salary as yearly amounts.
+func (s *Salary) Annual() (float64, float64) {
+	factor := 1.0
+	switch s.Period {
+	case HOURLY:
+		factor = hoursPerYear
+	case MONTHLY:
+		factor = monthsPerYear
+	}
+	return s.Min * factor, s.Max * factor
+}
+
+// defaultCurrency is the currency of salaries entered without one.
+var defaultCurrency = "USD"
+
+// SetDefaultCurrency sets the currency of salaries entered without one.
+func SetDefaultCurrency(code string) error {
+	code, err := NormalizeCurrency(code)
+	if err != nil {
+		return err
+	}
+	defaultCurrency = code
+	return nil
+}
+
+// NormalizeCurrency uppercases an ISO 4217 currency code, returning an error if it is not
+// a known currency.
+func NormalizeCurrency(code string) (string, error) {
+	unit, err := currency.ParseISO(strings.TrimSpace(code))
+	if err != nil {
+		return "", fmt.Errorf("Unknown currency %q, use an ISO 4217 code such as USD or EUR", code)
+	}
+	return unit.String(), nil
+}
+
+// currencySymbols maps the symbols written with amounts to their currency. Longer
+// symbols come first so C$ is not read as $. Symbols shared by several currencies, such
+// as kr and Rs, are left out.
+var currencySymbols = []struct {
+	symbol string
+	code   string
+}{
+	{"us$", "USD"}, {"ca$", "CAD"}, {"au$", "AUD"}, {"nz$", "NZD"}, {"hk$", "HKD"},
+	{"mx$", "MXN"}, {"nt$", "TWD"}, {"ec$", "XCD"}, {"cn¥", "CNY"},
+	{"c$", "CAD"}, {"a$", "AUD"}, {"s$", "SGD"}, {"r$", "BRL"}, {"e£", "EGP"}, {"l£", "LBP"},
+	{"zł", "PLN"}, {"kč", "CZK"},
+	{"$", "USD"}, {"€", "EUR"}, {"£", "GBP"}, {"¥", "JPY"}, {"₹", "INR"},
+	{"₦", "NGN"}, {"₩", "KRW"}, {"₪", "ILS"}, {"₫", "VND"}, {"₱", "PHP"}, {"₽", "RUB"},
+	{"₺", "TRY"}, {"₴", "UAH"}, {"₸", "KZT"}, {"₭", "LAK"}, {"₮", "MNT"}, {"₲", "PYG"},
+	{"₾", "GEL"}, {"₡", "CRC"}, {"৳", "BDT"}, {"฿", "THB"}, {"៛", "KHR"},
+}
+
+// commonCurrencies are the codes recognised in lowercase text. Other codes must be
+// written in uppercase, as plenty of them, such as ALL and TOP, are also words.
+var commonCurrencies = map[string]bool{
+	"usd": true, "eur": true, "gbp": true, "cad": true, "aud": true, "nzd": true,
+	"chf": true, "jpy": true, "inr": true, "sgd": true, "sek": true, "nok": true, "dkk": true,
+}
+
+// The words naming each period and total compensation, matched against the words of
+// the salary text.
+var (
+	hourlyWords  = []string{"hourly", "hour", "hours", "hr", "hrs", "h"}
+	monthlyWords = []string{"monthly", "month", "months", "mo", "mth"}
+	totalWords   = []string{"total", "tc", "ote", "tcc"}
+)
+
+// salaryAmounts matches an amount or a range of amounts, each with an optional k, m,
+// lakh or crore suffix, such as 120k, 1.2m, 7.5 LPA or 120,000 - $150,000.
+var salaryAmounts = regexp.MustCompile(
+	`(\d+(?:\.\d+)?)\s*` + amountSuffix + `?(?:\s*(?:-|–|—|\bto\b)\s*(?:[a-z]{1,3}\s*)?[$€£¥₹₦₩₪₫₱₽₺₴₸₭₮₲₾₡৳฿៛]?\s*(\d+(?:\.\d+)?)\s*` + amountSuffix + `?)?`,
+)
+
+// amountSuffix matches the suffixes multiplying an amount. Lakhs (100,000) and crores
+// (10,000,000) are how salaries are written in India, LPA being lakhs per annum.
+const amountSuffix = `((?:k|m|lpa|lakhs?|lacs?|cr|crores?)\b)`
+
+// thousandsSeparator matches the commas, periods, spaces and apostrophes grouping the
+// digits of an amount, such as in 120,000, €45.000, 45 000 or CHF 120'000. A period,
+// space or apostrophe must be followed by exactly three digits, so 1.5k stays a decimal.
+var thousandsSeparator = regexp.MustCompile(`(\d)(?:,(\d{3})|[. '’\x{a0}\x{202f}](\d{3})(\D|$))`)
+
+// ParseSalary reads the amounts, currency, period and basis of a salary written as
+// free text, such as "400k", "$120,000 - $150,000", "€45/hr" or "200-250k TC". It
+// returns nil when the text has no amount in it. Salaries are annual and base pay
+// unless the text says otherwise, and use the default currency if none is given.
+func ParseSalary(text string) *Salary {
+	lower := strings.ToLower(text)
+	for thousandsSeparator.MatchString(lower) {
+		lower = thousandsSeparator.ReplaceAllString(lower, "$1$2$3$4")
+	}
+	match := salaryAmounts.FindStringSubmatch(lower)
+	if match == nil {
+		return nil
+	}
+	low, lowSuffix := parseAmount(match[1], match[2])
+	high, highSuffix := low, lowSuffix
+	if match[3] != "" {
+		high, highSuffix = parseAmount(match[3], match[4])
+		// in 120-150k the suffix applies to both amounts
+		if lowSuffix == 1 && highSuffix > 1 && low < 1000 {
+			low *= highSuffix
+		}
+	}
+	if high == 0 {
+		return nil
+	}
+	if low > high {
+		low, high = high, low
+	}
+
+	currency := salaryCurrency(text, lower)
+	if currency == "" {
+		currency = defaultCurrency
+		// lakhs and crores are rupees unless the text says otherwise
+		if slices.Contains([]float64{lakh, crore}, lowSuffix) || slices.Contains([]float64{lakh, crore}, highSuffix) {
+			currency = "INR"
+		}
+	}
+	salary := &Salary{Min: low, Max: high, Currency: currency, Period: ANNUAL, Basis: BASE}
+	words := strings.FieldsFunc(lower, func(r rune) bool { return !unicode.IsLetter(r) })
+	for _, word := range words {
+		switch {
+		case containsWord(hourlyWords, word):
+			salary.Period = HOURLY
+		case containsWord(monthlyWords, word):
+			salary.Period = MONTHLY
+		case containsWord(totalWords, word):
+			salary.Basis = TOTAL
+		}
+	}
+	return salary
+}
+
+// The multipliers of a lakh and a crore.
+const (
+	lakh  = 100000
+	crore = 10000000
+)
+
+// parseAmount parses the digits of an amount and applies its suffix, returning the
+// amount and the suffix's multiplier.
+func parseAmount(digits string, suffix string) (float64, float64) {
+	amount, _ := strconv.ParseFloat(digits, 64)
+	multiplier := 1.0
+	switch suffix {
+	case "k":
+		multiplier = 1000
+	case "m":
+		multiplier = 1000000
+	case "lpa", "lakh", "lakhs", "lac", "lacs":
+		multiplier = lakh
+	case "cr", "crore", "crores":
+		multiplier = crore
+	}
+	return amount * multiplier, multiplier
+}
+
+// salaryCurrency finds the currency of a salary from an ISO code or a symbol in it,
+// returning "" if it has neither.
+func salaryCurrency(text string, lower string) string {
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool { return !unicode.IsLetter(r) }) {
+		if len(word) != 3 || (word != strings.ToUpper(word) && !commonCurrencies[strings.ToLower(word)]) {
+			continue
+		}
+		if code, err := NormalizeCurrency(word); err == nil {
+			return code
+		}
+	}
+	for _, symbol := range currencySymbols {
+		if strings.Contains(lower, symbol.symbol) {
+			return symbol.code
+		}
+	}
+	return ""
+}
+
+func containsWord(words []string, word string) bool {
+	for _, w := range words {
+		if w == word {
+			return true
+		}
+	}
+	return false
+}
+
+// salaryValues returns the values of the structured salary columns for a salary range,
+// all nil if it cannot be parsed.
+func salaryValues(salaryRange NullString) []any {
+	var salary *Salary
+	if salaryRange.Valid {
+		salary = ParseSalary(salaryRange.String)
+	}
+	if salary == nil {
+		return []any{nil, nil, nil, nil, nil}
+	}
+	return []any{salary.Min, salary.Max, salary.Currency, string(salary.Period), string(salary.Basis)}
+}
+
+// setJobSalary stores the structured form of a job's salary range.
+func setJobSalary(q querier, jobID int, salaryRange NullString) error {
+	const salaryQuery = `UPDATE jobs SET
+		salary_min = ?, salary_max = ?, salary_currency = ?, salary_period = ?, salary_basis = ?
+		WHERE id = ?;`
+	_, err := q.Exec(salaryQuery, append(salaryValues(salaryRange), jobID)...)
+	return err
+}
+
+// fillSalaries parses the salary ranges that have no structured salary yet.
+func fillSalaries(q querier) error {
+	rows, err := q.Query(`SELECT id, salary_range FROM jobs
+		WHERE salary_range IS NOT NULL AND salary_min IS NULL;`)
+	if err != nil {
+		return err
+	}
+	ranges := make(map[int]NullString)
+	for rows.Next() {
+		var id int
+		var salaryRange NullString
+		if err := rows.Scan(&id, &salaryRange); err != nil {
+			rows.Close()
+			return err
+		}
+		ranges[id] = salaryRange
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for id, salaryRange := range ranges {
+		if err := setJobSalary(q, id, salaryRange); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addJobSalaries adds the structured salary columns to jobs and fills them in from the
+// salary ranges already entered.
+func addJobSalaries(tx *sql.Tx) error {
+	err := execStatements(
+		`ALTER TABLE jobs ADD COLUMN salary_min DOUBLE PRECISION;`,
+		`ALTER TABLE jobs ADD COLUMN salary_max DOUBLE PRECISION;`,
+		`ALTER TABLE jobs ADD COLUMN salary_currency TEXT;`,
+		`ALTER TABLE jobs ADD COLUMN salary_period TEXT;`,
+		`ALTER TABLE jobs ADD COLUMN salary_basis TEXT;`,
+	)(tx)
+	if err != nil {
+		return err
+	}
+	return fillSalaries(tx)
+}
+
+// annualSalaryMax is the SQL expression of a job's maximum salary as a yearly amount.
+const annualSalaryMax = `(salary_max * CASE salary_period WHEN 'hourly' THEN 2080 WHEN 'monthly' THEN 12 ELSE 1 END)`
@@ -0,0 +1,63 @@
+package db
+
+import "testing"
+
+func TestParseSalary(t *testing.T) {
+	tests := []struct {
+		text string
+		want *Salary
+	}{
+		// the examples of the doc comment
+		{"400k", &Salary{Min: 400000, Max: 400000, Currency: "USD", Period: ANNUAL, Basis: BASE}},
+		{"$120,000 - $150,000", &Salary{Min: 120000, Max: 150000, Currency: "USD", Period: ANNUAL, Basis: BASE}},
+		{"€45/hr", &Salary{Min: 45, Max: 45, Currency: "EUR", Period: HOURLY, Basis: BASE}},
+		{"200-250k TC", &Salary{Min: 200000, Max: 250000, Currency: "USD", Period: ANNUAL, Basis: TOTAL}},
+
+		// suffixes, ranges, periods and bases
+		{"1.2m", &Salary{Min: 1200000, Max: 1200000, Currency: "USD", Period: ANNUAL, Basis: BASE}},
+		{"120k to 150k", &Salary{Min: 120000, Max: 150000, Currency: "USD", Period: ANNUAL, Basis: BASE}},
+		{"150k – 120k", &Salary{Min: 120000, Max: 150000, Currency: "USD", Period: ANNUAL, Basis: BASE}},
+		{"1.5k/hr", &Salary{Min: 1500, Max: 1500, Currency: "USD", Period: HOURLY, Basis: BASE}},
+		{"£4,000 per month", &Salary{Min: 4000, Max: 4000, Currency: "GBP", Period: MONTHLY, Basis: BASE}},
+		{"300k OTE", &Salary{Min: 300000, Max: 300000, Currency: "USD", Period: ANNUAL, Basis: TOTAL}},
+		{"7.5 LPA", &Salary{Min: 750000, Max: 750000, Currency: "INR", Period: ANNUAL, Basis: BASE}},
+		{"7-10 lakhs", &Salary{Min: 700000, Max: 1000000, Currency: "INR", Period: ANNUAL, Basis: BASE}},
+		{"₹1.2 crore", &Salary{Min: 12000000, Max: 12000000, Currency: "INR", Period: ANNUAL, Basis: BASE}},
+
+		// currencies written as codes
+		{"CHF 120k", &Salary{Min: 120000, Max: 120000, Currency: "CHF", Period: ANNUAL, Basis: BASE}},
+		{"90k eur", &Salary{Min: 90000, Max: 90000, Currency: "EUR", Period: ANNUAL, Basis: BASE}},
+		{"90k all in", &Salary{Min: 90000, Max: 90000, Currency: "USD", Period: ANNUAL, Basis: BASE}},
+
+		// digits grouped in thousands
+		{"€45.000 - €55.000", &Salary{Min: 45000, Max: 55000, Currency: "EUR", Period: ANNUAL, Basis: BASE}},
+		{"45 000 - 55 000 zł", &Salary{Min: 45000, Max: 55000, Currency: "PLN", Period: ANNUAL, Basis: BASE}},
+		{"45 000 €", &Salary{Min: 45000, Max: 45000, Currency: "EUR", Period: ANNUAL, Basis: BASE}},
+		{"CHF 120'000", &Salary{Min: 120000, Max: 120000, Currency: "CHF", Period: ANNUAL, Basis: BASE}},
+		{"CHF 120’000 - 140’000", &Salary{Min: 120000, Max: 140000, Currency: "CHF", Period: ANNUAL, Basis: BASE}},
+		{"₦4,500,000 - ₦6,000,000", &Salary{Min: 4500000, Max: 6000000, Currency: "NGN", Period: ANNUAL, Basis: BASE}},
+		{"1.000.000 ₩", &Salary{Min: 1000000, Max: 1000000, Currency: "KRW", Period: ANNUAL, Basis: BASE}},
+		{"$25.50/hr", &Salary{Min: 25.5, Max: 25.5, Currency: "USD", Period: HOURLY, Basis: BASE}},
+
+		// no amount
+		{"competitive", nil},
+		{"", nil},
+	}
+	for _, test := range tests {
+		got := ParseSalary(test.text)
+		if (got == nil) != (test.want == nil) || (got != nil && *got != *test.want) {
+			t.Errorf("ParseSalary(%q) = %+v, want %+v", test.text, got, test.want)
+		}
+	}
+}
+
+func TestParseSalarySymbols(t *testing.T) {
+	for _, symbol := range currencySymbols {
+		for _, text := range []string{symbol.symbol + "100k", "100k " + symbol.symbol, symbol.symbol + "100 - " + symbol.symbol + "200"} {
+			got := ParseSalary(text)
+			if got == nil || got.Currency != symbol.code {
+				t.Errorf("ParseSalary(%q) = %+v, want a salary in %s", text, got, symbol.code)
+			}
+		}
+	}
+}
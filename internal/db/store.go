@@ -0,0 +1,270 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Store is where jobtrack keeps its data. Commands go through a Store rather than a
+// database connection, so the data can be kept elsewhere than in a SQLite database.
+// Methods behave like the functions of this package of the same name.
+type Store interface {
+	AddJob(job *Job) error
+	GetJobByID(id int) (*Job, error)
+	GetAllJobs(includeTimestamps bool) ([]*Job, error)
+	QueryJobs(q JobQuery) ([]*Job, error)
+	ResolveJobID(ref string) (int, error)
+	UpdateJob(jobID int, updates UpdatedJobParams) (*Job, error)
+	DeleteJobByID(jobID int) (bool, error)
+	ImportJobs(rows []*ImportRow, options ImportOptions) (*ImportResult, error)
+	GetStatusHistory(jobID int) ([]*StatusEvent, error)
+	GetFollowUps(defaults map[JobStatus]int, until time.Time) ([]*FollowUp, error)
+	GetTagCounts() ([]*TagCount, error)
+	GetFieldNames() ([]string, error)
+	GetLocationCounts(by LocationGroup) ([]*LocationCount, error)
+
+	SetExchangeRates(rates []*ExchangeRate) error
+	GetExchangeRates() ([]*ExchangeRate, error)
+	GetConversions(target string, on time.Time) (map[string]*Conversion, error)
+
+	AddNote(jobID int, body string) (*Note, error)
+	GetNotes(jobID int) ([]*Note, error)
+	AttachNotes(jobs []*Job) error
+	UpdateNote(noteID int, body string) (*Note, error)
+	DeleteNote(noteID int) (bool, error)
+
+	AddContact(contact *Contact) error
+	GetContactByID(id int) (*Contact, error)
+	GetContacts(company string) (Contacts, error)
+	UpdateContact(id int, updates UpdatedContactParams) (*Contact, error)
+	DeleteContact(id int) (bool, error)
+	LinkContact(jobID int, contactID int) error
+	UnlinkContact(jobID int, contactID int) (bool, error)
+	GetJobContacts(jobID int) (Contacts, error)
+	GetContactJobs(contactID int) ([]*Job, error)
+
+	AddInterview(interview *Interview) error
+	GetInterviews(jobID int) (Interviews, error)
+	GetUpcomingInterviews(days int) (Interviews, error)
+	UpdateInterview(id int, updates UpdatedInterviewParams) (*Interview, error)
+	DeleteInterview(id int) (bool, error)
+
+	AddOffer(offer *Offer) error
+	GetOfferByID(id int) (*Offer, error)
+	GetOffers(jobID int) ([]*Offer, error)
+
+	GetCompanies() ([]*Company, error)
+	FindCompany(ref string) (*Company, error)
+	UpdateCompany(id int, updates UpdatedCompanyParams) (*Company, error)
+	RenameCompany(id int, name string) (*Company, error)
+	MergeCompanies(fromID int, intoID int) (*Company, error)
+
+	Sync(dir string) (*SyncResult, error)
+	SchemaVersion() (int, error)
+	MigrationStatuses() ([]MigrationStatus, error)
+	Migrate(target int) error
+	Close() error
+}
+
+// SQLStore keeps the data in a SQLite database. It is the default Store.
+type SQLStore struct {
+	db *sql.DB
+}
+
+// NewSQLStore returns a Store using the given database, which must have been
+// initialised with InitDB.
+func NewSQLStore(sqliteDB *sql.DB) *SQLStore {
+	return &SQLStore{db: sqliteDB}
+}
+
+func (s *SQLStore) AddJob(job *Job) error {
+	return AddJob(s.db, job)
+}
+
+func (s *SQLStore) GetJobByID(id int) (*Job, error) {
+	return GetJobByID(s.db, id)
+}
+
+func (s *SQLStore) GetAllJobs(includeTimestamps bool) ([]*Job, error) {
+	return GetAllJobs(s.db, includeTimestamps)
+}
+
+func (s *SQLStore) QueryJobs(q JobQuery) ([]*Job, error) {
+	return QueryJobs(s.db, q)
+}
+
+func (s *SQLStore) ResolveJobID(ref string) (int, error) {
+	return ResolveJobID(s.db, ref)
+}
+
+func (s *SQLStore) UpdateJob(jobID int, updates UpdatedJobParams) (*Job, error) {
+	return UpdateJob(s.db, jobID, updates)
+}
+
+func (s *SQLStore) DeleteJobByID(jobID int) (bool, error) {
+	return DeleteJobByID(s.db, jobID)
+}
+
+func (s *SQLStore) ImportJobs(rows []*ImportRow, options ImportOptions) (*ImportResult, error) {
+	return ImportJobs(s.db, rows, options)
+}
+
+func (s *SQLStore) GetStatusHistory(jobID int) ([]*StatusEvent, error) {
+	return GetStatusHistory(s.db, jobID)
+}
+
+func (s *SQLStore) GetFollowUps(defaults map[JobStatus]int, until time.Time) ([]*FollowUp, error) {
+	return GetFollowUps(s.db, defaults, until)
+}
+
+func (s *SQLStore) GetTagCounts() ([]*TagCount, error) {
+	return GetTagCounts(s.db)
+}
+
+func (s *SQLStore) GetFieldNames() ([]string, error) {
+	return GetFieldNames(s.db)
+}
+
+func (s *SQLStore) GetLocationCounts(by LocationGroup) ([]*LocationCount, error) {
+	return GetLocationCounts(s.db, by)
+}
+
+func (s *SQLStore) SetExchangeRates(rates []*ExchangeRate) error {
+	return SetExchangeRates(s.db, rates)
+}
+
+func (s *SQLStore) GetExchangeRates() ([]*ExchangeRate, error) {
+	return GetExchangeRates(s.db)
+}
+
+func (s *SQLStore) GetConversions(target string, on time.Time) (map[string]*Conversion, error) {
+	return GetConversions(s.db, target, on)
+}
+
+func (s *SQLStore) AddNote(jobID int, body string) (*Note, error) {
+	return AddNote(s.db, jobID, body)
+}
+
+func (s *SQLStore) GetNotes(jobID int) ([]*Note, error) {
+	return GetNotes(s.db, jobID)
+}
+
+func (s *SQLStore) AttachNotes(jobs []*Job) error {
+	return AttachNotes(s.db, jobs)
+}
+
+func (s *SQLStore) UpdateNote(noteID int, body string) (*Note, error) {
+	return UpdateNote(s.db, noteID, body)
+}
+
+func (s *SQLStore) DeleteNote(noteID int) (bool, error) {
+	return DeleteNote(s.db, noteID)
+}
+
+func (s *SQLStore) AddContact(contact *Contact) error {
+	return AddContact(s.db, contact)
+}
+
+func (s *SQLStore) GetContactByID(id int) (*Contact, error) {
+	return GetContactByID(s.db, id)
+}
+
+func (s *SQLStore) GetContacts(company string) (Contacts, error) {
+	return GetContacts(s.db, company)
+}
+
+func (s *SQLStore) UpdateContact(id int, updates UpdatedContactParams) (*Contact, error) {
+	return UpdateContact(s.db, id, updates)
+}
+
+func (s *SQLStore) DeleteContact(id int) (bool, error) {
+	return DeleteContact(s.db, id)
+}
+
+func (s *SQLStore) LinkContact(jobID int, contactID int) error {
+	return LinkContact(s.db, jobID, contactID)
+}
+
+func (s *SQLStore) UnlinkContact(jobID int, contactID int) (bool, error) {
+	return UnlinkContact(s.db, jobID, contactID)
+}
+
+func (s *SQLStore) GetJobContacts(jobID int) (Contacts, error) {
+	return GetJobContacts(s.db, jobID)
+}
+
+func (s *SQLStore) GetContactJobs(contactID int) ([]*Job, error) {
+	return GetContactJobs(s.db, contactID)
+}
+
+func (s *SQLStore) AddInterview(interview *Interview) error {
+	return AddInterview(s.db, interview)
+}
+
+func (s *SQLStore) GetInterviews(jobID int) (Interviews, error) {
+	return GetInterviews(s.db, jobID)
+}
+
+func (s *SQLStore) GetUpcomingInterviews(days int) (Interviews, error) {
+	return GetUpcomingInterviews(s.db, days)
+}
+
+func (s *SQLStore) UpdateInterview(id int, updates UpdatedInterviewParams) (*Interview, error) {
+	return UpdateInterview(s.db, id, updates)
+}
+
+func (s *SQLStore) DeleteInterview(id int) (bool, error) {
+	return DeleteInterview(s.db, id)
+}
+
+func (s *SQLStore) AddOffer(offer *Offer) error {
+	return AddOffer(s.db, offer)
+}
+
+func (s *SQLStore) GetOfferByID(id int) (*Offer, error) {
+	return GetOfferByID(s.db, id)
+}
+
+func (s *SQLStore) GetOffers(jobID int) ([]*Offer, error) {
+	return GetOffers(s.db, jobID)
+}
+
+func (s *SQLStore) GetCompanies() ([]*Company, error) {
+	return GetCompanies(s.db)
+}
+
+func (s *SQLStore) FindCompany(ref string) (*Company, error) {
+	return FindCompany(s.db, ref)
+}
+
+func (s *SQLStore) UpdateCompany(id int, updates UpdatedCompanyParams) (*Company, error) {
+	return UpdateCompany(s.db, id, updates)
+}
+
+func (s *SQLStore) RenameCompany(id int, name string) (*Company, error) {
+	return RenameCompany(s.db, id, name)
+}
+
+func (s *SQLStore) MergeCompanies(fromID int, intoID int) (*Company, error) {
+	return MergeCompanies(s.db, fromID, intoID)
+}
+
+func (s *SQLStore) Sync(dir string) (*SyncResult, error) {
+	return Sync(s.db, dir)
+}
+
+func (s *SQLStore) SchemaVersion() (int, error) {
+	return SchemaVersion(s.db)
+}
+
+func (s *SQLStore) MigrationStatuses() ([]MigrationStatus, error) {
+	return MigrationStatuses(s.db)
+}
+
+func (s *SQLStore) Migrate(target int) error {
+	return Migrate(s.db, target)
+}
+
+func (s *SQLStore) Close() error {
+	return s.db.Close()
+}
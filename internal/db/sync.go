@@ -0,0 +1,802 @@
+package db
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// syncColumns are the columns of jobs exchanged by Sync, each merged on its own.
+var syncColumns = []string{
+	"company",
+	"position",
+	"status",
+	"location",
+	"salary_range",
+	"job_posting_url",
+	"applied_at",
+	"follow_up_on",
+	"tags",
+	"fields",
+}
+
+// syncValue returns the value of a column of a job as written to change logs, "" if it is
+// not set.
+func syncValue(job *Job, column string) string {
+	switch column {
+	case "company":
+		return job.Company
+	case "position":
+		return job.Position
+	case "status":
+		return string(job.Status)
+	case "location":
+		return nullToEmpty(job.Location)
+	case "salary_range":
+		return nullToEmpty(job.SalaryRange)
+	case "job_posting_url":
+		return nullToEmpty(job.JobPostingURL)
+	case "applied_at":
+		return formatOptionalDate(job.AppliedAt)
+	case "follow_up_on":
+		return formatOptionalDate(job.FollowUpOn)
+	case "tags":
+		return strings.Join(job.Tags, ",")
+	case "fields":
+		return encodeFields(job.Fields)
+	}
+	return ""
+}
+
+// setSyncValue sets a column of a job to its value from a change log, returning an error
+// if the value cannot be used in this database.
+func setSyncValue(job *Job, column string, value string) error {
+	switch column {
+	case "company", "position":
+		if value == "" {
+			return fmt.Errorf("%s is empty", column)
+		}
+		if column == "company" {
+			job.Company = value
+		} else {
+			job.Position = value
+		}
+	case "status":
+		status, ok := activePipeline.Lookup(value)
+		if !ok {
+			return fmt.Errorf("Status %q is not part of the pipeline", value)
+		}
+		job.Status = status
+	case "location":
+		job.Location = emptyToNull(value)
+	case "salary_range":
+		job.SalaryRange = emptyToNull(value)
+	case "job_posting_url":
+		job.JobPostingURL = emptyToNull(value)
+	case "applied_at", "follow_up_on":
+		var date *time.Time
+		if value != "" {
+			var err error
+			if date, err = ParseDateTime(value, true); err != nil {
+				return fmt.Errorf("%s %q is not formatted YYYY-MM-DD", column, value)
+			}
+		}
+		if column == "follow_up_on" {
+			job.FollowUpOn = date
+		} else if date == nil {
+			return fmt.Errorf("%s is empty", column)
+		} else {
+			job.AppliedAt = date
+		}
+	case "tags":
+		tags, err := ParseTags(value)
+		if err != nil {
+			return err
+		}
+		job.Tags = tags
+	case "fields":
+		fields, err := decodeFields(value)
+		if err != nil {
+			return err
+		}
+		job.Fields = fields
+	}
+	return nil
+}
+
+// syncChange is one line of a change log: either new values for some fields of a job,
+// all changed at UpdatedAt, or the deletion of the job. Log identifies the log the change
+// was written to, so a log that was started over is recognised by its readers.
+type syncChange struct {
+	Log       string            `json:"log"`
+	Seq       int               `json:"seq"`
+	UUID      string            `json:"uuid"`
+	UpdatedAt time.Time         `json:"updated_at"`
+	Fields    map[string]string `json:"fields,omitempty"`
+	Deleted   bool              `json:"deleted,omitempty"`
+	// device is the device whose log the change was read from.
+	device string
+}
+
+// fieldState is the value of a field as of the last sync, and when it was changed.
+type fieldState struct {
+	value     string
+	updatedAt time.Time
+}
+
+// SyncConflict is a difference between devices that Sync could not settle.
+type SyncConflict struct {
+	UUID     string
+	Company  string
+	Position string
+	// Device is the other device involved.
+	Device  string
+	Message string
+}
+
+// SyncResult reports what Sync exchanged.
+type SyncResult struct {
+	// Device is the name of this device's change log.
+	Device string
+	// Devices counts the other devices with a change log in the directory.
+	Devices int
+	// Sent and Received count the changes written to this device's log and read from
+	// the logs of the others.
+	Sent     int
+	Received int
+	// Added, Updated and Deleted count the jobs changed in this database.
+	Added     int
+	Updated   int
+	Deleted   int
+	Conflicts []SyncConflict
+}
+
+// syncer holds the state of a single Sync.
+type syncer struct {
+	tx     *sql.Tx
+	result *SyncResult
+	state  map[string]map[string]fieldState
+	// deleted holds when every job known to be deleted was deleted.
+	deleted map[string]time.Time
+	// pending holds the fields changed by other devices that are still to be written to
+	// jobs, and deletions the device that deleted the job. order lists the jobs in the
+	// order they were first changed.
+	pending   map[string]map[string]bool
+	deletions map[string]string
+	devices   map[string]string
+	order     []string
+}
+
+// Sync exchanges changes with other devices through a shared directory. Every device
+// appends the changes made on it since its last sync to its own change log in dir, named
+// after the device, and merges in the changes in the logs of the others. As each device
+// only ever writes its own file, the directory can be kept in step by any file syncing
+// tool.
+//
+// Changes are merged field by field: the value changed last, going by the job's
+// updated_at, wins. Deleted jobs are logged as tombstones, and a deletion always wins over
+// changes made to the job elsewhere.
+func Sync(sqliteDB *sql.DB, dir string) (*SyncResult, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("Error creating sync directory: %w", err)
+	}
+	tx, err := sqliteDB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	device, logID, lastSeq, err := localSyncDevice(tx)
+	if err != nil {
+		return nil, err
+	}
+	s := &syncer{
+		tx:        tx,
+		result:    &SyncResult{Device: device},
+		pending:   make(map[string]map[string]bool),
+		deletions: make(map[string]string),
+		devices:   make(map[string]string),
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	remote, err := s.readPeerLogs(dir, device)
+	if err != nil {
+		return nil, err
+	}
+
+	logPath := filepath.Join(dir, device+".jsonl")
+	logged, err := readSyncLog(logPath)
+	if err != nil {
+		return nil, err
+	}
+	loggedSeq := 0
+	for _, change := range logged {
+		loggedSeq = max(loggedSeq, change.Seq)
+	}
+	// a log that is missing or was replaced has been lost, for example because the
+	// directory changed, so it is started over with everything synced so far
+	var local []*syncChange
+	if len(logged) == 0 || logged[0].Log != logID {
+		if lastSeq > 0 {
+			local = s.snapshot()
+		}
+		logID, loggedSeq = uuid.NewString(), 0
+		if err := os.Remove(logPath); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("Error starting change log: %w", err)
+		}
+	}
+	changes, err := s.localChanges(len(local) > 0)
+	if err != nil {
+		return nil, err
+	}
+	local = append(local, changes...)
+	for i, change := range local {
+		change.Log, change.Seq = logID, loggedSeq+i+1
+	}
+	// the log is written before committing, at worst changes are logged twice
+	if err := appendSyncLog(logPath, local); err != nil {
+		return nil, err
+	}
+	s.result.Sent = len(local)
+	const deviceQuery = `UPDATE sync_devices SET log_id = ?, last_seq = ? WHERE id = ?;`
+	_, err = tx.Exec(deviceQuery, logID, loggedSeq+len(local), device)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(remote, func(i, j int) bool {
+		return remote[i].UpdatedAt.Before(remote[j].UpdatedAt)
+	})
+	for _, change := range remote {
+		if err := s.apply(change); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.writeJobs(); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return s.result, nil
+}
+
+// syncDeviceName is the name of a new device: its host name followed by random
+// characters, so devices with the same host name still have logs of their own.
+func syncDeviceName() string {
+	host, _ := os.Hostname()
+	host = strings.Trim(regexp.MustCompile(`[^a-z0-9]+`).ReplaceAllString(strings.ToLower(host), "-"), "-")
+	if host == "" {
+		host = "device"
+	}
+	return host + "-" + strings.ReplaceAll(uuid.NewString(), "-", "")[:8]
+}
+
+// localSyncDevice returns the name of this device, its change log and the number of
+// changes it has logged, naming it on its first sync.
+func localSyncDevice(tx *sql.Tx) (string, string, int, error) {
+	var device string
+	var logID sql.NullString
+	var lastSeq int
+	const deviceQuery = `SELECT id, log_id, last_seq FROM sync_devices WHERE local = 1;`
+	err := tx.QueryRow(deviceQuery).Scan(&device, &logID, &lastSeq)
+	if err == sql.ErrNoRows {
+		device = syncDeviceName()
+		_, err = tx.Exec(`INSERT INTO sync_devices (id, local) VALUES (?, 1);`, device)
+	}
+	return device, logID.String, lastSeq, err
+}
+
+// load reads the state of every field as of the last sync, and the tombstones.
+func (s *syncer) load() error {
+	s.state = make(map[string]map[string]fieldState)
+	rows, err := s.tx.Query(`SELECT job_uuid, field, value, updated_at FROM sync_state;`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var jobUUID, field, value, updatedAt string
+		if err := rows.Scan(&jobUUID, &field, &value, &updatedAt); err != nil {
+			return err
+		}
+		t, err := ParseDateTime(updatedAt, false)
+		if err != nil {
+			return err
+		}
+		if s.state[jobUUID] == nil {
+			s.state[jobUUID] = make(map[string]fieldState)
+		}
+		s.state[jobUUID][field] = fieldState{value: value, updatedAt: *t}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	s.deleted = make(map[string]time.Time)
+	tombstones, err := s.tx.Query(`SELECT uuid, deleted_at FROM deleted_jobs;`)
+	if err != nil {
+		return err
+	}
+	defer tombstones.Close()
+	for tombstones.Next() {
+		var jobUUID, deletedAt string
+		if err := tombstones.Scan(&jobUUID, &deletedAt); err != nil {
+			return err
+		}
+		t, err := ParseDateTime(deletedAt, false)
+		if err != nil {
+			return err
+		}
+		s.deleted[jobUUID] = *t
+	}
+	return tombstones.Err()
+}
+
+// setState records the value of a field and when it changed.
+func (s *syncer) setState(jobUUID string, field string, state fieldState) error {
+	const upsertQuery = `INSERT INTO sync_state (job_uuid, field, value, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (job_uuid, field) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at;`
+	if s.state[jobUUID] == nil {
+		s.state[jobUUID] = make(map[string]fieldState)
+	}
+	s.state[jobUUID][field] = state
+	_, err := s.tx.Exec(upsertQuery, jobUUID, field, state.value, FormatDateTime(state.updatedAt, false))
+	return err
+}
+
+// snapshot returns changes recreating every job and tombstone synced so far.
+func (s *syncer) snapshot() []*syncChange {
+	var changes []*syncChange
+	for jobUUID, fields := range s.state {
+		if _, ok := s.deleted[jobUUID]; ok {
+			continue
+		}
+		// fields changed at the same time share a change
+		byTime := make(map[time.Time]*syncChange)
+		for field, state := range fields {
+			change, ok := byTime[state.updatedAt]
+			if !ok {
+				change = &syncChange{UUID: jobUUID, UpdatedAt: state.updatedAt, Fields: make(map[string]string)}
+				byTime[state.updatedAt] = change
+				changes = append(changes, change)
+			}
+			change.Fields[field] = state.value
+		}
+	}
+	for jobUUID, deletedAt := range s.deleted {
+		changes = append(changes, &syncChange{UUID: jobUUID, UpdatedAt: deletedAt, Deleted: true})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if !changes[i].UpdatedAt.Equal(changes[j].UpdatedAt) {
+			return changes[i].UpdatedAt.Before(changes[j].UpdatedAt)
+		}
+		return changes[i].UUID < changes[j].UUID
+	})
+	return changes
+}
+
+// localChanges compares every job with its state as of the last sync, returning the
+// fields changed since and the jobs deleted since. With snapshot the deletions are left
+// out, as the snapshot already has every tombstone.
+func (s *syncer) localChanges(snapshot bool) ([]*syncChange, error) {
+	rows, err := s.tx.Query(`SELECT ` + jobColumns + ` FROM jobs WHERE uuid IS NOT NULL ORDER BY id ASC;`)
+	if err != nil {
+		return nil, err
+	}
+	jobs, err := FetchJobsFromRows(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+	if err := attachDetails(s.tx, jobs); err != nil {
+		return nil, err
+	}
+	var changes []*syncChange
+	for _, job := range jobs {
+		change := &syncChange{UUID: job.UUID, UpdatedAt: job.UpdatedAt.UTC(), Fields: make(map[string]string)}
+		for _, column := range syncColumns {
+			value := syncValue(job, column)
+			state, ok := s.state[job.UUID][column]
+			if ok && state.value == value {
+				continue
+			}
+			change.Fields[column] = value
+			// a change must be newer than the value it replaces, even if clocks disagree
+			if ok && !change.UpdatedAt.After(state.updatedAt) {
+				change.UpdatedAt = state.updatedAt.Add(time.Second)
+			}
+		}
+		if len(change.Fields) == 0 {
+			continue
+		}
+		for column, value := range change.Fields {
+			if err := s.setState(job.UUID, column, fieldState{value: value, updatedAt: change.UpdatedAt}); err != nil {
+				return nil, err
+			}
+		}
+		changes = append(changes, change)
+	}
+
+	if !snapshot {
+		deleted, err := s.unsyncedTombstones()
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, deleted...)
+	}
+	_, err = s.tx.Exec(`UPDATE deleted_jobs SET synced = 1 WHERE synced = 0;`)
+	return changes, err
+}
+
+// unsyncedTombstones returns the deletions of jobs deleted since the last sync.
+func (s *syncer) unsyncedTombstones() ([]*syncChange, error) {
+	rows, err := s.tx.Query(`SELECT uuid, deleted_at FROM deleted_jobs WHERE synced = 0 ORDER BY deleted_at ASC;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var changes []*syncChange
+	for rows.Next() {
+		var jobUUID, deletedAt string
+		if err := rows.Scan(&jobUUID, &deletedAt); err != nil {
+			return nil, err
+		}
+		t, err := ParseDateTime(deletedAt, false)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, &syncChange{UUID: jobUUID, UpdatedAt: *t, Deleted: true})
+	}
+	return changes, rows.Err()
+}
+
+// readSyncLog reads the changes in a change log. A missing log has no changes, and a last
+// line without a newline is ignored, as the log may still be being copied.
+func readSyncLog(path string) ([]*syncChange, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error reading change log: %w", err)
+	}
+	if i := bytes.LastIndexByte(data, '\n'); i >= 0 {
+		data = data[:i+1]
+	} else {
+		data = nil
+	}
+	var changes []*syncChange
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var change syncChange
+		if err := json.Unmarshal(scanner.Bytes(), &change); err != nil {
+			return nil, fmt.Errorf("Error reading change log %s, line %d: %w", path, line, err)
+		}
+		changes = append(changes, &change)
+	}
+	return changes, scanner.Err()
+}
+
+// appendSyncLog adds changes to the end of a change log.
+func appendSyncLog(path string, changes []*syncChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, change := range changes {
+		if err := encoder.Encode(change); err != nil {
+			return err
+		}
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("Error writing change log: %w", err)
+	}
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		file.Close()
+		return fmt.Errorf("Error writing change log: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("Error writing change log: %w", err)
+	}
+	return file.Close()
+}
+
+// readPeerLogs returns the changes logged by other devices since they were last read,
+// and records how far each log has been read.
+func (s *syncer) readPeerLogs(dir string, device string) ([]*syncChange, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	var changes []*syncChange
+	for _, path := range paths {
+		peer := strings.TrimSuffix(filepath.Base(path), ".jsonl")
+		if peer == device {
+			continue
+		}
+		s.result.Devices++
+		var logID sql.NullString
+		var lastSeq int
+		const deviceQuery = `SELECT log_id, last_seq FROM sync_devices WHERE id = ?;`
+		err := s.tx.QueryRow(deviceQuery, peer).Scan(&logID, &lastSeq)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+		logged, err := readSyncLog(path)
+		if err != nil {
+			return nil, err
+		}
+		if len(logged) == 0 {
+			continue
+		}
+		// the log was started over, read it all again
+		if logged[0].Log != logID.String {
+			lastSeq = 0
+		}
+		maxSeq := lastSeq
+		for _, change := range logged {
+			if change.Seq > lastSeq {
+				change.device = peer
+				changes = append(changes, change)
+				maxSeq = max(maxSeq, change.Seq)
+			}
+		}
+		const upsertQuery = `INSERT INTO sync_devices (id, log_id, last_seq) VALUES (?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET log_id = excluded.log_id, last_seq = excluded.last_seq;`
+		if _, err := s.tx.Exec(upsertQuery, peer, logged[0].Log, maxSeq); err != nil {
+			return nil, err
+		}
+	}
+	s.result.Received = len(changes)
+	return changes, nil
+}
+
+// conflict reports a change that could not be merged.
+func (s *syncer) conflict(jobUUID string, job *Job, device string, format string, args ...any) {
+	conflict := SyncConflict{UUID: jobUUID, Device: device, Message: fmt.Sprintf(format, args...)}
+	if job != nil {
+		conflict.Company, conflict.Position = job.Company, job.Position
+	} else {
+		conflict.Company = s.state[jobUUID]["company"].value
+		conflict.Position = s.state[jobUUID]["position"].value
+	}
+	s.result.Conflicts = append(s.result.Conflicts, conflict)
+}
+
+// apply merges a change from another device into the state, leaving the jobs it changes
+// to be written by writeJobs.
+func (s *syncer) apply(change *syncChange) error {
+	if change.Deleted {
+		if _, ok := s.deleted[change.UUID]; ok {
+			return nil
+		}
+		const insertQuery = `INSERT INTO deleted_jobs (uuid, deleted_at, synced) VALUES (?, ?, 1)
+			ON CONFLICT (uuid) DO NOTHING;`
+		if _, err := s.tx.Exec(insertQuery, change.UUID, FormatDateTime(change.UpdatedAt, false)); err != nil {
+			return err
+		}
+		s.deleted[change.UUID] = change.UpdatedAt
+		s.deletions[change.UUID] = change.device
+		s.order = append(s.order, change.UUID)
+		return nil
+	}
+	if deletedAt, ok := s.deleted[change.UUID]; ok {
+		if change.UpdatedAt.After(deletedAt) {
+			s.conflict(change.UUID, nil, change.device, "Changed on %s after it was deleted, it stays deleted", change.device)
+		}
+		return nil
+	}
+	for _, column := range syncColumns {
+		value, ok := change.Fields[column]
+		if !ok {
+			continue
+		}
+		state, known := s.state[change.UUID][column]
+		switch {
+		case !known || change.UpdatedAt.After(state.updatedAt):
+			if err := s.setState(change.UUID, column, fieldState{value: value, updatedAt: change.UpdatedAt}); err != nil {
+				return err
+			}
+			if s.pending[change.UUID] == nil {
+				s.pending[change.UUID] = make(map[string]bool)
+				s.order = append(s.order, change.UUID)
+			}
+			s.pending[change.UUID][column] = true
+			s.devices[change.UUID] = change.device
+		case change.UpdatedAt.Equal(state.updatedAt) && value != state.value:
+			s.conflict(
+				change.UUID,
+				nil,
+				change.device,
+				"%s is %q here but %q on %s, both changed at %s, change it on either device to settle it",
+				column,
+				state.value,
+				value,
+				change.device,
+				FormatDateTime(state.updatedAt, false),
+			)
+		}
+	}
+	return nil
+}
+
+// writeJobs writes the changes merged by apply to the jobs.
+func (s *syncer) writeJobs() error {
+	written := make(map[string]bool)
+	for _, jobUUID := range s.order {
+		if written[jobUUID] {
+			continue
+		}
+		written[jobUUID] = true
+		job, err := ParseRow(s.tx.QueryRow(`SELECT `+jobColumns+` FROM jobs WHERE uuid = ?;`, jobUUID))
+		if err == sql.ErrNoRows {
+			job = nil
+		} else if err != nil {
+			return err
+		} else if err := attachDetails(s.tx, []*Job{job}); err != nil {
+			return err
+		}
+		if device, ok := s.deletions[jobUUID]; ok {
+			err = s.deleteJob(jobUUID, job, device)
+		} else {
+			err = s.writeJob(jobUUID, job)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteJob deletes a job deleted on another device. Its state is kept, so later
+// conflicts can still name the job.
+func (s *syncer) deleteJob(jobUUID string, job *Job, device string) error {
+	if job != nil {
+		if job.UpdatedAt.After(s.deleted[jobUUID]) {
+			s.conflict(jobUUID, job, device, "Changed here after it was deleted on %s, it was deleted anyway", device)
+		}
+		if _, err := s.tx.Exec(`DELETE FROM jobs WHERE id = ?;`, job.ID); err != nil {
+			return err
+		}
+		if err := pruneTags(s.tx); err != nil {
+			return err
+		}
+		if err := pruneCompanies(s.tx); err != nil {
+			return err
+		}
+		s.result.Deleted++
+	}
+	return nil
+}
+
+// writeJob adds or updates a job with the fields changed on other devices. Values this
+// database cannot use are reported and left as they are.
+func (s *syncer) writeJob(jobUUID string, job *Job) error {
+	const updateQuery = `UPDATE jobs SET
+		company = ?, position = ?, status = ?, location = ?, salary_range = ?,
+		job_posting_url = ?, applied_at = ?, follow_up_on = ?, updated_at = ?
+		WHERE id = ?;`
+
+	device := s.devices[jobUUID]
+	state := s.state[jobUUID]
+	isNew := job == nil
+	if isNew {
+		// the change adding the job may not have been copied to the directory yet
+		if state["company"].value == "" || state["position"].value == "" {
+			return nil
+		}
+		job = &Job{UUID: jobUUID, Status: activePipeline.Initial()}
+	}
+	updated := *job
+	var updatedAt time.Time
+	if job.UpdatedAt != nil {
+		updatedAt = job.UpdatedAt.UTC()
+	}
+	for _, column := range syncColumns {
+		if !s.pending[jobUUID][column] {
+			continue
+		}
+		if err := setSyncValue(&updated, column, state[column].value); err != nil {
+			s.conflict(jobUUID, job, device, "%s from %s was not applied: %s", column, device, err)
+			continue
+		}
+		if state[column].updatedAt.After(updatedAt) {
+			updatedAt = state[column].updatedAt
+		}
+	}
+
+	changed := isNew
+	for _, column := range syncColumns {
+		changed = changed || syncValue(job, column) != syncValue(&updated, column)
+	}
+	if !changed {
+		return nil
+	}
+	if isNew {
+		if err := insertJob(s.tx, &updated); err != nil {
+			return err
+		}
+		s.result.Added++
+	} else {
+		s.result.Updated++
+	}
+	_, err := s.tx.Exec(
+		updateQuery,
+		updated.Company,
+		updated.Position,
+		updated.Status,
+		toSQLValue(&updated.Location),
+		toSQLValue(&updated.SalaryRange),
+		toSQLValue(&updated.JobPostingURL),
+		toSQLValue(updated.AppliedAt),
+		toSQLValue(updated.FollowUpOn),
+		FormatDateTime(updatedAt, false),
+		updated.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if !isNew && updated.Company != job.Company {
+		if err := linkJobCompany(s.tx, updated.ID, updated.Company); err != nil {
+			return err
+		}
+		if err := pruneCompanies(s.tx); err != nil {
+			return err
+		}
+	}
+	if !isNew && syncValue(&updated, "salary_range") != syncValue(job, "salary_range") {
+		if err := setJobSalary(s.tx, updated.ID, updated.SalaryRange); err != nil {
+			return err
+		}
+	}
+	if !isNew && syncValue(&updated, "location") != syncValue(job, "location") {
+		if err := setJobPlace(s.tx, updated.ID, updated.Location); err != nil {
+			return err
+		}
+	}
+	if !isNew && syncValue(&updated, "tags") != syncValue(job, "tags") {
+		if err := replaceJobTags(s.tx, updated.ID, updated.Tags); err != nil {
+			return err
+		}
+	}
+	if !isNew && syncValue(&updated, "fields") != syncValue(job, "fields") {
+		if err := replaceJobFields(s.tx, updated.ID, updated.Fields); err != nil {
+			return err
+		}
+	}
+	if !isNew && updated.Status != job.Status {
+		note := emptyToNull("Synced from " + device)
+		if err := recordStatusEvent(s.tx, job.ID, emptyToNull(string(job.Status)), updated.Status, note); err != nil {
+			return err
+		}
+	}
+	// values that were not applied keep the value here, so they are not sent back as a
+	// change of this device's
+	for _, column := range syncColumns {
+		if value := syncValue(&updated, column); s.pending[jobUUID][column] && value != state[column].value {
+			if err := s.setState(jobUUID, column, fieldState{value: value, updatedAt: state[column].updatedAt}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
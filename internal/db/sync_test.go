@@ -0,0 +1,240 @@
+package db
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// openTestDB returns a new, fully migrated database in a temporary directory.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	sqliteDB, err := GetConnection(filepath.Join(t.TempDir(), "jobs.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqliteDB.Close() })
+	if err := InitDB(sqliteDB); err != nil {
+		t.Fatal(err)
+	}
+	if err := Migrate(sqliteDB, LatestVersion()); err != nil {
+		t.Fatal(err)
+	}
+	return sqliteDB
+}
+
+func addTestJob(t *testing.T, sqliteDB *sql.DB, company string, position string) *Job {
+	t.Helper()
+	job := &Job{Company: company, Position: position, Status: APPLIED}
+	if err := AddJob(sqliteDB, job); err != nil {
+		t.Fatal(err)
+	}
+	return job
+}
+
+func syncTestDB(t *testing.T, sqliteDB *sql.DB, dir string) *SyncResult {
+	t.Helper()
+	result, err := Sync(sqliteDB, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+// jobByUUID returns the job with the given UUID, or nil if there is none.
+func jobByUUID(t *testing.T, sqliteDB *sql.DB, jobUUID string) *Job {
+	t.Helper()
+	id, err := ResolveJobID(sqliteDB, jobUUID)
+	if err != nil {
+		return nil
+	}
+	job, err := GetJobByID(sqliteDB, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return job
+}
+
+// updateTestJob changes fields of a job as if it was changed at updatedAt, so tests do not
+// depend on the clock.
+func updateTestJob(t *testing.T, sqliteDB *sql.DB, jobUUID string, updates UpdatedJobParams, updatedAt string) {
+	t.Helper()
+	job := jobByUUID(t, sqliteDB, jobUUID)
+	if job == nil {
+		t.Fatalf("no job with UUID %s", jobUUID)
+	}
+	if _, err := UpdateJob(sqliteDB, job.ID, updates); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sqliteDB.Exec(`UPDATE jobs SET updated_at = ? WHERE id = ?;`, updatedAt, job.ID); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestSyncExchangesJobs(t *testing.T) {
+	dir := t.TempDir()
+	a, b := openTestDB(t), openTestDB(t)
+	fromA := addTestJob(t, a, "Acme", "Developer")
+	fromB := addTestJob(t, b, "Globex", "Tester")
+
+	syncTestDB(t, a, dir)
+	result := syncTestDB(t, b, dir)
+	if result.Added != 1 || result.Devices != 1 {
+		t.Fatalf("b added %d jobs from %d devices, want 1 from 1", result.Added, result.Devices)
+	}
+	result = syncTestDB(t, a, dir)
+	if result.Added != 1 {
+		t.Fatalf("a added %d jobs, want 1", result.Added)
+	}
+
+	for name, sqliteDB := range map[string]*sql.DB{"a": a, "b": b} {
+		for _, want := range []*Job{fromA, fromB} {
+			got := jobByUUID(t, sqliteDB, want.UUID)
+			if got == nil {
+				t.Fatalf("%s is missing %s at %s", name, want.Position, want.Company)
+			}
+			if got.Company != want.Company || got.Position != want.Position || got.Status != want.Status {
+				t.Errorf("%s has %s at %s (%s), want %s at %s (%s)",
+					name, got.Position, got.Company, got.Status, want.Position, want.Company, want.Status)
+			}
+		}
+	}
+
+	// nothing changed, so nothing is exchanged
+	result = syncTestDB(t, b, dir)
+	if result.Sent != 0 || result.Received != 0 {
+		t.Errorf("b sent %d and received %d changes, want none", result.Sent, result.Received)
+	}
+}
+
+func TestSyncLastWriterWinsPerField(t *testing.T) {
+	dir := t.TempDir()
+	a, b := openTestDB(t), openTestDB(t)
+	job := addTestJob(t, a, "Acme", "Developer")
+	syncTestDB(t, a, dir)
+	syncTestDB(t, b, dir)
+
+	// a changes the location after b does, b changes the position after a does
+	updateTestJob(t, a, job.UUID, UpdatedJobParams{Location: ptr("Berlin")}, "2100-01-01 10:00:00")
+	updateTestJob(t, b, job.UUID, UpdatedJobParams{Location: ptr("Paris")}, "2100-01-01 09:00:00")
+	syncTestDB(t, b, dir)
+	updateTestJob(t, b, job.UUID, UpdatedJobParams{Position: ptr("Senior Developer")}, "2100-01-01 11:00:00")
+	syncTestDB(t, b, dir)
+	syncTestDB(t, a, dir)
+	syncTestDB(t, b, dir)
+
+	for name, sqliteDB := range map[string]*sql.DB{"a": a, "b": b} {
+		got := jobByUUID(t, sqliteDB, job.UUID)
+		if got.Location.String != "Berlin" {
+			t.Errorf("%s has location %q, want the later Berlin", name, got.Location.String)
+		}
+		if got.Position != "Senior Developer" {
+			t.Errorf("%s has position %q, want the later Senior Developer", name, got.Position)
+		}
+	}
+}
+
+func TestSyncDeleteWins(t *testing.T) {
+	dir := t.TempDir()
+	a, b := openTestDB(t), openTestDB(t)
+	job := addTestJob(t, a, "Acme", "Developer")
+	syncTestDB(t, a, dir)
+	syncTestDB(t, b, dir)
+
+	// b changes the job after a deleted it, the deletion still wins
+	if deleted, err := DeleteJobByID(a, jobByUUID(t, a, job.UUID).ID); err != nil || !deleted {
+		t.Fatalf("deleting the job: %v", err)
+	}
+	updateTestJob(t, b, job.UUID, UpdatedJobParams{Position: ptr("Senior Developer")}, "2100-01-01 10:00:00")
+	syncTestDB(t, a, dir)
+	result := syncTestDB(t, b, dir)
+	if result.Deleted != 1 {
+		t.Errorf("b deleted %d jobs, want 1", result.Deleted)
+	}
+	if len(result.Conflicts) == 0 {
+		t.Error("b did not report that the job it changed was deleted")
+	}
+	result = syncTestDB(t, a, dir)
+	if result.Added != 0 {
+		t.Errorf("a added %d jobs, want the deleted job to stay deleted", result.Added)
+	}
+	for name, sqliteDB := range map[string]*sql.DB{"a": a, "b": b} {
+		if jobByUUID(t, sqliteDB, job.UUID) != nil {
+			t.Errorf("%s still has the deleted job", name)
+		}
+		var tombstones int
+		err := sqliteDB.QueryRow(`SELECT COUNT(*) FROM deleted_jobs WHERE uuid = ?;`, job.UUID).Scan(&tombstones)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tombstones != 1 {
+			t.Errorf("%s has %d tombstones for the job, want 1", name, tombstones)
+		}
+	}
+}
+
+func TestSyncReportsSameSecondConflict(t *testing.T) {
+	dir := t.TempDir()
+	a, b := openTestDB(t), openTestDB(t)
+	job := addTestJob(t, a, "Acme", "Developer")
+	syncTestDB(t, a, dir)
+	syncTestDB(t, b, dir)
+
+	const changedAt = "2100-01-01 10:00:00"
+	updateTestJob(t, a, job.UUID, UpdatedJobParams{Location: ptr("Berlin")}, changedAt)
+	updateTestJob(t, b, job.UUID, UpdatedJobParams{Location: ptr("Paris")}, changedAt)
+	syncTestDB(t, a, dir)
+	result := syncTestDB(t, b, dir)
+	if len(result.Conflicts) != 1 {
+		t.Fatalf("b reported %d conflicts, want 1", len(result.Conflicts))
+	}
+	conflict := result.Conflicts[0]
+	if conflict.UUID != job.UUID || !strings.Contains(conflict.Message, "location") {
+		t.Errorf("conflict %+v does not name the job and its location", conflict)
+	}
+	if got := jobByUUID(t, b, job.UUID); got.Location.String != "Paris" {
+		t.Errorf("b has location %q, want its own Paris kept", got.Location.String)
+	}
+}
+
+func TestSyncRereadsRestartedLog(t *testing.T) {
+	dir := t.TempDir()
+	a, b := openTestDB(t), openTestDB(t)
+	job := addTestJob(t, a, "Acme", "Developer")
+	syncTestDB(t, a, dir)
+	for _, position := range []string{"Developer II", "Senior Developer", "Staff Developer"} {
+		if _, err := UpdateJob(a, jobByUUID(t, a, job.UUID).ID, UpdatedJobParams{Position: ptr(position)}); err != nil {
+			t.Fatal(err)
+		}
+		syncTestDB(t, a, dir)
+	}
+	syncTestDB(t, b, dir)
+
+	// a's log is lost, so a starts it over with fewer changes than b has read of it
+	device := syncTestDB(t, a, dir).Device
+	if err := os.Remove(filepath.Join(dir, device+".jsonl")); err != nil {
+		t.Fatal(err)
+	}
+	added := addTestJob(t, a, "Globex", "Tester")
+	result := syncTestDB(t, a, dir)
+	if result.Sent < 2 {
+		t.Errorf("a sent %d changes starting its log over, want everything synced so far", result.Sent)
+	}
+
+	result = syncTestDB(t, b, dir)
+	if result.Added != 1 {
+		t.Errorf("b added %d jobs from the restarted log, want 1", result.Added)
+	}
+	if jobByUUID(t, b, added.UUID) == nil {
+		t.Error("b is missing the job logged after a's log was started over")
+	}
+	if got := jobByUUID(t, b, job.UUID); got == nil || got.Position != "Staff Developer" {
+		t.Errorf("b has %+v, want the job synced before the log was started over", got)
+	}
+}
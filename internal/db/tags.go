@@ -0,0 +1,168 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// TagCount is a tag along with how many jobs have it.
+type TagCount struct {
+	Name string `json:"name"`
+	Jobs int    `json:"jobs"`
+}
+
+// querier is implemented by both *sql.DB and *sql.Tx, so tags can be read and written
+// inside or outside a transaction.
+type querier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// NormalizeTag lowercases a tag, returning an error if it has characters other than
+// letters, digits, -, _ and . in it.
+func NormalizeTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" {
+		return "", errors.New("Tags cannot be empty")
+	}
+	for _, r := range tag {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("-_.", r) {
+			return "", fmt.Errorf("Invalid tag %q, use letters, digits, -, _ and .", tag)
+		}
+	}
+	return tag, nil
+}
+
+// NormalizeTags normalises every tag with NormalizeTag, leaving out empty and repeated
+// tags, and sorts them.
+func NormalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if strings.TrimSpace(tag) == "" {
+			continue
+		}
+		tag, err := NormalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+// ParseTags parses a comma separated list of tags, such as "referral,backend".
+func ParseTags(s string) ([]string, error) {
+	return NormalizeTags(strings.Split(s, ","))
+}
+
+// attachTags loads the tags of every given job into its Tags field.
+func attachTags(q querier, jobs []*Job) error {
+	if len(jobs) == 0 {
+		return nil
+	}
+	var b queryBuilder
+	if len(jobs) == 1 {
+		b.where("job_tags.job_id = ?", jobs[0].ID)
+	}
+	query := `SELECT job_tags.job_id, tags.name FROM job_tags
+		JOIN tags ON tags.id = job_tags.tag_id` + b.clause() + ` ORDER BY tags.name ASC;`
+	rows, err := q.Query(query, b.params...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	byJob := make(map[int][]string)
+	for rows.Next() {
+		var jobID int
+		var name string
+		if err := rows.Scan(&jobID, &name); err != nil {
+			return err
+		}
+		byJob[jobID] = append(byJob[jobID], name)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		job.Tags = byJob[job.ID]
+	}
+	return nil
+}
+
+// setJobTags adds and removes tags of a job. Tags are created when first added, and
+// removed once no job has them.
+func setJobTags(q querier, jobID int, add []string, remove []string) error {
+	const (
+		tagQuery  = `INSERT INTO tags (name) VALUES (?) ON CONFLICT (name) DO NOTHING;`
+		linkQuery = `INSERT INTO job_tags (job_id, tag_id) SELECT ?, id FROM tags WHERE name = ?
+			ON CONFLICT (job_id, tag_id) DO NOTHING;`
+		unlinkQuery = `DELETE FROM job_tags
+			WHERE job_id = ? AND tag_id IN (SELECT id FROM tags WHERE name = ?);`
+	)
+	for _, tag := range add {
+		if _, err := q.Exec(tagQuery, tag); err != nil {
+			return err
+		}
+		if _, err := q.Exec(linkQuery, jobID, tag); err != nil {
+			return err
+		}
+	}
+	if len(remove) == 0 {
+		return nil
+	}
+	for _, tag := range remove {
+		if _, err := q.Exec(unlinkQuery, jobID, tag); err != nil {
+			return err
+		}
+	}
+	return pruneTags(q)
+}
+
+// replaceJobTags makes tags the only tags of a job.
+func replaceJobTags(q querier, jobID int, tags []string) error {
+	if _, err := q.Exec(`DELETE FROM job_tags WHERE job_id = ?;`, jobID); err != nil {
+		return err
+	}
+	if err := setJobTags(q, jobID, tags, nil); err != nil {
+		return err
+	}
+	return pruneTags(q)
+}
+
+// pruneTags removes the tags no job has any more.
+func pruneTags(q querier) error {
+	_, err := q.Exec(`DELETE FROM tags WHERE id NOT IN (SELECT tag_id FROM job_tags);`)
+	return err
+}
+
+// GetTagCounts returns every tag with the number of jobs that have it, the most used
+// tags first.
+func GetTagCounts(sqliteDB *sql.DB) ([]*TagCount, error) {
+	const countQuery = `SELECT tags.name, COUNT(*) FROM tags
+		JOIN job_tags ON job_tags.tag_id = tags.id
+		GROUP BY tags.name
+		ORDER BY COUNT(*) DESC, tags.name ASC;`
+	rows, err := sqliteDB.Query(countQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var counts []*TagCount
+	for rows.Next() {
+		var count TagCount
+		if err := rows.Scan(&count.Name, &count.Jobs); err != nil {
+			return nil, err
+		}
+		counts = append(counts, &count)
+	}
+	return counts, rows.Err()
+}
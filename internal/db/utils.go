@@ -4,10 +4,10 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"strconv"
+	"strings"
 	"time"
 
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
 	_ "modernc.org/sqlite"
 )
 
@@ -19,6 +19,7 @@ type NullString struct {
 // JobStatus type
 type JobStatus string
 
+// The statuses of the default pipeline, used when no pipeline is configured.
 const (
 	APPLIED        JobStatus = "Applied"
 	INTERVIEW      JobStatus = "Interview"
@@ -28,18 +29,9 @@ const (
 	REJECTED       JobStatus = "Rejected"
 )
 
+// IsValidStatus reports whether status is part of the active pipeline, ignoring case.
 func IsValidStatus(status JobStatus) bool {
-	caser := cases.Title(language.English)
-	titleStatus := caser.String(string(status))
-	validStatuses := map[JobStatus]struct{}{
-		APPLIED:        {},
-		INTERVIEW:      {},
-		OFFER:          {},
-		ACCEPTED:       {},
-		REJECTED_OFFER: {},
-		REJECTED:       {},
-	}
-	_, ok := validStatuses[JobStatus(titleStatus)]
+	_, ok := activePipeline.Lookup(string(status))
 	return ok
 }
 
@@ -72,10 +64,27 @@ func FormatDateTime(t time.Time, dateOnly bool) string {
 	return t.Format(format)
 }
 
-// Parses a row and creates a job struct
-func ParseRow(row *sql.Row) (*Job, error) {
+// currentTimestamp returns the current UTC time formatted like the timestamps stored in
+// the database. Timestamps are set from Go rather than SQL so every database stores them
+// the same way.
+func currentTimestamp() string {
+	return FormatDateTime(time.Now().UTC(), false)
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanJob scans the columns listed in jobColumns into a job struct
+func scanJob(row rowScanner) (*Job, error) {
 	var job Job
 	var appliedAt, createdAt, updatedAt string
+	var followUpOn, jobUUID sql.NullString
+	var salaryMin, salaryMax sql.NullFloat64
+	var salaryCurrency, salaryPeriod, salaryBasis sql.NullString
+	var workMode, city, region, country sql.NullString
+	var companyID sql.NullInt64
 	err := row.Scan(
 		&job.ID,
 		&job.Company,
@@ -87,41 +96,101 @@ func ParseRow(row *sql.Row) (*Job, error) {
 		&appliedAt,
 		&createdAt,
 		&updatedAt,
+		&followUpOn,
+		&jobUUID,
+		&salaryMin,
+		&salaryMax,
+		&salaryCurrency,
+		&salaryPeriod,
+		&salaryBasis,
+		&workMode,
+		&city,
+		&region,
+		&country,
+		&companyID,
 	)
 	if err != nil {
 		return nil, err
 	}
+	job.UUID = jobUUID.String
+	job.CompanyID = int(companyID.Int64)
 	job.AppliedAt, _ = ParseDateTime(appliedAt, true)
 	job.CreatedAt, _ = ParseDateTime(createdAt, false)
 	job.UpdatedAt, _ = ParseDateTime(updatedAt, false)
+	if followUpOn.Valid {
+		job.FollowUpOn, _ = ParseDateTime(followUpOn.String, true)
+	}
+	if salaryMin.Valid && salaryMax.Valid {
+		job.Salary = &Salary{
+			Min:      salaryMin.Float64,
+			Max:      salaryMax.Float64,
+			Currency: salaryCurrency.String,
+			Period:   SalaryPeriod(salaryPeriod.String),
+			Basis:    SalaryBasis(salaryBasis.String),
+		}
+	}
+	if workMode.Valid || city.Valid || region.Valid || country.Valid {
+		job.Place = &Place{
+			WorkMode: WorkMode(workMode.String),
+			City:     city.String,
+			Region:   region.String,
+			Country:  country.String,
+		}
+	}
 	return &job, nil
 }
 
+// formatOptionalDate formats a date that may not be set, as an empty string if it is not
+func formatOptionalDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return FormatDateTime(*t, true)
+}
+
+// ParseRelativeDate parses a date formatted YYYY-MM-DD, "today", "tomorrow", or an offset
+// from today such as +3d or +2w.
+func ParseRelativeDate(value string, now time.Time) (*time.Time, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	switch value {
+	case "today":
+		return &today, nil
+	case "tomorrow":
+		t := today.AddDate(0, 0, 1)
+		return &t, nil
+	}
+	if strings.HasPrefix(value, "+") && len(value) > 2 {
+		amount, err := strconv.Atoi(value[1 : len(value)-1])
+		if err == nil && amount >= 0 {
+			switch value[len(value)-1] {
+			case 'd':
+				t := today.AddDate(0, 0, amount)
+				return &t, nil
+			case 'w':
+				t := today.AddDate(0, 0, 7*amount)
+				return &t, nil
+			}
+		}
+		return nil, errors.New("Relative dates must be formatted +Nd or +Nw, e.g. +7d")
+	}
+	return ParseDateTime(value, true)
+}
+
+// Parses a row and creates a job struct
+func ParseRow(row *sql.Row) (*Job, error) {
+	return scanJob(row)
+}
+
 // Extracts job structs from sql Rows
 func FetchJobsFromRows(rows *sql.Rows) ([]*Job, error) {
 	var jobs []*Job
 	for rows.Next() {
-		var job Job
-		var appliedAt, createdAt, updatedAt string
-		err := rows.Scan(
-			&job.ID,
-			&job.Company,
-			&job.Position,
-			&job.Status,
-			&job.Location,
-			&job.SalaryRange,
-			&job.JobPostingURL,
-			&appliedAt,
-			&createdAt,
-			&updatedAt,
-		)
+		job, err := scanJob(rows)
 		if err != nil {
 			return jobs, err
 		}
-		job.AppliedAt, _ = ParseDateTime(appliedAt, true)
-		job.CreatedAt, _ = ParseDateTime(createdAt, false)
-		job.UpdatedAt, _ = ParseDateTime(updatedAt, false)
-		jobs = append(jobs, &job)
+		jobs = append(jobs, job)
 	}
 	if err := rows.Err(); err != nil {
 		return jobs, err
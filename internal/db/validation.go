@@ -0,0 +1,149 @@
+package db
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// FieldError describes why a single field of a job is invalid.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists every invalid field of a job.
+type ValidationError []FieldError
+
+func (e ValidationError) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, fieldErr := range e {
+		messages = append(messages, fieldErr.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// checkPostingURL returns why a job posting URL is invalid, or "" if it is valid. URLs
+// without a scheme, such as example.com/jobs/1, are accepted; other schemes than http
+// and https are not, so a javascript: URL never becomes a link.
+func checkPostingURL(s string) string {
+	parsed, err := url.Parse(strings.TrimSpace(s))
+	if err != nil {
+		return fmt.Sprintf("Job posting URL %q is not a valid URL", s)
+	}
+	switch parsed.Scheme {
+	case "", "http", "https":
+		return ""
+	}
+	return fmt.Sprintf("Job posting URL %q must be an http or https URL", s)
+}
+
+func invalidStatusError() FieldError {
+	return FieldError{
+		Field:   "status",
+		Message: "Specified status is not valid, valid statuses are: " + JoinStatuses(activePipeline.Statuses()),
+	}
+}
+
+// ValidateJob checks that a new job has everything it needs before it is added. A job
+// without a status is given the initial status of the pipeline, and a valid status is
+// respelled the way the pipeline spells it. Tags and custom fields are normalised, leaving
+// out empty fields. Any error returned is a ValidationError.
+func ValidateJob(job *Job) error {
+	var errs ValidationError
+	if strings.TrimSpace(job.Company) == "" {
+		errs = append(errs, FieldError{Field: "company", Message: "Company not specified"})
+	}
+	if strings.TrimSpace(job.Position) == "" {
+		errs = append(errs, FieldError{Field: "position", Message: "Position not specified"})
+	}
+	if job.Status == "" {
+		job.Status = activePipeline.Initial()
+	} else if status, ok := activePipeline.Lookup(string(job.Status)); ok {
+		job.Status = status
+	} else {
+		errs = append(errs, invalidStatusError())
+	}
+	if job.AppliedAt != nil && job.AppliedAt.After(time.Now()) {
+		errs = append(errs, FieldError{Field: "applied_at", Message: "Applied date cannot be in the future"})
+	}
+	if message := checkPostingURL(job.JobPostingURL.String); job.JobPostingURL.Valid && message != "" {
+		errs = append(errs, FieldError{Field: "job_posting_url", Message: message})
+	}
+	if tags, err := NormalizeTags(job.Tags); err != nil {
+		errs = append(errs, FieldError{Field: "tags", Message: err.Error()})
+	} else {
+		job.Tags = tags
+	}
+	if fields, err := NormalizeFields(job.Fields, true); err != nil {
+		errs = append(errs, FieldError{Field: "fields", Message: err.Error()})
+	} else {
+		job.Fields = fields
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// ValidateUpdate checks the fields changed by an update, respelling a new status the way
+// the pipeline spells it and normalising tags and custom fields. Any error returned is a ValidationError.
+func ValidateUpdate(updates *UpdatedJobParams) error {
+	var errs ValidationError
+	if updates.Company != nil && strings.TrimSpace(*updates.Company) == "" {
+		errs = append(errs, FieldError{Field: "company", Message: "Company cannot be empty"})
+	}
+	if updates.Position != nil && strings.TrimSpace(*updates.Position) == "" {
+		errs = append(errs, FieldError{Field: "position", Message: "Position cannot be empty"})
+	}
+	if updates.Status != nil {
+		if status, ok := activePipeline.Lookup(string(*updates.Status)); ok {
+			updates.Status = &status
+		} else {
+			errs = append(errs, invalidStatusError())
+		}
+	}
+	if updates.AppliedAt != nil && updates.AppliedAt.After(time.Now()) {
+		errs = append(errs, FieldError{Field: "applied_at", Message: "Applied date cannot be in the future"})
+	}
+	if updates.JobPostingURL != nil {
+		if message := checkPostingURL(*updates.JobPostingURL); message != "" {
+			errs = append(errs, FieldError{Field: "job_posting_url", Message: message})
+		}
+	}
+	addTags, addErr := NormalizeTags(updates.AddTags)
+	removeTags, removeErr := NormalizeTags(updates.RemoveTags)
+	switch {
+	case addErr != nil:
+		errs = append(errs, FieldError{Field: "tags", Message: addErr.Error()})
+	case removeErr != nil:
+		errs = append(errs, FieldError{Field: "tags", Message: removeErr.Error()})
+	default:
+		updates.AddTags, updates.RemoveTags = addTags, removeTags
+		for _, tag := range addTags {
+			if slices.Contains(removeTags, tag) {
+				errs = append(errs, FieldError{
+					Field:   "tags",
+					Message: fmt.Sprintf("Tag %q cannot be both added and removed", tag),
+				})
+			}
+		}
+	}
+	if fields, err := NormalizeFields(updates.SetFields, false); err != nil {
+		errs = append(errs, FieldError{Field: "fields", Message: err.Error()})
+	} else {
+		updates.SetFields = fields
+	}
+	if updates.Note != nil && updates.Status == nil {
+		errs = append(errs, FieldError{
+			Field:   "note",
+			Message: "A note can only be recorded together with a status change",
+		})
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
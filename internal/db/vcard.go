@@ -0,0 +1,67 @@
+package db
+
+import (
+	"strings"
+)
+
+// textEscaper escapes text values as required by both vCard (RFC 6350) and
+// iCalendar (RFC 5545).
+var textEscaper = strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\r\n", `\n`, "\n", `\n`)
+
+// foldContentLine splits a vCard or iCalendar content line into lines of at most 75 octets,
+// continuing each with a single space, without breaking up multi-byte characters.
+func foldContentLine(line string) string {
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > 75 {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	b.WriteString("\r\n")
+	return b.String()
+}
+
+// ToVCard marshals a contact into a vCard 3.0 entry.
+func (c *Contact) ToVCard() string {
+	var b strings.Builder
+	write := func(line string) {
+		b.WriteString(foldContentLine(line))
+	}
+	// vCard 3.0 requires a structured N property, the full name is used as the family name
+	// because names cannot be reliably split into their parts.
+	write("BEGIN:VCARD")
+	write("VERSION:3.0")
+	write("FN:" + textEscaper.Replace(c.Name))
+	write("N:" + textEscaper.Replace(c.Name) + ";;;;")
+	if c.Company.Valid {
+		write("ORG:" + textEscaper.Replace(c.Company.String))
+	}
+	if c.Role.Valid {
+		write("TITLE:" + textEscaper.Replace(c.Role.String))
+	}
+	if c.Email.Valid {
+		write("EMAIL;TYPE=INTERNET:" + textEscaper.Replace(c.Email.String))
+	}
+	if c.Phone.Valid {
+		write("TEL;TYPE=VOICE:" + textEscaper.Replace(c.Phone.String))
+	}
+	if c.LinkedInURL.Valid {
+		write("URL:" + c.LinkedInURL.String)
+	}
+	write("END:VCARD")
+	return b.String()
+}
+
+// ToVCard marshals contacts into a single vCard file.
+func (contacts Contacts) ToVCard() string {
+	var b strings.Builder
+	for _, contact := range contacts {
+		b.WriteString(contact.ToVCard())
+	}
+	return b.String()
+}
@@ -0,0 +1,51 @@
+package jobPrinter
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/valentino7504/jobtrack/internal/db"
+)
+
+// PrintCompaniesTable prints companies with how many applications there are at each, and
+// how many of them have each status.
+func PrintCompaniesTable(companies []*db.Company) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.Debug|tabwriter.AlignRight)
+	fmt.Fprintf(w, "ID\tName\tIndustry\tSize\tApplications\tStatuses\n")
+	for _, company := range companies {
+		fmt.Fprintf(
+			w,
+			"%d\t%s\t%s\t%s\t%d\t%s\n",
+			company.ID,
+			company.Name,
+			OptionalParamStr(company.Industry),
+			OptionalParamStr(company.Size),
+			company.Jobs,
+			statusCountsStr(company.Statuses),
+		)
+	}
+	w.Flush()
+}
+
+// PrintCompany prints every detail of a company followed by the applications made there.
+func PrintCompany(company *db.Company, jobs []*db.Job) {
+	var s string
+	s += fmt.Sprintf("Company ID: %d\nName: %s\n", company.ID, company.Name)
+	if len(company.Aliases) > 0 {
+		s += fmt.Sprintf("Also known as: %s\n", strings.Join(company.Aliases, ", "))
+	}
+	s += fmt.Sprintf("Website: %s\n", OptionalParamStr(company.Website))
+	s += fmt.Sprintf("Industry: %s\nSize: %s\n", OptionalParamStr(company.Industry), OptionalParamStr(company.Size))
+	s += fmt.Sprintf("Notes: %s\n", OptionalParamStr(company.Notes))
+	s += fmt.Sprintf("Applications: %d", company.Jobs)
+	if company.Jobs > 0 {
+		s += fmt.Sprintf(" (%s)", statusCountsStr(company.Statuses))
+	}
+	fmt.Println(s)
+	if len(jobs) > 0 {
+		fmt.Println()
+		PrintJobsTable(jobs, "id", "position", "status", "location", "salary", "applied", "updated")
+	}
+}
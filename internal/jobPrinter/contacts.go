@@ -0,0 +1,62 @@
+package jobPrinter
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/valentino7504/jobtrack/internal/db"
+)
+
+// contactsStr formats contacts one per line with their role and whichever details are known.
+func contactsStr(contacts db.Contacts) string {
+	var lines []string
+	for _, contact := range contacts {
+		line := fmt.Sprintf("  [%d] %s", contact.ID, contact.Name)
+		if contact.Role.Valid {
+			line += fmt.Sprintf(" (%s)", contact.Role.String)
+		}
+		for _, detail := range []db.NullString{contact.Email, contact.Phone, contact.LinkedInURL} {
+			if detail.Valid {
+				line += "  " + detail.String
+			}
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func PrintContactsTable(contacts db.Contacts) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.Debug|tabwriter.AlignRight)
+	fmt.Fprintf(w, "ID\tName\tRole\tCompany\tEmail\tPhone\n")
+	for _, contact := range contacts {
+		fmt.Fprintf(
+			w,
+			"%d\t%s\t%s\t%s\t%s\t%s\n",
+			contact.ID,
+			contact.Name,
+			OptionalParamStr(contact.Role),
+			OptionalParamStr(contact.Company),
+			OptionalParamStr(contact.Email),
+			OptionalParamStr(contact.Phone),
+		)
+	}
+	w.Flush()
+}
+
+// PrintContact prints every detail of a contact followed by the jobs it is linked to.
+func PrintContact(contact *db.Contact, jobs []*db.Job) {
+	var s string
+	s += fmt.Sprintf("Contact ID: %d\nName: %s\n", contact.ID, contact.Name)
+	s += fmt.Sprintf("Role: %s\nCompany: %s\n", OptionalParamStr(contact.Role), OptionalParamStr(contact.Company))
+	s += fmt.Sprintf("Email: %s\nPhone: %s\n", OptionalParamStr(contact.Email), OptionalParamStr(contact.Phone))
+	s += fmt.Sprintf("LinkedIn: %s", OptionalParamStr(contact.LinkedInURL))
+	if len(jobs) > 0 {
+		s += "\nJobs:"
+		for _, job := range jobs {
+			s += fmt.Sprintf("\n  [%d] %s at %s (%s)", job.ID, job.Position, job.Company, job.Status)
+		}
+	}
+	fmt.Println(s)
+}
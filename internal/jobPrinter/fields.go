@@ -0,0 +1,21 @@
+package jobPrinter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// fieldsStr formats custom fields one per line, sorted by name.
+func fieldsStr(fields map[string]string) string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	lines := make([]string, 0, len(names))
+	for _, name := range names {
+		lines = append(lines, fmt.Sprintf("  %s: %s", name, fields[name]))
+	}
+	return strings.Join(lines, "\n")
+}
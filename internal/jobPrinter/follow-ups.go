@@ -0,0 +1,50 @@
+package jobPrinter
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/valentino7504/jobtrack/internal/db"
+)
+
+// dueStr describes how far a due date is from today, e.g. "3 days overdue" or "in 2 days".
+func dueStr(dueOn time.Time, today time.Time) string {
+	days := int(dueOn.Sub(today).Hours() / 24)
+	switch {
+	case days < -1:
+		return fmt.Sprintf("%d days overdue", -days)
+	case days == -1:
+		return "1 day overdue"
+	case days == 0:
+		return "today"
+	case days == 1:
+		return "tomorrow"
+	default:
+		return fmt.Sprintf("in %d days", days)
+	}
+}
+
+func PrintFollowUpsTable(followUps []*db.FollowUp, today time.Time) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.Debug|tabwriter.AlignRight)
+	fmt.Fprintf(w, "ID\tCompany\tPosition\tStatus\tDue On\tDue\tSource\n")
+	for _, followUp := range followUps {
+		source := "set"
+		if followUp.Automatic {
+			source = "status age"
+		}
+		fmt.Fprintf(
+			w,
+			"%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			followUp.Job.ID,
+			followUp.Job.Company,
+			followUp.Job.Position,
+			followUp.Job.Status,
+			formatDate(followUp.DueOn),
+			dueStr(followUp.DueOn, today),
+			source,
+		)
+	}
+	w.Flush()
+}
@@ -0,0 +1,22 @@
+package jobPrinter
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/valentino7504/jobtrack/internal/db"
+)
+
+// PrintImportErrors prints why each row of an import failed, one problem per line.
+func PrintImportErrors(rows []*db.ImportRow) {
+	for _, row := range rows {
+		var validationErr db.ValidationError
+		if !errors.As(row.Err, &validationErr) {
+			fmt.Printf("Line %d: %s\n", row.Line, row.Err)
+			continue
+		}
+		for _, fieldErr := range validationErr {
+			fmt.Printf("Line %d: %s\n", row.Line, fieldErr.Message)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package jobPrinter
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/valentino7504/jobtrack/internal/db"
+)
+
+// interviewTime shows an interview time in the zone it was scheduled in.
+func interviewTime(t *time.Time, zone *time.Location) string {
+	return t.In(zone).Format(options.DateLayout + " 15:04 MST")
+}
+
+func PrintInterviewsTable(interviews db.Interviews) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.Debug|tabwriter.AlignRight)
+	fmt.Fprintf(w, "ID\tJob\tRound\tStarts\tEnds\tWhere\tInterviewers\tOutcome\n")
+	for _, interview := range interviews {
+		zone := interview.Zone()
+		ends := "N/A"
+		if interview.EndsAt != nil {
+			ends = interviewTime(interview.EndsAt, zone)
+		}
+		where := interview.Location
+		if !where.Valid {
+			where = interview.VideoLink
+		}
+		fmt.Fprintf(
+			w,
+			"%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			interview.ID,
+			fmt.Sprintf("[%d] %s at %s", interview.JobID, interview.Position, interview.Company),
+			interview.Round,
+			interviewTime(interview.StartsAt, zone),
+			ends,
+			OptionalParamStr(where),
+			OptionalParamStr(interview.Interviewers),
+			OptionalParamStr(interview.Outcome),
+		)
+	}
+	w.Flush()
+}
@@ -1,30 +1,46 @@
 package jobPrinter
 
 import (
-	"fmt"
 	"os"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/valentino7504/jobtrack/internal/db"
 )
 
-func PrintJobsTable(jobs []*db.Job) {
+// PrintJobsTable prints jobs as a table with the given columns, or the configured ones
+// when columns is empty.
+func PrintJobsTable(jobs []*db.Job, columns ...string) {
+	if len(columns) == 0 {
+		columns = options.Columns
+	}
+	var table []column
+	for _, name := range columns {
+		if c, ok := lookupColumn(name); ok {
+			table = append(table, c)
+		} else {
+			table = append(table, fieldColumn(name))
+		}
+	}
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.Debug|tabwriter.AlignRight)
-	fmt.Fprintf(w, "ID\tCompany\tPosition\tStatus\tLocation\tSalary Range\tApplied On\n")
+	headers := make([]string, len(table))
+	for i, c := range table {
+		headers[i] = c.header
+		if c.name == "status" {
+			// every cell of the column is coloured so they all have the same width
+			headers[i] = colorize(colorBold, c.header)
+		}
+	}
+	w.Write([]byte(strings.Join(headers, "\t") + "\n"))
 	for _, job := range jobs {
-		location := db.NullString(job.Location)
-		salaryRange := db.NullString(job.SalaryRange)
-		fmt.Fprintf(
-			w,
-			"%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
-			job.ID,
-			job.Company,
-			job.Position,
-			job.Status,
-			OptionalParamStr(location),
-			OptionalParamStr(salaryRange),
-			db.FormatDateTime(*job.AppliedAt, true),
-		)
+		cells := make([]string, len(table))
+		for i, c := range table {
+			cells[i] = c.value(job)
+			if c.name == "status" {
+				cells[i] = statusStr(job.Status)
+			}
+		}
+		w.Write([]byte(strings.Join(cells, "\t") + "\n"))
 	}
 	w.Flush()
 }
@@ -0,0 +1,26 @@
+package jobPrinter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/valentino7504/jobtrack/internal/db"
+)
+
+// notesStr formats notes one per line, prefixed by their ID and the time they were written.
+func notesStr(notes []*db.Note) string {
+	var lines []string
+	for _, note := range notes {
+		lines = append(lines, fmt.Sprintf(
+			"  [%d] %s  %s",
+			note.ID,
+			formatTimestamp(*note.CreatedAt),
+			note.Body,
+		))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func PrintNotes(notes []*db.Note) {
+	fmt.Println(notesStr(notes))
+}
@@ -0,0 +1,94 @@
+package jobPrinter
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/valentino7504/jobtrack/internal/db"
+)
+
+// offerAmount formats an amount of an offer, or N/A when the offer has none.
+func offerAmount(offer *db.Offer, amount float64) string {
+	if amount == 0 {
+		return "N/A"
+	}
+	return FormatAmount(offer.Currency, amount)
+}
+
+// vestingStr shows the vesting schedule of an offer's equity.
+func vestingStr(offer *db.Offer) string {
+	if offer.Equity == 0 {
+		return "N/A"
+	}
+	if !offer.Vesting.Valid {
+		return "4 years (default)"
+	}
+	return offer.Vesting.String
+}
+
+func offerJobStr(offer *db.Offer) string {
+	return fmt.Sprintf("[%d] %s at %s", offer.JobID, offer.Position, offer.Company)
+}
+
+func PrintOffersTable(offers []*db.Offer) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.Debug|tabwriter.AlignRight)
+	fmt.Fprintf(w, "ID\tJob\tBase\tFirst Year\tFour Years\tDeadline\n")
+	for _, offer := range offers {
+		fmt.Fprintf(
+			w,
+			"%d\t%s\t%s\t%s\t%s\t%s\n",
+			offer.ID,
+			offerJobStr(offer),
+			FormatAmount(offer.Currency, offer.Base),
+			FormatAmount(offer.Currency, offer.FirstYear()),
+			FormatAmount(offer.Currency, offer.FourYear()),
+			optionalDate(offer.Deadline),
+		)
+	}
+	w.Flush()
+}
+
+func PrintOffer(offer *db.Offer) {
+	var s string
+	s += fmt.Sprintf("Offer ID: %d\nJob: %s\n", offer.ID, offerJobStr(offer))
+	s += fmt.Sprintf("Base Salary: %s/yr\n", FormatAmount(offer.Currency, offer.Base))
+	s += fmt.Sprintf("Bonus: %s\n", offerAmount(offer, offer.Bonus))
+	s += fmt.Sprintf("Sign-On Bonus: %s\n", offerAmount(offer, offer.SignOn))
+	s += fmt.Sprintf("Equity: %s\n", offerAmount(offer, offer.Equity))
+	s += fmt.Sprintf("Vesting: %s\n", vestingStr(offer))
+	s += fmt.Sprintf("First Year Total: %s\n", FormatAmount(offer.Currency, offer.FirstYear()))
+	s += fmt.Sprintf("Four Year Total: %s\n", FormatAmount(offer.Currency, offer.FourYear()))
+	s += fmt.Sprintf("Start Date: %s\n", optionalDate(offer.StartDate))
+	s += fmt.Sprintf("Decision Deadline: %s\n", optionalDate(offer.Deadline))
+	s += fmt.Sprintf("Benefits: %s", OptionalParamStr(offer.Benefits))
+	fmt.Println(s)
+}
+
+// PrintOfferComparison prints offers side by side, one column per offer, with the total
+// compensation of the first year and the first four years of each.
+func PrintOfferComparison(offers []*db.Offer) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.Debug|tabwriter.AlignRight)
+	row := func(label string, value func(offer *db.Offer) string) {
+		cells := []string{label}
+		for _, offer := range offers {
+			cells = append(cells, value(offer))
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t")+"\t")
+	}
+	row("", func(offer *db.Offer) string { return fmt.Sprintf("Offer %d", offer.ID) })
+	row("Company", func(offer *db.Offer) string { return offer.Company })
+	row("Position", func(offer *db.Offer) string { return offer.Position })
+	row("Base", func(offer *db.Offer) string { return FormatAmount(offer.Currency, offer.Base) })
+	row("Bonus", func(offer *db.Offer) string { return offerAmount(offer, offer.Bonus) })
+	row("Sign-On", func(offer *db.Offer) string { return offerAmount(offer, offer.SignOn) })
+	row("Equity", func(offer *db.Offer) string { return offerAmount(offer, offer.Equity) })
+	row("Vesting", vestingStr)
+	row("First Year", func(offer *db.Offer) string { return FormatAmount(offer.Currency, offer.FirstYear()) })
+	row("Four Years", func(offer *db.Offer) string { return FormatAmount(offer.Currency, offer.FourYear()) })
+	row("Start Date", func(offer *db.Offer) string { return optionalDate(offer.StartDate) })
+	row("Deadline", func(offer *db.Offer) string { return optionalDate(offer.Deadline) })
+	row("Benefits", func(offer *db.Offer) string { return OptionalParamStr(offer.Benefits) })
+	w.Flush()
+}
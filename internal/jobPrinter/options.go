@@ -0,0 +1,188 @@
+package jobPrinter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/valentino7504/jobtrack/internal/db"
+)
+
+// Options control how jobs are printed. They are set from the configuration on startup.
+type Options struct {
+	// DateLayout is the Go time layout dates are shown in.
+	DateLayout string
+	// Columns are the columns of the jobs table, see Columns for their names. Other names
+	// are custom fields.
+	Columns []string
+	// Color highlights statuses with terminal colours.
+	Color bool
+}
+
+// DefaultColumns are the columns of the jobs table unless configured otherwise.
+var DefaultColumns = []string{"id", "company", "position", "status", "location", "salary", "applied"}
+
+var options = Options{DateLayout: time.DateOnly, Columns: DefaultColumns}
+
+// SetOptions replaces the options used for printing.
+func SetOptions(o Options) {
+	options = o
+}
+
+// column is a column of the jobs table.
+type column struct {
+	name   string
+	header string
+	value  func(job *db.Job) string
+}
+
+var columns = []column{
+	{"id", "ID", func(job *db.Job) string { return fmt.Sprint(job.ID) }},
+	{"uuid", "UUID", func(job *db.Job) string { return job.UUID }},
+	{"company", "Company", func(job *db.Job) string { return job.Company }},
+	{"position", "Position", func(job *db.Job) string { return job.Position }},
+	{"status", "Status", func(job *db.Job) string { return string(job.Status) }},
+	{"location", "Location", func(job *db.Job) string { return OptionalParamStr(job.Location) }},
+	{"work-mode", "Work Mode", placeField(func(p *db.Place) string { return string(p.WorkMode) })},
+	{"city", "City", placeField(func(p *db.Place) string { return p.City })},
+	{"region", "Region", placeField(func(p *db.Place) string { return p.Region })},
+	{"country", "Country", placeField(func(p *db.Place) string { return p.Country })},
+	{"salary", "Salary Range", salaryStr},
+	{"url", "Job Posting", func(job *db.Job) string { return OptionalParamStr(job.JobPostingURL) }},
+	{"tags", "Tags", func(job *db.Job) string { return tagsStr(job.Tags) }},
+	{"applied", "Applied On", func(job *db.Job) string { return optionalDate(job.AppliedAt) }},
+	{"follow-up", "Follow Up On", func(job *db.Job) string { return optionalDate(job.FollowUpOn) }},
+	{"created", "Created", func(job *db.Job) string { return optionalTimestamp(job.CreatedAt) }},
+	{"updated", "Updated", func(job *db.Job) string { return optionalTimestamp(job.UpdatedAt) }},
+}
+
+// Columns returns the names of the columns the jobs table can show.
+func Columns() []string {
+	names := make([]string, 0, len(columns))
+	for _, c := range columns {
+		names = append(names, c.name)
+	}
+	return names
+}
+
+// ParseColumns parses a comma separated list of column names, such as "id,company,status".
+// Names that are not one of Columns show the custom field of that name.
+func ParseColumns(s string) ([]string, error) {
+	var names []string
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if _, ok := lookupColumn(name); !ok {
+			field, err := db.NormalizeFieldName(name)
+			if err != nil {
+				return nil, fmt.Errorf(
+					"Unknown column %q, valid columns are: %s, or the name of a custom field",
+					name,
+					strings.Join(Columns(), ", "),
+				)
+			}
+			name = field
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("No columns given, valid columns are: %s", strings.Join(Columns(), ", "))
+	}
+	return names, nil
+}
+
+func lookupColumn(name string) (column, bool) {
+	for _, c := range columns {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return column{}, false
+}
+
+// fieldColumn is a column showing the custom field of the given name.
+func fieldColumn(name string) column {
+	return column{name, name, func(job *db.Job) string {
+		if value, ok := job.Fields[name]; ok {
+			return value
+		}
+		return "N/A"
+	}}
+}
+
+// DateLayout converts a date format written with YYYY, YY, MMMM, MMM, MM and DD, such as
+// DD/MM/YYYY, to a Go time layout. A format that already is a Go layout is kept as is.
+func DateLayout(format string) (string, error) {
+	layout := format
+	if !strings.Contains(format, "2006") {
+		replacer := strings.NewReplacer("YYYY", "2006", "YY", "06", "MMMM", "January", "MMM", "Jan", "MM", "01", "DD", "02")
+		layout = replacer.Replace(format)
+	}
+	hasYear := strings.Contains(layout, "06")
+	hasMonth := strings.Contains(layout, "01") || strings.Contains(layout, "Jan")
+	hasDay := strings.Contains(layout, "02")
+	if !hasYear || !hasMonth || !hasDay {
+		return "", fmt.Errorf("Date format %q must include the year (YYYY), month (MM) and day (DD)", format)
+	}
+	return layout, nil
+}
+
+// formatDate shows a date in the configured layout.
+func formatDate(t time.Time) string {
+	return t.Format(options.DateLayout)
+}
+
+// formatTimestamp shows a date in the configured layout followed by the time of day.
+func formatTimestamp(t time.Time) string {
+	return t.Format(options.DateLayout + " 15:04:05")
+}
+
+func optionalDate(t *time.Time) string {
+	if t == nil {
+		return "N/A"
+	}
+	return formatDate(*t)
+}
+
+func optionalTimestamp(t *time.Time) string {
+	if t == nil {
+		return "N/A"
+	}
+	return formatTimestamp(*t)
+}
+
+// ANSI colours used for statuses, all two digits long so coloured cells of a table
+// column stay the same width and tabwriter keeps them aligned.
+const (
+	colorBold   = "01"
+	colorRed    = "31"
+	colorGreen  = "32"
+	colorYellow = "33"
+	colorCyan   = "36"
+)
+
+// colorize wraps s in the given colour when colours are enabled.
+func colorize(color string, s string) string {
+	if !options.Color {
+		return s
+	}
+	return "\x1b[" + color + "m" + s + "\x1b[0m"
+}
+
+// statusStr shows a status coloured by where it is in the pipeline: exits in red, other
+// final statuses in green, the initial status in cyan and the rest in yellow.
+func statusStr(status db.JobStatus) string {
+	pipeline := db.ActivePipeline()
+	switch {
+	case pipeline.IsExit(status):
+		return colorize(colorRed, string(status))
+	case pipeline.IsFinal(status):
+		return colorize(colorGreen, string(status))
+	case status == pipeline.Initial():
+		return colorize(colorCyan, string(status))
+	default:
+		return colorize(colorYellow, string(status))
+	}
+}
@@ -0,0 +1,77 @@
+package jobPrinter
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/valentino7504/jobtrack/internal/db"
+)
+
+// placeField returns the value of a column showing a field of the parsed location of a
+// job, N/A when it is not known.
+func placeField(field func(place *db.Place) string) func(job *db.Job) string {
+	return func(job *db.Job) string {
+		if job.Place == nil || field(job.Place) == "" {
+			return "N/A"
+		}
+		return field(job.Place)
+	}
+}
+
+// placeStr describes a parsed location, such as "New York, NY, United States (hybrid)".
+func placeStr(place *db.Place) string {
+	var parts []string
+	for _, part := range []string{place.City, place.Region} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if place.Country != "" {
+		parts = append(parts, db.CountryName(place.Country))
+	}
+	s := strings.Join(parts, ", ")
+	switch {
+	case place.WorkMode != "" && s != "":
+		s += " (" + string(place.WorkMode) + ")"
+	case place.WorkMode != "":
+		s = string(place.WorkMode)
+	}
+	return s
+}
+
+// statusCountsStr lists how many applications have each status, most first.
+func statusCountsStr(statuses map[db.JobStatus]int) string {
+	names := make([]db.JobStatus, 0, len(statuses))
+	for status := range statuses {
+		names = append(names, status)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if statuses[names[i]] != statuses[names[j]] {
+			return statuses[names[i]] > statuses[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	counts := make([]string, 0, len(names))
+	for _, status := range names {
+		counts = append(counts, fmt.Sprintf("%d %s", statuses[status], status))
+	}
+	return strings.Join(counts, ", ")
+}
+
+// PrintLocationsTable prints how many applications there are in each place, and how
+// many of them have each status.
+func PrintLocationsTable(counts []*db.LocationCount) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.Debug|tabwriter.AlignRight)
+	fmt.Fprintf(w, "Location\tApplications\tStatuses\n")
+	for _, count := range counts {
+		location := placeStr(&count.Place)
+		if location == "" {
+			location = "Unknown"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\n", location, count.Jobs, statusCountsStr(count.Statuses))
+	}
+	w.Flush()
+}
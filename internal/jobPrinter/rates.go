@@ -0,0 +1,27 @@
+package jobPrinter
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/valentino7504/jobtrack/internal/db"
+)
+
+// PrintRatesTable prints exchange rates, marking those that took effect before
+// staleBefore with StaleMarker.
+func PrintRatesTable(rates []*db.ExchangeRate, staleBefore time.Time) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.Debug|tabwriter.AlignRight)
+	fmt.Fprintf(w, "Currency\tRate\tEffective On\tStale\n")
+	for _, rate := range rates {
+		stale := ""
+		if rate.EffectiveOn.Before(staleBefore) {
+			stale = StaleMarker
+		}
+		value := strconv.FormatFloat(rate.Rate, 'f', -1, 64) + " " + rate.Base
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", rate.Currency, value, formatDate(*rate.EffectiveOn), stale)
+	}
+	w.Flush()
+}
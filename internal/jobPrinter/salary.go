@@ -0,0 +1,85 @@
+package jobPrinter
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/valentino7504/jobtrack/internal/db"
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+var amountPrinter = message.NewPrinter(language.English)
+
+// periodSuffixes are written after salaries to say how often they are paid.
+var periodSuffixes = map[db.SalaryPeriod]string{
+	db.HOURLY:  "/hr",
+	db.MONTHLY: "/mo",
+	db.ANNUAL:  "/yr",
+}
+
+// FormatAmount formats an amount of money with the symbol of its currency, such as
+// $120,000 or CHF 95.50. Cents are only shown when the amount has them.
+func FormatAmount(code string, amount float64) string {
+	unit, err := currency.ParseISO(code)
+	if err != nil {
+		return amountPrinter.Sprint(number.Decimal(amount, number.MaxFractionDigits(2))) + " " + code
+	}
+	symbol := amountPrinter.Sprint(currency.Symbol(unit))
+	if last := []rune(symbol); unicode.IsLetter(last[len(last)-1]) {
+		symbol += " "
+	}
+	digits := 0
+	if amount != float64(int64(amount)) {
+		digits, _ = currency.Standard.Rounding(unit)
+	}
+	return symbol + amountPrinter.Sprint(number.Decimal(
+		amount,
+		number.MinFractionDigits(digits),
+		number.MaxFractionDigits(digits),
+	))
+}
+
+// StaleMarker follows salaries converted with stale exchange rates.
+const StaleMarker = "*"
+
+// salaryStr shows the parsed salary of a job, such as $120,000 - $150,000/yr, falling
+// back to the salary range as entered when it could not be parsed. A salary converted
+// to another currency is shown converted, followed by StaleMarker if the rates used
+// were stale.
+func salaryStr(job *db.Job) string {
+	if converted := job.ConvertedSalary; converted != nil {
+		s := formatSalary(&converted.Salary)
+		if converted.Stale {
+			s += " " + StaleMarker
+		}
+		return s
+	}
+	if job.Salary == nil {
+		return OptionalParamStr(job.SalaryRange)
+	}
+	return formatSalary(job.Salary)
+}
+
+func formatSalary(salary *db.Salary) string {
+	s := FormatAmount(salary.Currency, salary.Min)
+	if salary.Max != salary.Min {
+		s += " - " + FormatAmount(salary.Currency, salary.Max)
+	}
+	s += periodSuffixes[salary.Period]
+	if salary.Basis == db.TOTAL {
+		s += " TC"
+	}
+	return s
+}
+
+// salaryDetailsStr describes a parsed salary in words, such as "annual base pay in USD".
+func salaryDetailsStr(salary *db.Salary) string {
+	basis := "base pay"
+	if salary.Basis == db.TOTAL {
+		basis = "total compensation"
+	}
+	return strings.Join([]string{string(salary.Period), basis, "in", salary.Currency}, " ")
+}
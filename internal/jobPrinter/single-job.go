@@ -11,9 +11,32 @@ func PrintJob(job *db.Job) {
 	location := OptionalParamStr(job.Location)
 	salaryRange := OptionalParamStr(job.SalaryRange)
 	jobPostingURL := OptionalParamStr(job.JobPostingURL)
-	s += fmt.Sprintf("Job ID: %d\nCompany: %s\nPosition: %s\n", job.ID, job.Company, job.Position)
-	s += fmt.Sprintf("Status: %s\nLocation: %s\n", job.Status, location)
-	s += fmt.Sprintf("Applied On: %s\n", db.FormatDateTime(*job.AppliedAt, true))
-	s += fmt.Sprintf("Salary Range: %s\nJob Posting: %s", salaryRange, jobPostingURL)
+	s += fmt.Sprintf("Job ID: %d\nUUID: %s\n", job.ID, job.UUID)
+	s += fmt.Sprintf("Company: %s\nPosition: %s\n", job.Company, job.Position)
+	s += fmt.Sprintf("Status: %s\nLocation: %s\n", statusStr(job.Status), location)
+	if job.Place != nil {
+		s += fmt.Sprintf("Place: %s\n", placeStr(job.Place))
+	}
+	s += fmt.Sprintf("Applied On: %s\n", formatDate(*job.AppliedAt))
+	s += fmt.Sprintf("Salary Range: %s\n", salaryRange)
+	if job.Salary != nil {
+		s += fmt.Sprintf("Salary: %s (%s)\n", salaryStr(job), salaryDetailsStr(job.Salary))
+	}
+	s += fmt.Sprintf("Job Posting: %s", jobPostingURL)
+	if job.FollowUpOn != nil {
+		s += fmt.Sprintf("\nFollow Up On: %s", formatDate(*job.FollowUpOn))
+	}
+	if len(job.Tags) > 0 {
+		s += fmt.Sprintf("\nTags: %s", tagsStr(job.Tags))
+	}
+	if len(job.Fields) > 0 {
+		s += "\nFields:\n" + fieldsStr(job.Fields)
+	}
+	if len(job.Contacts) > 0 {
+		s += "\nContacts:\n" + contactsStr(job.Contacts)
+	}
+	if len(job.Notes) > 0 {
+		s += "\nNotes:\n" + notesStr(job.Notes)
+	}
 	fmt.Println(s)
 }
@@ -0,0 +1,47 @@
+package jobPrinter
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/valentino7504/jobtrack/internal/db"
+)
+
+// formatDuration renders a duration in whole days, or hours for anything shorter.
+func formatDuration(d time.Duration) string {
+	days := int(d.Hours() / 24)
+	switch {
+	case days == 1:
+		return "1 day"
+	case days > 1:
+		return fmt.Sprintf("%d days", days)
+	default:
+		return fmt.Sprintf("%d hours", int(d.Hours()))
+	}
+}
+
+// PrintStatusHistory prints the status timeline of a job along with the time spent in
+// each stage. The last stage is measured up to now.
+func PrintStatusHistory(job *db.Job, events []*db.StatusEvent) {
+	fmt.Printf("Status history for %s at %s (ID: %d)\n\n", job.Position, job.Company, job.ID)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.Debug|tabwriter.AlignRight)
+	fmt.Fprintf(w, "Changed At\tFrom\tTo\tTime In Stage\tNote\n")
+	for i, event := range events {
+		end := time.Now().UTC()
+		if i+1 < len(events) {
+			end = *events[i+1].ChangedAt
+		}
+		fmt.Fprintf(
+			w,
+			"%s\t%s\t%s\t%s\t%s\n",
+			formatTimestamp(*event.ChangedAt),
+			OptionalParamStr(event.OldStatus),
+			event.NewStatus,
+			formatDuration(end.Sub(*event.ChangedAt)),
+			OptionalParamStr(event.Note),
+		)
+	}
+	w.Flush()
+}
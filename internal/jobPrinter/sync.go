@@ -0,0 +1,28 @@
+package jobPrinter
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/valentino7504/jobtrack/internal/db"
+)
+
+// PrintSyncConflicts prints the conflicts a sync could not settle, with the start of the
+// UUID of each job so it can be passed to --id.
+func PrintSyncConflicts(conflicts []db.SyncConflict) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.Debug|tabwriter.AlignRight)
+	fmt.Fprintf(w, "UUID\tCompany\tPosition\tDevice\tConflict\n")
+	for _, conflict := range conflicts {
+		fmt.Fprintf(
+			w,
+			"%s\t%s\t%s\t%s\t%s\n",
+			conflict.UUID[:min(8, len(conflict.UUID))],
+			conflict.Company,
+			conflict.Position,
+			conflict.Device,
+			conflict.Message,
+		)
+	}
+	w.Flush()
+}
@@ -0,0 +1,27 @@
+package jobPrinter
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/valentino7504/jobtrack/internal/db"
+)
+
+// tagsStr joins tags with commas, or returns N/A for a job without any.
+func tagsStr(tags []string) string {
+	if len(tags) == 0 {
+		return "N/A"
+	}
+	return strings.Join(tags, ", ")
+}
+
+func PrintTagsTable(tags []*db.TagCount) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.Debug|tabwriter.AlignRight)
+	fmt.Fprintf(w, "Tag\tJobs\n")
+	for _, tag := range tags {
+		fmt.Fprintf(w, "%s\t%d\n", tag.Name, tag.Jobs)
+	}
+	w.Flush()
+}
@@ -0,0 +1,495 @@
+// The jobtrack dashboard. Every view is rendered from the REST API served under /api, so
+// the dashboard validates and stores jobs exactly like the command line does.
+"use strict";
+
+const app = document.getElementById("app");
+const message = document.getElementById("message");
+
+// The sortable columns of the table and the sort field of the API for each.
+const columns = [
+  { title: "ID", sort: "id", value: (job) => job.id },
+  { title: "Company", sort: "company", value: (job) => job.company },
+  { title: "Position", sort: "position", value: (job) => job.position },
+  { title: "Status", sort: "status", value: (job) => job.status },
+  { title: "Location", sort: "location", value: (job) => optional(job.location) },
+  { title: "Salary Range", value: (job) => optional(job.salary_range) },
+  { title: "Applied On", sort: "applied", value: (job) => date(job.applied_at) },
+];
+
+// The state of the table view, kept while switching views.
+const tableState = {
+  sort: "applied",
+  descending: false,
+  filters: { status: "", company: "", position: "", location: "", after: "", before: "" },
+};
+
+// el creates an element with the given attributes and children. Strings become text
+// nodes, so values from the API are never parsed as HTML.
+function el(tag, attrs = {}, ...children) {
+  const node = document.createElement(tag);
+  for (const [name, value] of Object.entries(attrs)) {
+    if (name.startsWith("on")) {
+      node.addEventListener(name.slice(2), value);
+    } else if (value === true) {
+      node.setAttribute(name, "");
+    } else if (value !== false && value !== null && value !== undefined) {
+      node.setAttribute(name, value);
+    }
+  }
+  for (const child of children.flat()) {
+    if (child !== null && child !== undefined) {
+      node.append(child instanceof Node ? child : String(child));
+    }
+  }
+  return node;
+}
+
+function optional(value) {
+  return value === null || value === undefined || value === "" ? "N/A" : value;
+}
+
+function date(value) {
+  return value ? value.slice(0, 10) : "N/A";
+}
+
+function showError(text) {
+  message.textContent = text;
+  message.hidden = false;
+}
+
+function clearError() {
+  message.hidden = true;
+}
+
+// ApiError carries the error body returned by the API.
+class ApiError extends Error {
+  constructor(status, body) {
+    super(body.error || "Request failed");
+    this.status = status;
+    this.fields = body.fields || [];
+  }
+}
+
+async function request(method, path, body) {
+  const options = { method, headers: {} };
+  if (body !== undefined) {
+    options.headers["Content-Type"] = "application/json";
+    options.body = JSON.stringify(body);
+  }
+  const response = await fetch("api" + path, options);
+  if (response.status === 204) {
+    return null;
+  }
+  const data = await response.json();
+  if (!response.ok) {
+    throw new ApiError(response.status, data);
+  }
+  return data;
+}
+
+// updateStatus changes the status of a job, offering to force changes the pipeline does
+// not allow.
+async function updateStatus(job, status, note) {
+  const changes = { status };
+  if (note) {
+    changes.note = note;
+  }
+  try {
+    return await request("PATCH", "/jobs/" + job.id, changes);
+  } catch (err) {
+    if (err.status !== 409 || !confirm(err.message + "\n\nMove the job anyway?")) {
+      throw err;
+    }
+    return request("PATCH", "/jobs/" + job.id, { ...changes, force: true });
+  }
+}
+
+async function renderTable() {
+  const params = new URLSearchParams();
+  for (const [name, value] of Object.entries(tableState.filters)) {
+    if (value) {
+      params.set(name, value);
+    }
+  }
+  params.set("sort", tableState.sort + (tableState.descending ? ":desc" : ":asc"));
+  const [jobs, statuses] = await Promise.all([request("GET", "/jobs?" + params), request("GET", "/statuses")]);
+
+  const filterInput = (name, placeholder, type = "search") =>
+    el("input", {
+      type,
+      placeholder,
+      "aria-label": placeholder,
+      value: tableState.filters[name],
+      onchange: (event) => {
+        tableState.filters[name] = event.target.value;
+        route();
+      },
+    });
+  const statusFilter = el(
+    "select",
+    {
+      "aria-label": "Status",
+      onchange: (event) => {
+        tableState.filters.status = event.target.value;
+        route();
+      },
+    },
+    el("option", { value: "" }, "All statuses"),
+    statuses.map((status) =>
+      el("option", { value: status.name, selected: status.name === tableState.filters.status }, status.name),
+    ),
+  );
+  const filters = el(
+    "div",
+    { class: "filters" },
+    statusFilter,
+    filterInput("company", "Company"),
+    filterInput("position", "Position"),
+    filterInput("location", "Location"),
+    el("label", {}, "Applied after ", filterInput("after", "Applied after", "date")),
+    el("label", {}, "before ", filterInput("before", "Applied before", "date")),
+  );
+
+  const header = el(
+    "tr",
+    {},
+    columns.map((column) => {
+      if (!column.sort) {
+        return el("th", {}, column.title);
+      }
+      let className = "sortable";
+      if (column.sort === tableState.sort) {
+        className += tableState.descending ? " sorted-desc" : " sorted-asc";
+      }
+      return el(
+        "th",
+        {
+          class: className,
+          onclick: () => {
+            tableState.descending = column.sort === tableState.sort && !tableState.descending;
+            tableState.sort = column.sort;
+            route();
+          },
+        },
+        column.title,
+      );
+    }),
+  );
+  const rows = jobs.map((job) =>
+    el(
+      "tr",
+      {},
+      columns.map((column, i) =>
+        el("td", {}, i === 0 ? el("a", { href: "#/jobs/" + job.id }, column.value(job)) : column.value(job)),
+      ),
+    ),
+  );
+  const table =
+    jobs.length === 0
+      ? el("p", { class: "empty" }, "No job applications found")
+      : el("table", {}, el("thead", {}, header), el("tbody", {}, rows));
+  app.replaceChildren(filters, table);
+}
+
+async function renderBoard() {
+  const [jobs, statuses] = await Promise.all([request("GET", "/jobs"), request("GET", "/statuses")]);
+  const known = new Set(statuses.map((status) => status.name));
+  const columnsByStatus = new Map();
+  let dragged = null;
+
+  const column = (name) => {
+    const list = el("div", { class: "cards" });
+    const node = el(
+      "div",
+      {
+        class: "column",
+        ondragover: (event) => {
+          if (dragged && dragged.status !== name) {
+            event.preventDefault();
+            node.classList.add("over");
+          }
+        },
+        ondragleave: () => node.classList.remove("over"),
+        ondrop: async (event) => {
+          event.preventDefault();
+          node.classList.remove("over");
+          const job = dragged;
+          if (!job || job.status === name) {
+            return;
+          }
+          try {
+            await updateStatus(job, name);
+            clearError();
+          } catch (err) {
+            showError("Could not move " + job.position + " at " + job.company + ": " + err.message);
+          }
+          route();
+        },
+      },
+      el("h2", {}, name),
+      list,
+    );
+    columnsByStatus.set(name, { node, list });
+    return node;
+  };
+
+  const board = el(
+    "div",
+    { class: "board" },
+    statuses.map((status) => column(status.name)),
+  );
+  // jobs whose status is no longer part of the pipeline get a column of their own
+  for (const job of jobs) {
+    if (!known.has(job.status) && !columnsByStatus.has(job.status)) {
+      board.append(column(job.status));
+    }
+  }
+  for (const job of jobs) {
+    const card = el(
+      "a",
+      {
+        class: "card",
+        href: "#/jobs/" + job.id,
+        draggable: "true",
+        ondragstart: (event) => {
+          dragged = job;
+          event.dataTransfer.setData("text/plain", String(job.id));
+          const next = statuses.find((status) => status.name === job.status);
+          for (const [name, target] of columnsByStatus) {
+            if (!next || next.next.includes(name)) {
+              target.node.classList.add("allowed");
+            }
+          }
+        },
+        ondragend: () => {
+          dragged = null;
+          for (const target of columnsByStatus.values()) {
+            target.node.classList.remove("allowed", "over");
+          }
+        },
+      },
+      el("strong", {}, job.company),
+      el("span", {}, job.position),
+      el("small", {}, "Applied " + date(job.applied_at)),
+    );
+    columnsByStatus.get(job.status).list.append(card);
+  }
+  app.replaceChildren(board);
+}
+
+// postingLink links to a job posting, or shows its URL as text when it is not an http or
+// https URL, so a javascript: URL stored by another client never runs.
+function postingLink(url) {
+  let protocol;
+  try {
+    protocol = new URL(url).protocol;
+  } catch {
+    return url;
+  }
+  if (protocol !== "http:" && protocol !== "https:") {
+    return url;
+  }
+  return el("a", { href: url, rel: "noreferrer" }, url);
+}
+
+async function renderJob(id) {
+  const [job, history, statuses] = await Promise.all([
+    request("GET", "/jobs/" + id),
+    request("GET", "/jobs/" + id + "/history"),
+    request("GET", "/statuses"),
+  ]);
+  const link = job.job_posting_url ? postingLink(job.job_posting_url) : "N/A";
+  const details = el(
+    "dl",
+    {},
+    el("dt", {}, "Company"),
+    el("dd", {}, job.company),
+    el("dt", {}, "Position"),
+    el("dd", {}, job.position),
+    el("dt", {}, "Status"),
+    el("dd", {}, job.status),
+    el("dt", {}, "Location"),
+    el("dd", {}, optional(job.location)),
+    el("dt", {}, "Applied On"),
+    el("dd", {}, date(job.applied_at)),
+    el("dt", {}, "Salary Range"),
+    el("dd", {}, optional(job.salary_range)),
+    el("dt", {}, "Job Posting"),
+    el("dd", {}, link),
+    el("dt", {}, "Follow Up On"),
+    el("dd", {}, date(job.follow_up_on)),
+  );
+
+  const statusSelect = el(
+    "select",
+    { "aria-label": "New status" },
+    statuses.map((status) => el("option", { value: status.name, selected: status.name === job.status }, status.name)),
+  );
+  const noteInput = el("input", { type: "text", placeholder: "Note (optional)", "aria-label": "Note" });
+  const actions = el(
+    "div",
+    { class: "actions" },
+    statusSelect,
+    noteInput,
+    el(
+      "button",
+      {
+        onclick: async () => {
+          try {
+            await updateStatus(job, statusSelect.value, noteInput.value);
+            clearError();
+            route();
+          } catch (err) {
+            showError(err.fields.length > 0 ? err.fields.map((f) => f.message).join(". ") : err.message);
+          }
+        },
+      },
+      "Change status",
+    ),
+    el(
+      "button",
+      {
+        class: "danger",
+        onclick: async () => {
+          if (!confirm("Delete the application for " + job.position + " at " + job.company + "?")) {
+            return;
+          }
+          try {
+            await request("DELETE", "/jobs/" + job.id);
+            location.hash = "#/";
+          } catch (err) {
+            showError(err.message);
+          }
+        },
+      },
+      "Delete",
+    ),
+  );
+
+  const notes = (job.notes || []).map((note) =>
+    el("li", {}, el("small", {}, note.created_at.slice(0, 16).replace("T", " ") + " "), note.body),
+  );
+  const events = history.map((event) =>
+    el(
+      "tr",
+      {},
+      el("td", {}, event.changed_at.slice(0, 16).replace("T", " ")),
+      el("td", {}, event.old_status ? event.old_status + " → " + event.new_status : event.new_status),
+      el("td", {}, optional(event.note)),
+    ),
+  );
+  app.replaceChildren(
+    el("h2", {}, job.position + " at " + job.company + " (ID: " + job.id + ")"),
+    details,
+    actions,
+    el("section", {}, el("h3", {}, "Notes"), notes.length > 0 ? el("ul", {}, notes) : el("p", { class: "empty" }, "No notes")),
+    el(
+      "section",
+      {},
+      el("h3", {}, "Status history"),
+      el(
+        "table",
+        {},
+        el("thead", {}, el("tr", {}, el("th", {}, "Changed"), el("th", {}, "Status"), el("th", {}, "Note"))),
+        el("tbody", {}, events),
+      ),
+    ),
+  );
+}
+
+async function renderNew() {
+  const statuses = await request("GET", "/statuses");
+  const today = new Date();
+  const localToday = new Date(today.getTime() - today.getTimezoneOffset() * 60000).toISOString().slice(0, 10);
+  const fields = [
+    { name: "company", label: "Company", required: true },
+    { name: "position", label: "Position", required: true },
+    { name: "status", label: "Status" },
+    { name: "location", label: "Location" },
+    { name: "salary_range", label: "Salary Range" },
+    { name: "job_posting_url", label: "Job Posting URL", type: "url" },
+    { name: "applied_at", label: "Applied On", type: "date", value: localToday, max: localToday },
+    { name: "follow_up_on", label: "Follow Up On", placeholder: "YYYY-MM-DD, +7d or +2w" },
+  ];
+  const inputs = {};
+  const errors = {};
+  const form = el("form", { class: "job", novalidate: true });
+  for (const field of fields) {
+    const id = "field-" + field.name;
+    if (field.name === "status") {
+      inputs.status = el(
+        "select",
+        { id },
+        statuses.map((status) => el("option", { value: status.name }, status.name)),
+      );
+    } else {
+      inputs[field.name] = el("input", {
+        id,
+        type: field.type || "text",
+        value: field.value,
+        max: field.max,
+        placeholder: field.placeholder,
+        required: field.required,
+      });
+    }
+    errors[field.name] = el("div", { class: "field-error", hidden: true });
+    form.append(el("label", { for: id }, field.label), inputs[field.name], errors[field.name]);
+  }
+  form.append(el("span"), el("button", { type: "submit" }, "Add job"));
+  form.addEventListener("submit", async (event) => {
+    event.preventDefault();
+    const job = {};
+    for (const [name, input] of Object.entries(inputs)) {
+      job[name] = input.value.trim();
+      errors[name].hidden = true;
+    }
+    try {
+      const created = await request("POST", "/jobs", job);
+      clearError();
+      location.hash = "#/jobs/" + created.id;
+    } catch (err) {
+      if (err.fields.length === 0) {
+        showError(err.message);
+        return;
+      }
+      for (const fieldErr of err.fields) {
+        if (errors[fieldErr.field]) {
+          errors[fieldErr.field].textContent = fieldErr.message;
+          errors[fieldErr.field].hidden = false;
+        }
+      }
+    }
+  });
+  app.replaceChildren(el("h2", {}, "New job application"), form);
+}
+
+async function route() {
+  const hash = location.hash.replace(/^#/, "") || "/";
+  const jobMatch = hash.match(/^\/jobs\/(\d+)$/);
+  let view = "table";
+  let render = renderTable;
+  if (hash === "/board") {
+    view = "board";
+    render = renderBoard;
+  } else if (hash === "/new") {
+    view = "new";
+    render = renderNew;
+  } else if (jobMatch) {
+    view = "job";
+    render = () => renderJob(jobMatch[1]);
+  }
+  for (const link of document.querySelectorAll("nav a")) {
+    link.classList.toggle("active", link.dataset.view === view);
+  }
+  try {
+    await render();
+  } catch (err) {
+    showError(err.message);
+  }
+}
+
+window.addEventListener("hashchange", () => {
+  clearError();
+  route();
+});
+route();
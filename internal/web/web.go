@@ -0,0 +1,28 @@
+// Package web serves the browser dashboard of jobtrack. The dashboard is a static page
+// embedded in the binary that works on top of the REST API from package api, so it needs
+// no network access beyond the local server.
+package web
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+
+	"github.com/valentino7504/jobtrack/internal/api"
+	"github.com/valentino7504/jobtrack/internal/db"
+)
+
+//go:embed static
+var static embed.FS
+
+// NewHandler returns the handler serving the dashboard at / and the REST API at /api/.
+func NewHandler(store db.Store) http.Handler {
+	assets, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/api/", http.StripPrefix("/api", api.NewHandler(store, "")))
+	mux.Handle("/", http.FileServerFS(assets))
+	return mux
+}
@@ -2,21 +2,61 @@ package main
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/valentino7504/jobtrack/cmd"
+	"github.com/valentino7504/jobtrack/internal/config"
 	"github.com/valentino7504/jobtrack/internal/db"
+	"github.com/valentino7504/jobtrack/internal/jobPrinter"
 )
 
 func main() {
-	sqliteDB, err := db.GetConnection()
+	if path := cmd.ConfigPath(); path != "" {
+		config.SetPath(path)
+	}
+	cfg, err := config.Load()
+	if !cmd.UsesStore() {
+		// config and profile commands run even when the configuration is invalid or the
+		// file cannot be read, falling back to the default settings in the latter case
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v, using the default settings\n", err)
+		}
+		cmd.SetConfig(cfg)
+		os.Exit(cmd.Execute())
+	}
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	cmd.SetConfig(cfg)
+	pipeline, err := cfg.BuildPipeline()
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
-	if err := db.InitDB(sqliteDB); err != nil {
+	db.SetPipeline(pipeline)
+	if err := cfg.ApplyDefaultCurrency(); err != nil {
+		fmt.Println(err)
+		return
+	}
+	options, err := cfg.PrinterOptions()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	jobPrinter.SetOptions(options)
+	if url := cmd.DatabaseURL(); url != "" {
+		cfg.Storage.Backend, cfg.Storage.URL = "postgres", url
+	}
+	profile := cmd.ProfileName()
+	store, err := cfg.OpenStore(profile, !cmd.ManagesMigrations())
+	if err != nil {
 		fmt.Println(err)
 		return
 	}
-	cmd.SetDB(sqliteDB)
-	cmd.Execute()
+	cmd.SetProfile(profile)
+	cmd.SetStore(store)
+	code := cmd.Execute()
+	store.Close()
+	os.Exit(code)
 }